@@ -4,6 +4,7 @@ import (
 	"errors"
 	"flagon/pkg/migrations"
 	"fmt"
+	"strconv"
 
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/spf13/cobra"
@@ -12,22 +13,167 @@ import (
 var Cmd = &cobra.Command{
 	Use:   "migrate",
 	Short: "migrate the database",
+	Long:  "Migrate the database. Without a subcommand, apply all pending migrations, as up does.",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		migrateCmd, err := New()
+		return withRunner(func(c *CmdRunner) error { return c.Up() })
+	},
+}
+
+var upCmd = &cobra.Command{
+	Use:   "up",
+	Short: "Apply all pending migrations",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withRunner(func(c *CmdRunner) error { return c.Up() })
+	},
+}
+
+var downCmd = &cobra.Command{
+	Use:   "down [n]",
+	Short: "Revert the last n migrations, 1 by default",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		n := 1
+		if len(args) > 0 {
+			var err error
+			if n, err = strconv.Atoi(args[0]); err != nil || n < 1 {
+				return fmt.Errorf("invalid number of migrations %q", args[0])
+			}
+		}
+		return withRunner(func(c *CmdRunner) error { return c.Down(n) })
+	},
+}
+
+var gotoCmd = &cobra.Command{
+	Use:   "goto <version>",
+	Short: "Migrate up or down to a version",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		version, err := strconv.ParseUint(args[0], 10, 0)
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[0])
+		}
+		return withRunner(func(c *CmdRunner) error { return c.Goto(uint(version)) })
+	},
+}
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the version of the database and the pending migrations",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withRunner(func(c *CmdRunner) error { return c.Status(cmd) })
+	},
+}
+
+var forceCmd = &cobra.Command{
+	Use:   "force <version>",
+	Short: "Set the version of the database without migrating",
+	Long: "Set the version of the database and clear its dirty flag without running migrations.\n" +
+		"Once a failed migration was completed or undone by hand, force the version it left the\n" +
+		"database at. Use `flagon migrate force -- -1` for a database without migrations.",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		version, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[0])
+		}
+		return withRunner(func(c *CmdRunner) error { return c.Force(version) })
+	},
+}
+
+var createDir string
+
+var createCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create empty up and down migrations for every database (development)",
+	Long: "Create empty up and down migrations for every supported database, numbered after the last\n" +
+		"migration. Migrations are embedded in the binary, so run this from the repository and rebuild.",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		paths, err := migrations.Create(createDir, args[0])
 		if err != nil {
-			return fmt.Errorf("failed to create migrate command: %w", err)
+			return err
+		}
+		for _, path := range paths {
+			cmd.Println("Created", path)
 		}
-		return migrateCmd.Run()
+		return nil
 	},
 }
 
+func init() {
+	createCmd.Flags().StringVar(&createDir, "dir", "pkg/migrations", "directory of the migrations")
+	Cmd.AddCommand(upCmd, downCmd, gotoCmd, statusCmd, forceCmd, createCmd)
+}
+
+func withRunner(run func(*CmdRunner) error) error {
+	migrateCmd, err := New()
+	if err != nil {
+		return fmt.Errorf("failed to create migrate command: %w", err)
+	}
+	return run(migrateCmd)
+}
+
 type CmdRunner struct {
 	Migrator *migrations.Migrator
 }
 
-func (c *CmdRunner) Run() error {
-	if err := c.Migrator.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
-		return fmt.Errorf("failed to run migrations: %w", err)
+func (c *CmdRunner) Up() error {
+	if err := c.Migrator.Up(); err != nil {
+		return fmt.Errorf("failed to run migrations: %w", dirtyHint(err))
+	}
+	return nil
+}
+
+func (c *CmdRunner) Down(n int) error {
+	if err := c.Migrator.Down(n); err != nil {
+		return fmt.Errorf("failed to revert migrations: %w", dirtyHint(err))
+	}
+	return nil
+}
+
+func (c *CmdRunner) Goto(version uint) error {
+	if err := c.Migrator.Goto(version); err != nil {
+		return fmt.Errorf("failed to migrate: %w", dirtyHint(err))
 	}
 	return nil
 }
+
+func (c *CmdRunner) Force(version int) error {
+	if err := c.Migrator.Force(version); err != nil {
+		return fmt.Errorf("failed to force version: %w", err)
+	}
+	return nil
+}
+
+func (c *CmdRunner) Status(cmd *cobra.Command) error {
+	status, err := c.Migrator.Status()
+	if err != nil {
+		return fmt.Errorf("failed to read migration status: %w", err)
+	}
+	if status.Version == nil {
+		cmd.Println("Version: none")
+	} else {
+		cmd.Printf("Version: %d\n", *status.Version)
+	}
+	cmd.Printf("Dirty: %t\n", status.Dirty)
+	if len(status.Pending) == 0 {
+		cmd.Println("Pending: none")
+		return nil
+	}
+	cmd.Println("Pending:")
+	for _, migration := range status.Pending {
+		cmd.Printf("  %06d %s\n", migration.Version, migration.Name)
+	}
+	return nil
+}
+
+// dirtyHint explains how to recover when a failed migration left the database dirty.
+func dirtyHint(err error) error {
+	var dirty migrate.ErrDirty
+	if errors.As(err, &dirty) {
+		return fmt.Errorf("a migration failed at version %d: fix the database by hand, then run `flagon migrate force <version>`", dirty.Version)
+	}
+	return err
+}
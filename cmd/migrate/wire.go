@@ -6,8 +6,8 @@
 package migrate
 
 import (
-	"flagon/internal/migrations"
 	"flagon/pkg/database"
+	"flagon/pkg/migrations"
 
 	"github.com/google/wire"
 )
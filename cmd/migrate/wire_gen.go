@@ -7,8 +7,8 @@
 package migrate
 
 import (
-	"flagon/internal/migrations"
 	"flagon/pkg/database"
+	"flagon/pkg/migrations"
 )
 
 // Injectors from wire.go:
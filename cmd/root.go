@@ -3,6 +3,7 @@ package cmd
 import (
 	"flagon/cmd/migrate"
 	"flagon/cmd/server"
+	"flagon/cmd/user"
 	"flagon/pkg/config"
 	"flagon/pkg/log"
 
@@ -33,5 +34,6 @@ func init() {
 
 	cmd.AddCommand(server.Cmd)
 	cmd.AddCommand(migrate.Cmd)
+	cmd.AddCommand(user.Cmd)
 
 }
@@ -8,6 +8,8 @@ import (
 	v1 "flagon/pkg/api/v1"
 	"flagon/pkg/cache"
 	"flagon/pkg/database"
+	"flagon/pkg/evaluation"
+	"flagon/pkg/mailer"
 	"flagon/pkg/repository"
 	"flagon/pkg/server"
 	"flagon/pkg/service"
@@ -18,11 +20,14 @@ func New() (*CmdRunner, error) {
 	wire.Build(
 		wire.Struct(new(CmdRunner), "*"),
 		server.NewHttpServer,
+		server.NewRateLimiter,
 		v1.WireSet,
 		repository.WireSet,
 		service.WireSet,
+		evaluation.WireSet,
 		database.Open,
 		cache.New,
+		mailer.New,
 	)
 	return &CmdRunner{}, nil
 }
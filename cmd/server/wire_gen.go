@@ -7,12 +7,14 @@
 package server
 
 import (
-	"flagon/internal/api/v1"
-	"flagon/internal/repository"
-	"flagon/internal/server"
-	"flagon/internal/service"
+	"flagon/pkg/api/v1"
 	"flagon/pkg/cache"
 	"flagon/pkg/database"
+	"flagon/pkg/evaluation"
+	"flagon/pkg/mailer"
+	"flagon/pkg/repository"
+	"flagon/pkg/server"
+	"flagon/pkg/service"
 )
 
 // Injectors from wire.go:
@@ -23,15 +25,71 @@ func New() (*CmdRunner, error) {
 		return nil, err
 	}
 	userRepository := repository.NewUserRepository(db)
-	redisCache, err := cache.New()
+	cacheCache, err := cache.New(db)
 	if err != nil {
 		return nil, err
 	}
-	tokenRepository := repository.NewTokenRepository(redisCache)
-	authService := service.NewAuthService(userRepository, tokenRepository)
-	authAPI := v1.NewAuthAPI(authService, tokenRepository)
-	api := v1.New(authAPI)
-	httpServer, err := server.NewHttpServer(api)
+	tokenRepository := repository.NewTokenRepository(cacheCache)
+	oidcStateRepository := repository.NewOIDCStateRepository(cacheCache)
+	signingKeyRepository := repository.NewSigningKeyRepository(db)
+	signingKeyService, err := service.NewSigningKeyService(signingKeyRepository)
+	if err != nil {
+		return nil, err
+	}
+	userMFARepository := repository.NewUserMFARepository(db)
+	sessionService := service.NewSessionService(tokenRepository)
+	mfaService := service.NewMFAService(userMFARepository, userRepository, sessionService)
+	mfaChallengeRepository := repository.NewMFAChallengeRepository(cacheCache)
+	oneTimeTokenRepository := repository.NewOneTimeTokenRepository(cacheCache)
+	mailerMailer, err := mailer.New()
+	if err != nil {
+		return nil, err
+	}
+	accountService := service.NewAccountService(userRepository, oneTimeTokenRepository, signingKeyService, sessionService, mailerMailer)
+	loginAttemptRepository := repository.NewLoginAttemptRepository(cacheCache)
+	authService := service.NewAuthService(userRepository, tokenRepository, oidcStateRepository, signingKeyService, mfaService, mfaChallengeRepository, accountService, loginAttemptRepository)
+	accessTokenRepository := repository.NewAccessTokenRepository(db)
+	environmentRepository := repository.NewEnvironmentRepository(db)
+	projectRepository := repository.NewProjectRepository(db)
+	projectGroupRepository := repository.NewProjectGroupRepository(db)
+	accessService := service.NewAccessService(projectGroupRepository, projectRepository, environmentRepository, userMFARepository)
+	auditEventRepository := repository.NewAuditEventRepository(db)
+	auditService := service.NewAuditService(auditEventRepository, projectRepository, accessService)
+	projectGroupService := service.NewProjectGroupService(projectGroupRepository, userRepository, accessService, auditService)
+	projectService := service.NewProjectService(projectRepository, userRepository, projectGroupService, accessService, auditService)
+	tokenService := service.NewTokenService(accessTokenRepository, userRepository, environmentRepository, projectRepository, projectService, projectGroupService, accessService, auditService)
+	authAPI := v1.NewAuthAPI(authService, tokenService)
+	authorizer := v1.NewAuthorizer(accessService)
+	projectGroupAPI := v1.NewProjectGroupAPI(projectGroupService, authorizer)
+	projectAPI := v1.NewProjectAPI(projectService, authorizer)
+	environmentService := service.NewEnvironmentService(environmentRepository, projectService, auditService)
+	environmentAPI := v1.NewEnvironmentAPI(environmentService, authorizer)
+	featureRepository := repository.NewFeatureRepository(db)
+	categoryRepository := repository.NewCategoryRepository(db)
+	targetGroupRepository := repository.NewTargetGroupRepository(db)
+	flagEventRepository := repository.NewFlagEventRepository(cacheCache)
+	snapshotRepository := repository.NewSnapshotRepository(db)
+	streamService := service.NewStreamService(flagEventRepository, snapshotRepository, environmentRepository)
+	featureService := service.NewFeatureService(featureRepository, categoryRepository, environmentRepository, targetGroupRepository, projectService, streamService, auditService)
+	featureAPI := v1.NewFeatureAPI(featureService, authorizer)
+	targetGroupService := service.NewTargetGroupService(targetGroupRepository, environmentRepository, projectService, streamService, auditService)
+	targetGroupAPI := v1.NewTargetGroupAPI(targetGroupService, authorizer)
+	evaluator := evaluation.NewEvaluator(snapshotRepository)
+	sdkService := service.NewSdkService(tokenService, evaluator, snapshotRepository)
+	sdkAPI := v1.NewSdkAPI(sdkService, streamService)
+	accessTokenAPI := v1.NewAccessTokenAPI(tokenService)
+	auditAPI := v1.NewAuditAPI(auditService, tokenService)
+	jwksapi := v1.NewJWKSAPI(signingKeyService)
+	sessionAPI := v1.NewSessionAPI(sessionService)
+	mfaapi := v1.NewMFAAPI(mfaService)
+	accountAPI := v1.NewAccountAPI(accountService, authAPI)
+	userService := service.NewUserService(userRepository, accountService, sessionService, auditService)
+	userAPI := v1.NewUserAPI(userService)
+	profileService := service.NewProfileService(userRepository, projectGroupRepository, projectRepository, accountService, sessionService, mfaService)
+	profileAPI := v1.NewProfileAPI(profileService)
+	api := v1.New(authAPI, projectGroupAPI, projectAPI, environmentAPI, featureAPI, targetGroupAPI, sdkAPI, accessTokenAPI, auditAPI, jwksapi, sessionAPI, mfaapi, accountAPI, userAPI, profileAPI)
+	rateLimiter := server.NewRateLimiter(cacheCache)
+	httpServer, err := server.NewHttpServer(api, rateLimiter)
 	if err != nil {
 		return nil, err
 	}
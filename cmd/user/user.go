@@ -0,0 +1,76 @@
+package user
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flagon/pkg/service"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "user",
+	Short: "Manage the users of Flagon",
+}
+
+var createAdminReq service.CreateAdminRequest
+
+var createAdminCmd = &cobra.Command{
+	Use:   "create-admin",
+	Short: "Create an instance admin",
+	Long: "Create an instance admin, who can manage the other users through the API.\n" +
+		"Without --password, the password is read from standard input.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if createAdminReq.Password == "" {
+			password, err := readPassword(cmd)
+			if err != nil {
+				return fmt.Errorf("failed to read password: %w", err)
+			}
+			createAdminReq.Password = password
+		}
+		userCmd, err := New()
+		if err != nil {
+			return fmt.Errorf("failed to create user command: %w", err)
+		}
+		return userCmd.CreateAdmin(cmd, &createAdminReq)
+	},
+}
+
+func init() {
+	createAdminCmd.Flags().StringVar(&createAdminReq.Username, "username", "", "username of the admin")
+	createAdminCmd.Flags().StringVar(&createAdminReq.Email, "email", "", "email address of the admin")
+	createAdminCmd.Flags().StringVar(&createAdminReq.Password, "password", "", "password of the admin")
+	_ = createAdminCmd.MarkFlagRequired("username")
+	_ = createAdminCmd.MarkFlagRequired("email")
+	Cmd.AddCommand(createAdminCmd)
+}
+
+type CmdRunner struct {
+	UserService service.UserService
+}
+
+func (c *CmdRunner) CreateAdmin(cmd *cobra.Command, req *service.CreateAdminRequest) error {
+	user, err := c.UserService.CreateAdmin(context.Background(), req)
+	if err != nil {
+		return fmt.Errorf("failed to create admin: %w", err)
+	}
+	cmd.Printf("Created admin %s (%s)\n", user.Username, user.ID)
+	return nil
+}
+
+// readPassword reads the first line of standard input, prompting for it on terminals.
+func readPassword(cmd *cobra.Command) (string, error) {
+	if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		cmd.PrintErr("Password: ")
+	}
+	password, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	return strings.TrimRight(password, "\r\n"), nil
+}
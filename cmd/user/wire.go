@@ -0,0 +1,26 @@
+//go:build wireinject
+// +build wireinject
+
+//go:generate wire
+package user
+
+import (
+	"flagon/pkg/cache"
+	"flagon/pkg/database"
+	"flagon/pkg/mailer"
+	"flagon/pkg/repository"
+	"flagon/pkg/service"
+	"github.com/google/wire"
+)
+
+func New() (*CmdRunner, error) {
+	wire.Build(
+		wire.Struct(new(CmdRunner), "*"),
+		repository.WireSet,
+		service.WireSet,
+		database.Open,
+		cache.New,
+		mailer.New,
+	)
+	return &CmdRunner{}, nil
+}
@@ -0,0 +1,54 @@
+// Code generated by Wire. DO NOT EDIT.
+
+//go:generate go run -mod=mod github.com/google/wire/cmd/wire
+//go:build !wireinject
+// +build !wireinject
+
+package user
+
+import (
+	"flagon/pkg/cache"
+	"flagon/pkg/database"
+	"flagon/pkg/mailer"
+	"flagon/pkg/repository"
+	"flagon/pkg/service"
+)
+
+// Injectors from wire.go:
+
+func New() (*CmdRunner, error) {
+	db, err := database.Open()
+	if err != nil {
+		return nil, err
+	}
+	userRepository := repository.NewUserRepository(db)
+	cacheCache, err := cache.New(db)
+	if err != nil {
+		return nil, err
+	}
+	oneTimeTokenRepository := repository.NewOneTimeTokenRepository(cacheCache)
+	signingKeyRepository := repository.NewSigningKeyRepository(db)
+	signingKeyService, err := service.NewSigningKeyService(signingKeyRepository)
+	if err != nil {
+		return nil, err
+	}
+	tokenRepository := repository.NewTokenRepository(cacheCache)
+	sessionService := service.NewSessionService(tokenRepository)
+	mailerMailer, err := mailer.New()
+	if err != nil {
+		return nil, err
+	}
+	accountService := service.NewAccountService(userRepository, oneTimeTokenRepository, signingKeyService, sessionService, mailerMailer)
+	auditEventRepository := repository.NewAuditEventRepository(db)
+	projectRepository := repository.NewProjectRepository(db)
+	projectGroupRepository := repository.NewProjectGroupRepository(db)
+	environmentRepository := repository.NewEnvironmentRepository(db)
+	userMFARepository := repository.NewUserMFARepository(db)
+	accessService := service.NewAccessService(projectGroupRepository, projectRepository, environmentRepository, userMFARepository)
+	auditService := service.NewAuditService(auditEventRepository, projectRepository, accessService)
+	userService := service.NewUserService(userRepository, accountService, sessionService, auditService)
+	cmdRunner := &CmdRunner{
+		UserService: userService,
+	}
+	return cmdRunner, nil
+}
@@ -0,0 +1,99 @@
+package v1
+
+import (
+	"flagon/pkg/api/v1/response"
+	"flagon/pkg/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AccessTokenAPI interface {
+	Register(router gin.IRouter)
+}
+
+type accessTokenApi struct {
+	tokenService service.TokenService
+}
+
+func NewAccessTokenAPI(tokenService service.TokenService) AccessTokenAPI {
+	return &accessTokenApi{
+		tokenService: tokenService,
+	}
+}
+
+func (api *accessTokenApi) Register(router gin.IRouter) {
+	tokens := router.Group("/access-tokens")
+	tokens.GET("", api.HandleList)
+	tokens.POST("", api.HandleCreate)
+	tokens.DELETE("/:tokenId", api.HandleRevoke)
+}
+
+// HandleList
+// @Summary List access tokens
+// @Description List the access tokens of the current user; secrets are never returned
+// @Tags access-tokens
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.SuccessResponse[[]model.AccessToken] "Access tokens"
+// @Router /access-tokens [get]
+func (api *accessTokenApi) HandleList(c *gin.Context) {
+	tokens, err := api.tokenService.List(c.Request.Context(), currentUserID(c))
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Access tokens", tokens)
+}
+
+// HandleCreate
+// @Summary Create an access token
+// @Description Create an access token. The secret is only returned in this response.
+// @Tags access-tokens
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param token body service.CreateAccessTokenRequest true "Access token details"
+// @Success 201 {object} response.SuccessResponse[service.CreatedAccessToken] "Access token created"
+// @Failure 400 {object} response.ErrorResponse[string] "Invalid scope"
+// @Failure 404 {object} response.ErrorResponse[string] "Scope not found"
+// @Failure 409 {object} response.ErrorResponse[string] "Access token name already in use"
+// @Router /access-tokens [post]
+func (api *accessTokenApi) HandleCreate(c *gin.Context) {
+	var req service.CreateAccessTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendBadRequest(c, response.ErrInvalidRequest, err.Error())
+		return
+	}
+
+	token, err := api.tokenService.Create(c.Request.Context(), currentUserID(c), &req)
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendCreated(c, "Access token created", token)
+}
+
+// HandleRevoke
+// @Summary Revoke an access token
+// @Tags access-tokens
+// @Produce json
+// @Security BearerAuth
+// @Param tokenId path string true "Access token ID"
+// @Success 200 {object} response.SuccessResponse[string] "Access token revoked"
+// @Failure 404 {object} response.ErrorResponse[string] "Access token not found"
+// @Router /access-tokens/{tokenId} [delete]
+func (api *accessTokenApi) HandleRevoke(c *gin.Context) {
+	id, ok := uuidParam(c, "tokenId")
+	if !ok {
+		return
+	}
+
+	if err := api.tokenService.Revoke(c.Request.Context(), currentUserID(c), id); err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Access token revoked", nil)
+}
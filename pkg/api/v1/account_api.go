@@ -0,0 +1,124 @@
+package v1
+
+import (
+	"flagon/pkg/api/v1/response"
+	"flagon/pkg/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AccountAPI interface {
+	Register(router gin.IRouter)
+}
+
+type accountApi struct {
+	accountService service.AccountService
+	authAPI        AuthAPI
+}
+
+func NewAccountAPI(accountService service.AccountService, authAPI AuthAPI) AccountAPI {
+	return &accountApi{
+		accountService: accountService,
+		authAPI:        authAPI,
+	}
+}
+
+func (api *accountApi) Register(router gin.IRouter) {
+	router.POST("/password/forgot", api.HandleForgotPassword)
+	router.POST("/password/reset", api.HandleResetPassword)
+	router.POST("/email/verify", api.HandleVerifyEmail)
+	router.POST("/email/verification", api.authAPI.AuthRequired(), api.HandleSendEmailVerification)
+}
+
+// HandleForgotPassword
+// @Summary Request a password reset
+// @Description Email a link to reset the password of the account with the address. The response is the same whether or not an account has the address.
+// @Tags account
+// @Accept json
+// @Produce json
+// @Param request body service.PasswordResetRequest true "Email address of the account"
+// @Success 200 {object} response.SuccessResponse[string] "Password reset requested"
+// @Failure 400 {object} response.ErrorResponse[string] "Invalid request"
+// @Router /password/forgot [post]
+func (api *accountApi) HandleForgotPassword(c *gin.Context) {
+	var req service.PasswordResetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendBadRequest(c, response.ErrInvalidRequest, err.Error())
+		return
+	}
+
+	if err := api.accountService.RequestPasswordReset(c.Request.Context(), &req); err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "If an account uses this address, a password reset link was sent to it", nil)
+}
+
+// HandleResetPassword
+// @Summary Reset the password
+// @Description Set a new password with the token of a password reset link. All sessions of the user are revoked.
+// @Tags account
+// @Accept json
+// @Produce json
+// @Param request body service.ResetPasswordRequest true "Token of the link and new password"
+// @Success 200 {object} response.SuccessResponse[string] "Password reset"
+// @Failure 400 {object} response.ErrorResponse[string] "Invalid or expired link"
+// @Router /password/reset [post]
+func (api *accountApi) HandleResetPassword(c *gin.Context) {
+	var req service.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendBadRequest(c, response.ErrInvalidRequest, err.Error())
+		return
+	}
+
+	if err := api.accountService.ResetPassword(c.Request.Context(), &req); err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Password reset", nil)
+}
+
+// HandleVerifyEmail
+// @Summary Verify the email address
+// @Description Verify the email address of a user with the token of a verification link.
+// @Tags account
+// @Accept json
+// @Produce json
+// @Param request body service.VerifyEmailRequest true "Token of the link"
+// @Success 200 {object} response.SuccessResponse[string] "Email address verified"
+// @Failure 400 {object} response.ErrorResponse[string] "Invalid or expired link"
+// @Router /email/verify [post]
+func (api *accountApi) HandleVerifyEmail(c *gin.Context) {
+	var req service.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendBadRequest(c, response.ErrInvalidRequest, err.Error())
+		return
+	}
+
+	if err := api.accountService.VerifyEmail(c.Request.Context(), &req); err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Email address verified", nil)
+}
+
+// HandleSendEmailVerification
+// @Summary Resend the verification email
+// @Description Email a new link verifying the email address of the current user.
+// @Tags account
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.SuccessResponse[string] "Verification email sent"
+// @Failure 400 {object} response.ErrorResponse[string] "Email address verified already"
+// @Router /email/verification [post]
+func (api *accountApi) HandleSendEmailVerification(c *gin.Context) {
+	if err := api.accountService.SendEmailVerification(c.Request.Context(), currentUserID(c)); err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Verification email sent", nil)
+}
@@ -0,0 +1,97 @@
+package v1
+
+import (
+	"flagon/pkg/api/v1/response"
+	"flagon/pkg/model"
+	"flagon/pkg/service"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AuditAPI interface {
+	Register(router gin.IRouter)
+}
+
+type auditApi struct {
+	auditService service.AuditService
+	tokenService service.TokenService
+}
+
+func NewAuditAPI(auditService service.AuditService, tokenService service.TokenService) AuditAPI {
+	return &auditApi{
+		auditService: auditService,
+		tokenService: tokenService,
+	}
+}
+
+func (api *auditApi) Register(router gin.IRouter) {
+	router.GET("/audit", api.HandleList)
+}
+
+// HandleList
+// @Summary List audit events
+// @Description List the changes made to a project or project group, newest first. Filtering by project or group requires the admin role in it; without either filter, only the changes made by the current user are listed.
+// @Tags audit
+// @Produce json
+// @Security BearerAuth
+// @Param project query string false "Project slug"
+// @Param group_id query string false "Project group ID"
+// @Param actor_id query string false "ID of the user who made the changes"
+// @Param resource_type query string false "Resource type, such as flag or target_group"
+// @Param resource_id query string false "Resource ID"
+// @Param action query string false "Action" Enums(create, update, delete)
+// @Param request_id query string false "Request ID"
+// @Param since query string false "Earliest time, in RFC 3339 format"
+// @Param until query string false "Time before which events are listed, in RFC 3339 format"
+// @Param limit query int false "Maximum number of events, 50 by default and at most 500"
+// @Param offset query int false "Number of events to skip"
+// @Success 200 {object} response.SuccessResponse[[]model.AuditEvent] "Audit events"
+// @Failure 400 {object} response.ErrorResponse[string] "Invalid filter"
+// @Failure 403 {object} response.ErrorResponse[string] "Admin role required"
+// @Failure 404 {object} response.ErrorResponse[string] "Project not found"
+// @Router /audit [get]
+func (api *auditApi) HandleList(c *gin.Context) {
+	var req service.ListAuditEventsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.SendBadRequest(c, response.ErrInvalidRequest, err.Error())
+		return
+	}
+	if err := api.authorizeAccessToken(c, &req); err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	events, err := api.auditService.List(c.Request.Context(), currentUserID(c), &req)
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Audit events", events)
+}
+
+// authorizeAccessToken limits tokens scoped to a project or project group to the events of their scope,
+// as the route carries no project or group for AuthRequired to check.
+func (api *auditApi) authorizeAccessToken(c *gin.Context, req *service.ListAuditEventsRequest) error {
+	value, _ := c.Get("accessToken")
+	token, _ := value.(*model.AccessToken)
+	if token == nil || token.GroupID == nil && token.ProjectID == nil {
+		return nil
+	}
+
+	ctx := c.Request.Context()
+	switch {
+	case req.Project != "":
+		return api.tokenService.AuthorizeProject(ctx, token, req.Project)
+	case req.GroupID != "":
+		groupID, err := uuid.Parse(req.GroupID)
+		if err != nil {
+			return fmt.Errorf("%w: invalid group_id", service.ErrInvalidArgument)
+		}
+		return api.tokenService.AuthorizeGroup(ctx, token, groupID)
+	default:
+		return fmt.Errorf("%w: scoped access tokens must filter by project or project group", service.ErrPermissionDenied)
+	}
+}
@@ -1,9 +1,14 @@
 package v1
 
 import (
+	"errors"
 	"flagon/pkg/api/v1/response"
-	"flagon/pkg/repository"
+	"flagon/pkg/model"
 	"flagon/pkg/service"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -16,33 +21,37 @@ type AuthAPI interface {
 }
 
 type authApi struct {
-	authService service.AuthService
-	tokenRepo   repository.TokenRepository
+	authService  service.AuthService
+	tokenService service.TokenService
 }
 
-func NewAuthAPI(authService service.AuthService, tokenRepo repository.TokenRepository) AuthAPI {
+func NewAuthAPI(authService service.AuthService, tokenService service.TokenService) AuthAPI {
 	return &authApi{
-		authService: authService,
-		tokenRepo:   tokenRepo,
+		authService:  authService,
+		tokenService: tokenService,
 	}
 }
 
 func (api *authApi) Register(router gin.IRouter) {
 	router.POST("/register", api.HandleRegister)
 	router.POST("/login", api.HandleLogin)
+	router.POST("/login/mfa", api.HandleLoginMFA)
 	router.POST("/refresh-token", api.HandleRefreshToken)
-	router.POST("/logout", api.HandleLogout, api.AuthRequired())
+	router.POST("/logout", api.AuthRequired(), api.HandleLogout)
+	router.GET("/auth/oidc/login", api.HandleOIDCLogin)
+	router.GET("/auth/oidc/callback", api.HandleOIDCCallback)
 }
 
 // HandleRegister
 // @Summary Register a new user
-// @Description Register a new user with email and password
+// @Description Register a new user with email and password, unless registration is disabled or restricted to other email domains
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param user body service.RegisterRequest true "User registration details"
 // @Success 200 {object} response.SuccessResponse[model.User] "Registration successful"
 // @Failure 400 {object} response.ErrorResponse[string] "Invalid request"
+// @Failure 403 {object} response.ErrorResponse[string] "Registration is disabled"
 // @Router /register [post]
 func (api *authApi) HandleRegister(c *gin.Context) {
 	var req service.RegisterRequest
@@ -52,6 +61,10 @@ func (api *authApi) HandleRegister(c *gin.Context) {
 	}
 
 	user, err := api.authService.Register(c, &req)
+	if errors.Is(err, service.ErrPermissionDenied) {
+		sendServiceError(c, err)
+		return
+	}
 	if err != nil {
 		response.SendBadRequest(c, response.ErrInvalidRequest, err.Error())
 		return
@@ -62,13 +75,14 @@ func (api *authApi) HandleRegister(c *gin.Context) {
 
 // HandleLogin
 // @Summary Login user
-// @Description Authenticate user and return access token
+// @Description Authenticate user and return access token. Users with two-factor authentication get an mfa_token instead, to send with a code to /login/mfa.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param credentials body service.LoginRequest true "User login credentials"
 // @Success 200 {object} response.SuccessResponse[service.LoginResponse] "Login successful"
 // @Failure 401 {object} response.ErrorResponse[string] "Invalid credentials"
+// @Failure 429 {object} response.ErrorResponse[string] "Account locked out after failed logins, or too many requests"
 // @Router /login [post]
 func (api *authApi) HandleLogin(c *gin.Context) {
 	var req service.LoginRequest
@@ -78,6 +92,12 @@ func (api *authApi) HandleLogin(c *gin.Context) {
 	}
 
 	resp, err := api.authService.Login(c.Request.Context(), &req)
+	var locked *service.AccountLockedError
+	if errors.As(err, &locked) {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(locked.RetryAfter.Seconds()))))
+		response.SendTooManyRequests(c, response.ErrTooManyRequests, err.Error())
+		return
+	}
 	if err != nil {
 		response.SendUnauthorized(c, response.ErrInvalidCredentials, err.Error())
 		return
@@ -86,9 +106,39 @@ func (api *authApi) HandleLogin(c *gin.Context) {
 	response.SendOK(c, "Login successful", resp)
 }
 
+// HandleLoginMFA
+// @Summary Complete a login with two-factor authentication
+// @Description Exchange the mfa_token returned by the login and a code of the user's authenticator, or a recovery code, for the tokens. The mfa_token expires after 5 minutes or 5 wrong codes.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param login body service.MFALoginRequest true "Login challenge and code"
+// @Success 200 {object} response.SuccessResponse[service.LoginResponse] "Login successful"
+// @Failure 401 {object} response.ErrorResponse[string] "Invalid code or expired login"
+// @Router /login/mfa [post]
+func (api *authApi) HandleLoginMFA(c *gin.Context) {
+	var req service.MFALoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendBadRequest(c, response.ErrInvalidRequest, err.Error())
+		return
+	}
+
+	resp, err := api.authService.LoginMFA(c.Request.Context(), &req)
+	if errors.Is(err, service.ErrAuthenticationFailed) {
+		response.SendUnauthorized(c, response.ErrInvalidCredentials, err.Error())
+		return
+	}
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Login successful", resp)
+}
+
 // HandleRefreshToken
 // @Summary Refresh access token
-// @Description Get a new access token using refresh token
+// @Description Exchange a refresh token for a new access and refresh token. Each refresh token can be used once; reusing one revokes every token descending from the same login
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -112,39 +162,77 @@ func (api *authApi) HandleRefreshToken(c *gin.Context) {
 	response.SendOK(c, "Token refreshed successfully", resp)
 }
 
+// HandleOIDCLogin
+// @Summary Start single sign-on
+// @Description Redirect to the login page of the configured OpenID Connect provider, which then redirects back to /auth/oidc/callback
+// @Tags auth
+// @Success 302 "Redirect to the identity provider"
+// @Failure 404 {object} response.ErrorResponse[string] "Single sign-on is not configured"
+// @Router /auth/oidc/login [get]
+func (api *authApi) HandleOIDCLogin(c *gin.Context) {
+	authURL, err := api.authService.OIDCAuthorizationURL(c.Request.Context())
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// HandleOIDCCallback
+// @Summary Complete single sign-on
+// @Description Log in with the authorization code the OpenID Connect provider redirected back with. On first login the account is linked to the user with the same verified email address, or a user is created.
+// @Tags auth
+// @Produce json
+// @Param code query string false "Authorization code"
+// @Param state query string true "State sent to the provider"
+// @Param error query string false "Error returned by the provider"
+// @Param error_description query string false "Description of the error returned by the provider"
+// @Success 200 {object} response.SuccessResponse[service.LoginResponse] "Login successful"
+// @Failure 401 {object} response.ErrorResponse[string] "Login failed"
+// @Failure 404 {object} response.ErrorResponse[string] "Single sign-on is not configured"
+// @Router /auth/oidc/callback [get]
+func (api *authApi) HandleOIDCCallback(c *gin.Context) {
+	var req service.OIDCCallbackRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.SendBadRequest(c, response.ErrInvalidRequest, err.Error())
+		return
+	}
+
+	resp, err := api.authService.OIDCLogin(c.Request.Context(), &req)
+	if errors.Is(err, service.ErrAuthenticationFailed) {
+		response.SendUnauthorized(c, response.ErrInvalidCredentials, err.Error())
+		return
+	}
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Login successful", resp)
+}
+
 // HandleLogout
 // @Summary Logout user
-// @Description Invalidate current access token
+// @Description End the current session, revoking its access and refresh tokens
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Success 200 {object} response.SuccessResponse[string]	"Logout successful"
+// @Failure 400 {object} response.ErrorResponse[string] "Not authenticated with a session"
 // @Failure 401 {object} response.ErrorResponse[string] "Unauthorized"
 // @Failure 500 {object} response.ErrorResponse[string] "Internal server error"
 // @Router /logout [post]
 func (api *authApi) HandleLogout(c *gin.Context) {
-	// Get user ID and JTI from context (set by auth middleware)
-	userID, exists := c.Get("userID")
-	if !exists {
-		response.SendUnauthorized(c, response.ErrUnauthorized, "User not authenticated")
-		return
-	}
-
-	userUUID, err := uuid.Parse(userID.(string))
-	if err != nil {
-		response.SendUnauthorized(c, response.ErrUnauthorized, "Invalid user ID")
-		return
-	}
-
-	jti, exists := c.Get("jti")
-	if !exists {
-		response.SendUnauthorized(c, response.ErrUnauthorized, "Invalid token")
+	sessionID, ok := currentSessionID(c)
+	if !ok {
+		response.SendBadRequest(c, response.ErrInvalidRequest, "Access tokens cannot log out; revoke them instead")
 		return
 	}
 
-	err = api.authService.Logout(c.Request.Context(), userUUID, jti.(string))
-	if err != nil {
+	if err := api.authService.Logout(c.Request.Context(), currentUserID(c), sessionID); err != nil {
+		_ = c.Error(err)
 		response.SendInternalServerError(c, response.ErrInternalServer, "Failed to logout")
 		return
 	}
@@ -171,7 +259,12 @@ func (api *authApi) AuthRequired() gin.HandlerFunc {
 		}
 
 		tokenString := parts[1]
-		token, err := api.authService.VerifyJwtToken(c, tokenString)
+		if strings.HasPrefix(tokenString, service.AccessTokenPrefix) {
+			api.accessTokenRequired(c, tokenString)
+			return
+		}
+
+		token, err := api.authService.VerifyJwtToken(c.Request.Context(), tokenString)
 
 		if err != nil {
 			response.SendUnauthorized(c, response.ErrUnauthorized, "Invalid token")
@@ -188,24 +281,64 @@ func (api *authApi) AuthRequired() gin.HandlerFunc {
 			return
 		}
 
-		// Check if token is valid in repository
-		isValid, err := api.tokenRepo.IsJwtValid(c, userUUID, claims.ID)
-		if err != nil {
-			response.SendInternalServerError(c, response.ErrInternalServer, "Failed to validate token")
-			c.Abort()
-			return
-		}
-
-		if !isValid {
-			response.SendUnauthorized(c, response.ErrUnauthorized, "Token has been revoked")
-			c.Abort()
-			return
-		}
-
-		// Set user ID and JTI in context
+		// Set user ID, JTI and session ID in context
 		c.Set("userID", userUUID)
 		c.Set("jti", claims.ID)
+		c.Set("sessionID", claims.Family)
 		c.Set("claims", claims)
+		c.Request = c.Request.WithContext(service.WithActor(c.Request.Context(), service.Actor{UserID: userUUID}))
 		c.Next()
 	}
 }
+
+// accessTokenRequired authenticates a request made with a personal or service access token.
+// Scoped tokens are limited to the routes of their project group or project.
+func (api *authApi) accessTokenRequired(c *gin.Context, secret string) {
+	token, err := api.tokenService.Authenticate(c.Request.Context(), secret)
+	if errors.Is(err, service.ErrInvalidToken) {
+		response.SendUnauthorized(c, response.ErrInvalidToken, "Invalid access token")
+		c.Abort()
+		return
+	}
+	if err != nil {
+		_ = c.Error(err)
+		response.SendInternalServerError(c, response.ErrInternalServer, "Failed to validate access token")
+		c.Abort()
+		return
+	}
+	if token.TokenType == model.AccessTokenTypeSDK {
+		response.SendUnauthorized(c, response.ErrInvalidToken, "SDK tokens can only be used with the SDK API")
+		c.Abort()
+		return
+	}
+
+	if err := api.authorizeAccessToken(c, token); err != nil {
+		sendServiceError(c, err)
+		c.Abort()
+		return
+	}
+
+	c.Set("userID", token.UserID)
+	c.Set("accessToken", token)
+	actor := service.Actor{UserID: token.UserID, AccessTokenID: &token.ID}
+	c.Request = c.Request.WithContext(service.WithActor(c.Request.Context(), actor))
+	c.Next()
+}
+
+func (api *authApi) authorizeAccessToken(c *gin.Context, token *model.AccessToken) error {
+	ctx := c.Request.Context()
+	switch {
+	case token.GroupID == nil && token.ProjectID == nil:
+		return nil
+	case c.Param("slug") != "":
+		return api.tokenService.AuthorizeProject(ctx, token, c.Param("slug"))
+	case strings.Contains(c.FullPath(), "/groups/:id"):
+		groupID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			return fmt.Errorf("%w: invalid id", service.ErrInvalidArgument)
+		}
+		return api.tokenService.AuthorizeGroup(ctx, token, groupID)
+	default:
+		return fmt.Errorf("%w: scoped access tokens cannot use this endpoint", service.ErrPermissionDenied)
+	}
+}
@@ -0,0 +1,66 @@
+package v1
+
+import (
+	"flagon/pkg/api/v1/response"
+	"flagon/pkg/model"
+	"flagon/pkg/service"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Authorizer builds middleware checking the current user's permission on the resource of the route.
+type Authorizer interface {
+	Require(permission model.Permission) gin.HandlerFunc
+}
+
+type authorizer struct {
+	accessService service.AccessService
+}
+
+func NewAuthorizer(accessService service.AccessService) Authorizer {
+	return &authorizer{
+		accessService: accessService,
+	}
+}
+
+// Require checks the permission on the project of a :slug route, narrowed to the environment when the
+// route also has an :envId, or on the project group of a /groups/:id route. It must run after AuthRequired.
+func (a *authorizer) Require(permission model.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		userID := currentUserID(c)
+
+		var err error
+		switch {
+		case c.Param("slug") != "" && c.Param("envId") != "":
+			envID, ok := uuidParam(c, "envId")
+			if !ok {
+				c.Abort()
+				return
+			}
+			err = a.accessService.AuthorizeEnvironment(ctx, userID, c.Param("slug"), envID, permission)
+		case c.Param("slug") != "":
+			err = a.accessService.AuthorizeProject(ctx, userID, c.Param("slug"), permission)
+		case strings.Contains(c.FullPath(), "/groups/:id"):
+			groupID, ok := uuidParam(c, "id")
+			if !ok {
+				c.Abort()
+				return
+			}
+			err = a.accessService.AuthorizeGroup(ctx, userID, groupID, permission)
+		default:
+			// A route without a resource cannot be authorized; refuse rather than let it through.
+			response.SendForbidden(c, response.ErrForbidden, "no resource to authorize")
+			c.Abort()
+			return
+		}
+
+		if err != nil {
+			sendServiceError(c, err)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,159 @@
+package v1
+
+import (
+	"context"
+	"flagon/pkg/model"
+	"flagon/pkg/service"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// fakeAccessService records the check Require made, and answers it with err.
+type fakeAccessService struct {
+	service.AccessService
+	err   error
+	check string
+}
+
+func (s *fakeAccessService) AuthorizeGroup(_ context.Context, _, groupID uuid.UUID, permission model.Permission) error {
+	s.check = fmt.Sprintf("group %s %s", groupID, permission)
+	return s.err
+}
+
+func (s *fakeAccessService) AuthorizeProject(_ context.Context, _ uuid.UUID, projectSlug string, permission model.Permission) error {
+	s.check = fmt.Sprintf("project %s %s", projectSlug, permission)
+	return s.err
+}
+
+func (s *fakeAccessService) AuthorizeEnvironment(_ context.Context, _ uuid.UUID, projectSlug string, envID uuid.UUID, permission model.Permission) error {
+	s.check = fmt.Sprintf("environment %s %s %s", projectSlug, envID, permission)
+	return s.err
+}
+
+func TestRequire(t *testing.T) {
+	groupID, envID := uuid.New(), uuid.New()
+
+	tests := []struct {
+		name       string
+		route      string
+		path       string
+		permission model.Permission
+		err        error
+		wantStatus int
+		// wantCheck is the check Require makes, or empty when it makes none.
+		wantCheck string
+	}{
+		{
+			name:       "group route",
+			route:      "/groups/:id",
+			path:       "/groups/" + groupID.String(),
+			permission: model.PermissionView,
+			wantStatus: http.StatusOK,
+			wantCheck:  "group " + groupID.String() + " view",
+		},
+		{
+			name:       "group sub-route",
+			route:      "/groups/:id/members/:userId",
+			path:       "/groups/" + groupID.String() + "/members/" + uuid.NewString(),
+			permission: model.PermissionManage,
+			wantStatus: http.StatusOK,
+			wantCheck:  "group " + groupID.String() + " manage",
+		},
+		{
+			name:       "group route denied",
+			route:      "/groups/:id",
+			path:       "/groups/" + groupID.String(),
+			permission: model.PermissionOwn,
+			err:        service.ErrPermissionDenied,
+			wantStatus: http.StatusForbidden,
+			wantCheck:  "group " + groupID.String() + " own",
+		},
+		{
+			name:       "invalid group ID",
+			route:      "/groups/:id",
+			path:       "/groups/acme",
+			permission: model.PermissionView,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "project route",
+			route:      "/projects/:slug/features",
+			path:       "/projects/shop/features",
+			permission: model.PermissionEdit,
+			wantStatus: http.StatusOK,
+			wantCheck:  "project shop edit",
+		},
+		{
+			name:       "unknown project",
+			route:      "/projects/:slug",
+			path:       "/projects/blog",
+			permission: model.PermissionView,
+			err:        service.ErrNotFound,
+			wantStatus: http.StatusNotFound,
+			wantCheck:  "project blog view",
+		},
+		{
+			name:       "environment route",
+			route:      "/projects/:slug/environments/:envId/flags",
+			path:       "/projects/shop/environments/" + envID.String() + "/flags",
+			permission: model.PermissionToggle,
+			wantStatus: http.StatusOK,
+			wantCheck:  "environment shop " + envID.String() + " toggle",
+		},
+		{
+			name:       "environment route denied",
+			route:      "/projects/:slug/environments/:envId",
+			path:       "/projects/shop/environments/" + envID.String(),
+			permission: model.PermissionToggle,
+			err:        service.ErrPermissionDenied,
+			wantStatus: http.StatusForbidden,
+			wantCheck:  "environment shop " + envID.String() + " toggle",
+		},
+		{
+			name:       "invalid environment ID",
+			route:      "/projects/:slug/environments/:envId",
+			path:       "/projects/shop/environments/production",
+			permission: model.PermissionToggle,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			// Routes whose :id is not a group must not be mistaken for one.
+			name:       "route without a resource",
+			route:      "/tokens/:id",
+			path:       "/tokens/" + uuid.NewString(),
+			permission: model.PermissionView,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "route without parameters",
+			route:      "/profile",
+			path:       "/profile",
+			permission: model.PermissionView,
+			wantStatus: http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			access := &fakeAccessService{err: tt.err}
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.GET(tt.route, NewAuthorizer(access).Require(tt.permission), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("status %d, want %d", recorder.Code, tt.wantStatus)
+			}
+			if access.check != tt.wantCheck {
+				t.Errorf("checked %q, want %q", access.check, tt.wantCheck)
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package v1
+
+import (
+	"flagon/pkg/api/v1/response"
+	"flagon/pkg/model"
+	"flagon/pkg/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EnvironmentAPI interface {
+	Register(router gin.IRouter)
+}
+
+type environmentApi struct {
+	envService service.EnvironmentService
+	authz      Authorizer
+}
+
+func NewEnvironmentAPI(envService service.EnvironmentService, authz Authorizer) EnvironmentAPI {
+	return &environmentApi{
+		envService: envService,
+		authz:      authz,
+	}
+}
+
+func (api *environmentApi) Register(router gin.IRouter) {
+	envs := router.Group("/projects/:slug/environments")
+	envs.GET("", api.authz.Require(model.PermissionView), api.HandleList)
+	envs.POST("", api.authz.Require(model.PermissionManage), api.HandleCreate)
+	envs.PUT("/order", api.authz.Require(model.PermissionManage), api.HandleReorder)
+	envs.PATCH("/:envId", api.authz.Require(model.PermissionManage), api.HandleUpdate)
+	envs.DELETE("/:envId", api.authz.Require(model.PermissionManage), api.HandleDelete)
+	envs.POST("/:envId/clone", api.authz.Require(model.PermissionManage), api.HandleClone)
+}
+
+// HandleList
+// @Summary List environments
+// @Description List the environments of a project in display order
+// @Tags environments
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "Project slug"
+// @Success 200 {object} response.SuccessResponse[[]model.ProjectEnvironment] "Environments"
+// @Failure 404 {object} response.ErrorResponse[string] "Project not found"
+// @Router /projects/{slug}/environments [get]
+func (api *environmentApi) HandleList(c *gin.Context) {
+	envs, err := api.envService.List(c.Request.Context(), c.Param("slug"))
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Environments", envs)
+}
+
+// HandleCreate
+// @Summary Create an environment
+// @Description Create an empty environment placed after the existing ones
+// @Tags environments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "Project slug"
+// @Param environment body service.CreateEnvironmentRequest true "Environment details"
+// @Success 201 {object} response.SuccessResponse[model.ProjectEnvironment] "Environment created"
+// @Failure 400 {object} response.ErrorResponse[string] "Invalid request"
+// @Failure 404 {object} response.ErrorResponse[string] "Project not found"
+// @Failure 409 {object} response.ErrorResponse[string] "Environment name already in use"
+// @Router /projects/{slug}/environments [post]
+func (api *environmentApi) HandleCreate(c *gin.Context) {
+	var req service.CreateEnvironmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendBadRequest(c, response.ErrInvalidRequest, err.Error())
+		return
+	}
+
+	env, err := api.envService.Create(c.Request.Context(), c.Param("slug"), &req)
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendCreated(c, "Environment created", env)
+}
+
+// HandleReorder
+// @Summary Reorder environments
+// @Description Set the display order of all environments of a project
+// @Tags environments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "Project slug"
+// @Param order body service.ReorderEnvironmentsRequest true "Environment IDs in the desired order"
+// @Success 200 {object} response.SuccessResponse[[]model.ProjectEnvironment] "Environments reordered"
+// @Failure 400 {object} response.ErrorResponse[string] "The IDs do not match the project's environments"
+// @Failure 404 {object} response.ErrorResponse[string] "Project not found"
+// @Router /projects/{slug}/environments/order [put]
+func (api *environmentApi) HandleReorder(c *gin.Context) {
+	var req service.ReorderEnvironmentsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendBadRequest(c, response.ErrInvalidRequest, err.Error())
+		return
+	}
+
+	envs, err := api.envService.Reorder(c.Request.Context(), c.Param("slug"), &req)
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Environments reordered", envs)
+}
+
+// HandleUpdate
+// @Summary Update an environment
+// @Description Rename an environment or change its description
+// @Tags environments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "Project slug"
+// @Param envId path string true "Environment ID"
+// @Param environment body service.UpdateEnvironmentRequest true "Fields to update"
+// @Success 200 {object} response.SuccessResponse[model.ProjectEnvironment] "Environment updated"
+// @Failure 404 {object} response.ErrorResponse[string] "Environment not found"
+// @Failure 409 {object} response.ErrorResponse[string] "Environment name already in use"
+// @Router /projects/{slug}/environments/{envId} [patch]
+func (api *environmentApi) HandleUpdate(c *gin.Context) {
+	id, ok := uuidParam(c, "envId")
+	if !ok {
+		return
+	}
+
+	var req service.UpdateEnvironmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendBadRequest(c, response.ErrInvalidRequest, err.Error())
+		return
+	}
+
+	env, err := api.envService.Update(c.Request.Context(), c.Param("slug"), id, &req)
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Environment updated", env)
+}
+
+// HandleDelete
+// @Summary Delete an environment
+// @Description Delete an environment together with its flag states
+// @Tags environments
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "Project slug"
+// @Param envId path string true "Environment ID"
+// @Success 200 {object} response.SuccessResponse[string] "Environment deleted"
+// @Failure 404 {object} response.ErrorResponse[string] "Environment not found"
+// @Router /projects/{slug}/environments/{envId} [delete]
+func (api *environmentApi) HandleDelete(c *gin.Context) {
+	id, ok := uuidParam(c, "envId")
+	if !ok {
+		return
+	}
+
+	if err := api.envService.Delete(c.Request.Context(), c.Param("slug"), id); err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Environment deleted", nil)
+}
+
+// HandleClone
+// @Summary Clone an environment
+// @Description Create a new environment starting from the flag states and target groups of an existing one
+// @Tags environments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "Project slug"
+// @Param envId path string true "Source environment ID"
+// @Param environment body service.CreateEnvironmentRequest true "New environment details"
+// @Success 201 {object} response.SuccessResponse[model.ProjectEnvironment] "Environment cloned"
+// @Failure 404 {object} response.ErrorResponse[string] "Source environment not found"
+// @Failure 409 {object} response.ErrorResponse[string] "Environment name already in use"
+// @Router /projects/{slug}/environments/{envId}/clone [post]
+func (api *environmentApi) HandleClone(c *gin.Context) {
+	id, ok := uuidParam(c, "envId")
+	if !ok {
+		return
+	}
+
+	var req service.CreateEnvironmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendBadRequest(c, response.ErrInvalidRequest, err.Error())
+		return
+	}
+
+	env, err := api.envService.Clone(c.Request.Context(), c.Param("slug"), id, &req)
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendCreated(c, "Environment cloned", env)
+}
@@ -0,0 +1,437 @@
+package v1
+
+import (
+	"flagon/pkg/api/v1/response"
+	"flagon/pkg/model"
+	"flagon/pkg/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type FeatureAPI interface {
+	Register(router gin.IRouter)
+}
+
+type featureApi struct {
+	featureService service.FeatureService
+	authz          Authorizer
+}
+
+func NewFeatureAPI(featureService service.FeatureService, authz Authorizer) FeatureAPI {
+	return &featureApi{
+		featureService: featureService,
+		authz:          authz,
+	}
+}
+
+func (api *featureApi) Register(router gin.IRouter) {
+	view := api.authz.Require(model.PermissionView)
+	edit := api.authz.Require(model.PermissionEdit)
+	toggle := api.authz.Require(model.PermissionToggle)
+
+	categories := router.Group("/projects/:slug/categories")
+	categories.GET("", view, api.HandleListCategories)
+	categories.POST("", edit, api.HandleCreateCategory)
+	categories.PATCH("/:categoryId", edit, api.HandleUpdateCategory)
+	categories.DELETE("/:categoryId", edit, api.HandleDeleteCategory)
+
+	features := router.Group("/projects/:slug/features")
+	features.GET("", view, api.HandleList)
+	features.POST("", edit, api.HandleCreate)
+	features.GET("/:key", view, api.HandleGet)
+	features.PATCH("/:key", edit, api.HandleUpdate)
+	features.DELETE("/:key", edit, api.HandleDelete)
+	features.GET("/:key/flags", view, api.HandleListFlags)
+	features.PUT("/:key/environments/:envId", toggle, api.HandleSetFlag)
+	features.DELETE("/:key/environments/:envId", toggle, api.HandleClearFlag)
+	features.PUT("/:key/environments/:envId/target-groups/:targetGroupId", toggle, api.HandleSetTargetFlag)
+	features.DELETE("/:key/environments/:envId/target-groups/:targetGroupId", toggle, api.HandleClearTargetFlag)
+}
+
+// HandleListCategories
+// @Summary List categories
+// @Tags features
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "Project slug"
+// @Success 200 {object} response.SuccessResponse[[]model.Category] "Categories"
+// @Failure 404 {object} response.ErrorResponse[string] "Project not found"
+// @Router /projects/{slug}/categories [get]
+func (api *featureApi) HandleListCategories(c *gin.Context) {
+	categories, err := api.featureService.ListCategories(c.Request.Context(), c.Param("slug"))
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Categories", categories)
+}
+
+// HandleCreateCategory
+// @Summary Create a category
+// @Tags features
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "Project slug"
+// @Param category body service.CreateCategoryRequest true "Category details"
+// @Success 201 {object} response.SuccessResponse[model.Category] "Category created"
+// @Failure 404 {object} response.ErrorResponse[string] "Project not found"
+// @Failure 409 {object} response.ErrorResponse[string] "Category name already in use"
+// @Router /projects/{slug}/categories [post]
+func (api *featureApi) HandleCreateCategory(c *gin.Context) {
+	var req service.CreateCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendBadRequest(c, response.ErrInvalidRequest, err.Error())
+		return
+	}
+
+	category, err := api.featureService.CreateCategory(c.Request.Context(), c.Param("slug"), &req)
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendCreated(c, "Category created", category)
+}
+
+// HandleUpdateCategory
+// @Summary Update a category
+// @Tags features
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "Project slug"
+// @Param categoryId path string true "Category ID"
+// @Param category body service.UpdateCategoryRequest true "Fields to update"
+// @Success 200 {object} response.SuccessResponse[model.Category] "Category updated"
+// @Failure 404 {object} response.ErrorResponse[string] "Category not found"
+// @Failure 409 {object} response.ErrorResponse[string] "Category name already in use"
+// @Router /projects/{slug}/categories/{categoryId} [patch]
+func (api *featureApi) HandleUpdateCategory(c *gin.Context) {
+	id, ok := uuidParam(c, "categoryId")
+	if !ok {
+		return
+	}
+
+	var req service.UpdateCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendBadRequest(c, response.ErrInvalidRequest, err.Error())
+		return
+	}
+
+	category, err := api.featureService.UpdateCategory(c.Request.Context(), c.Param("slug"), id, &req)
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Category updated", category)
+}
+
+// HandleDeleteCategory
+// @Summary Delete a category
+// @Description Delete a category together with every feature in it
+// @Tags features
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "Project slug"
+// @Param categoryId path string true "Category ID"
+// @Success 200 {object} response.SuccessResponse[string] "Category deleted"
+// @Failure 404 {object} response.ErrorResponse[string] "Category not found"
+// @Router /projects/{slug}/categories/{categoryId} [delete]
+func (api *featureApi) HandleDeleteCategory(c *gin.Context) {
+	id, ok := uuidParam(c, "categoryId")
+	if !ok {
+		return
+	}
+
+	if err := api.featureService.DeleteCategory(c.Request.Context(), c.Param("slug"), id); err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Category deleted", nil)
+}
+
+// HandleList
+// @Summary List features
+// @Tags features
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "Project slug"
+// @Param category query string false "Only list features of this category ID"
+// @Success 200 {object} response.SuccessResponse[[]model.Feature] "Features"
+// @Failure 404 {object} response.ErrorResponse[string] "Project not found"
+// @Router /projects/{slug}/features [get]
+func (api *featureApi) HandleList(c *gin.Context) {
+	var categoryID *uuid.UUID
+	if raw := c.Query("category"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			response.SendBadRequest(c, response.ErrInvalidRequest, "invalid category")
+			return
+		}
+		categoryID = &id
+	}
+
+	features, err := api.featureService.List(c.Request.Context(), c.Param("slug"), categoryID)
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Features", features)
+}
+
+// HandleCreate
+// @Summary Create a feature
+// @Description Define a new feature flag; keys start with a letter and contain letters, digits, '.', '_' or '-'
+// @Tags features
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "Project slug"
+// @Param feature body service.CreateFeatureRequest true "Feature details"
+// @Success 201 {object} response.SuccessResponse[model.Feature] "Feature created"
+// @Failure 400 {object} response.ErrorResponse[string] "Invalid feature key"
+// @Failure 404 {object} response.ErrorResponse[string] "Project or category not found"
+// @Failure 409 {object} response.ErrorResponse[string] "Feature key already in use"
+// @Router /projects/{slug}/features [post]
+func (api *featureApi) HandleCreate(c *gin.Context) {
+	var req service.CreateFeatureRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendBadRequest(c, response.ErrInvalidRequest, err.Error())
+		return
+	}
+
+	feature, err := api.featureService.Create(c.Request.Context(), c.Param("slug"), &req)
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendCreated(c, "Feature created", feature)
+}
+
+// HandleGet
+// @Summary Get a feature
+// @Tags features
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "Project slug"
+// @Param key path string true "Feature key"
+// @Success 200 {object} response.SuccessResponse[model.Feature] "Feature"
+// @Failure 404 {object} response.ErrorResponse[string] "Feature not found"
+// @Router /projects/{slug}/features/{key} [get]
+func (api *featureApi) HandleGet(c *gin.Context) {
+	feature, err := api.featureService.Get(c.Request.Context(), c.Param("slug"), c.Param("key"))
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Feature", feature)
+}
+
+// HandleUpdate
+// @Summary Update a feature
+// @Tags features
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "Project slug"
+// @Param key path string true "Feature key"
+// @Param feature body service.UpdateFeatureRequest true "Fields to update"
+// @Success 200 {object} response.SuccessResponse[model.Feature] "Feature updated"
+// @Failure 400 {object} response.ErrorResponse[string] "Invalid feature key"
+// @Failure 404 {object} response.ErrorResponse[string] "Feature or category not found"
+// @Failure 409 {object} response.ErrorResponse[string] "Feature key already in use"
+// @Router /projects/{slug}/features/{key} [patch]
+func (api *featureApi) HandleUpdate(c *gin.Context) {
+	var req service.UpdateFeatureRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendBadRequest(c, response.ErrInvalidRequest, err.Error())
+		return
+	}
+
+	feature, err := api.featureService.Update(c.Request.Context(), c.Param("slug"), c.Param("key"), &req)
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Feature updated", feature)
+}
+
+// HandleDelete
+// @Summary Delete a feature
+// @Tags features
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "Project slug"
+// @Param key path string true "Feature key"
+// @Success 200 {object} response.SuccessResponse[string] "Feature deleted"
+// @Failure 404 {object} response.ErrorResponse[string] "Feature not found"
+// @Router /projects/{slug}/features/{key} [delete]
+func (api *featureApi) HandleDelete(c *gin.Context) {
+	if err := api.featureService.Delete(c.Request.Context(), c.Param("slug"), c.Param("key")); err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Feature deleted", nil)
+}
+
+// HandleListFlags
+// @Summary List flag states of a feature
+// @Description List the per-environment states of a feature
+// @Tags features
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "Project slug"
+// @Param key path string true "Feature key"
+// @Success 200 {object} response.SuccessResponse[[]model.ProjectFeatureFlag] "Flag states"
+// @Failure 404 {object} response.ErrorResponse[string] "Feature not found"
+// @Router /projects/{slug}/features/{key}/flags [get]
+func (api *featureApi) HandleListFlags(c *gin.Context) {
+	flags, err := api.featureService.ListFlags(c.Request.Context(), c.Param("slug"), c.Param("key"))
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Flag states", flags)
+}
+
+// HandleSetFlag
+// @Summary Toggle a feature in an environment
+// @Tags features
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "Project slug"
+// @Param key path string true "Feature key"
+// @Param envId path string true "Environment ID"
+// @Param flag body service.SetFlagRequest true "Flag state"
+// @Success 200 {object} response.SuccessResponse[model.ProjectFeatureFlag] "Flag updated"
+// @Failure 404 {object} response.ErrorResponse[string] "Feature or environment not found"
+// @Router /projects/{slug}/features/{key}/environments/{envId} [put]
+func (api *featureApi) HandleSetFlag(c *gin.Context) {
+	envID, ok := uuidParam(c, "envId")
+	if !ok {
+		return
+	}
+
+	var req service.SetFlagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendBadRequest(c, response.ErrInvalidRequest, err.Error())
+		return
+	}
+
+	flag, err := api.featureService.SetFlag(c.Request.Context(), c.Param("slug"), c.Param("key"), envID, &req)
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Flag updated", flag)
+}
+
+// HandleClearFlag
+// @Summary Reset a feature in an environment
+// @Description Remove the environment's state so the feature falls back to its default value
+// @Tags features
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "Project slug"
+// @Param key path string true "Feature key"
+// @Param envId path string true "Environment ID"
+// @Success 200 {object} response.SuccessResponse[string] "Flag reset"
+// @Failure 404 {object} response.ErrorResponse[string] "Feature or environment not found"
+// @Router /projects/{slug}/features/{key}/environments/{envId} [delete]
+func (api *featureApi) HandleClearFlag(c *gin.Context) {
+	envID, ok := uuidParam(c, "envId")
+	if !ok {
+		return
+	}
+
+	if err := api.featureService.ClearFlag(c.Request.Context(), c.Param("slug"), c.Param("key"), envID); err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Flag reset", nil)
+}
+
+// HandleSetTargetFlag
+// @Summary Toggle a feature for a target group
+// @Description Set the state of a feature for the members of a target group in an environment
+// @Tags features
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "Project slug"
+// @Param key path string true "Feature key"
+// @Param envId path string true "Environment ID"
+// @Param targetGroupId path string true "Target group ID"
+// @Param flag body service.SetFlagRequest true "Flag state"
+// @Success 200 {object} response.SuccessResponse[model.ProjectFeatureFlag] "Flag updated"
+// @Failure 404 {object} response.ErrorResponse[string] "Feature, environment or target group not found"
+// @Router /projects/{slug}/features/{key}/environments/{envId}/target-groups/{targetGroupId} [put]
+func (api *featureApi) HandleSetTargetFlag(c *gin.Context) {
+	envID, ok := uuidParam(c, "envId")
+	if !ok {
+		return
+	}
+	targetGroupID, ok := uuidParam(c, "targetGroupId")
+	if !ok {
+		return
+	}
+
+	var req service.SetFlagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendBadRequest(c, response.ErrInvalidRequest, err.Error())
+		return
+	}
+
+	flag, err := api.featureService.SetTargetFlag(c.Request.Context(), c.Param("slug"), c.Param("key"), envID, targetGroupID, &req)
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Flag updated", flag)
+}
+
+// HandleClearTargetFlag
+// @Summary Reset a feature for a target group
+// @Description Remove the target group's state so its members get the environment's state
+// @Tags features
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "Project slug"
+// @Param key path string true "Feature key"
+// @Param envId path string true "Environment ID"
+// @Param targetGroupId path string true "Target group ID"
+// @Success 200 {object} response.SuccessResponse[string] "Flag reset"
+// @Failure 404 {object} response.ErrorResponse[string] "Feature, environment or target group not found"
+// @Router /projects/{slug}/features/{key}/environments/{envId}/target-groups/{targetGroupId} [delete]
+func (api *featureApi) HandleClearTargetFlag(c *gin.Context) {
+	envID, ok := uuidParam(c, "envId")
+	if !ok {
+		return
+	}
+	targetGroupID, ok := uuidParam(c, "targetGroupId")
+	if !ok {
+		return
+	}
+
+	if err := api.featureService.ClearTargetFlag(c.Request.Context(), c.Param("slug"), c.Param("key"), envID, targetGroupID); err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Flag reset", nil)
+}
@@ -0,0 +1,51 @@
+package v1
+
+import (
+	"errors"
+	"flagon/pkg/api/v1/response"
+	"flagon/pkg/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// currentUserID returns the ID of the authenticated user set by AuthRequired.
+func currentUserID(c *gin.Context) uuid.UUID {
+	userID, _ := c.Get("userID")
+	id, _ := userID.(uuid.UUID)
+	return id
+}
+
+// currentSessionID returns the ID of the session set by AuthRequired. Requests made with access
+// tokens have no session.
+func currentSessionID(c *gin.Context) (string, bool) {
+	sessionID := c.GetString("sessionID")
+	return sessionID, sessionID != ""
+}
+
+// uuidParam parses a UUID path parameter, sending a 400 response when it is malformed.
+func uuidParam(c *gin.Context, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		response.SendBadRequest(c, response.ErrInvalidRequest, "invalid "+name)
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// sendServiceError maps service errors to the matching HTTP response.
+func sendServiceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		response.SendNotFound(c, response.ErrNotFound, err.Error())
+	case errors.Is(err, service.ErrAlreadyExists):
+		response.SendConflict(c, response.ErrDuplicateEntry, err.Error())
+	case errors.Is(err, service.ErrPermissionDenied):
+		response.SendForbidden(c, response.ErrForbidden, err.Error())
+	case errors.Is(err, service.ErrInvalidArgument):
+		response.SendBadRequest(c, response.ErrValidationFailed, err.Error())
+	default:
+		_ = c.Error(err)
+		response.SendInternalServerError(c, response.ErrInternalServer, nil)
+	}
+}
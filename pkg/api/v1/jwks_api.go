@@ -0,0 +1,44 @@
+package v1
+
+import (
+	"flagon/pkg/api/v1/response"
+	"flagon/pkg/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type JWKSAPI interface {
+	Register(router gin.IRouter)
+}
+
+type jwksApi struct {
+	keyService service.SigningKeyService
+}
+
+func NewJWKSAPI(keyService service.SigningKeyService) JWKSAPI {
+	return &jwksApi{
+		keyService: keyService,
+	}
+}
+
+// Register adds the key set at the root of the router, where other services look for it.
+func (api *jwksApi) Register(router gin.IRouter) {
+	router.GET("/.well-known/jwks.json", api.HandleJWKS)
+}
+
+// HandleJWKS serves the public keys verifying the JWTs Flagon issues, as a JWK set (RFC 7517). It
+// lives outside the API base path, so it is not part of the Swagger documentation. Keys are only
+// cached briefly, as a new key signs tokens as soon as it is rotated in; verifiers meeting an unknown
+// kid should fetch the set again.
+func (api *jwksApi) HandleJWKS(c *gin.Context) {
+	set, err := api.keyService.JWKS(c.Request.Context())
+	if err != nil {
+		_ = c.Error(err)
+		response.SendInternalServerError(c, response.ErrInternalServer, nil)
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, set)
+}
@@ -0,0 +1,149 @@
+package v1
+
+import (
+	"flagon/pkg/api/v1/response"
+	"flagon/pkg/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MFAAPI interface {
+	Register(router gin.IRouter)
+}
+
+type mfaApi struct {
+	mfaService service.MFAService
+}
+
+func NewMFAAPI(mfaService service.MFAService) MFAAPI {
+	return &mfaApi{
+		mfaService: mfaService,
+	}
+}
+
+func (api *mfaApi) Register(router gin.IRouter) {
+	mfa := router.Group("/mfa")
+	mfa.GET("", api.HandleStatus)
+	mfa.POST("/totp", api.HandleEnroll)
+	mfa.POST("/totp/confirm", api.HandleConfirm)
+	mfa.DELETE("/totp", api.HandleDisable)
+	mfa.POST("/recovery-codes", api.HandleRegenerateRecoveryCodes)
+}
+
+// HandleStatus
+// @Summary Get two-factor authentication status
+// @Tags mfa
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.SuccessResponse[service.MFAStatus] "Two-factor authentication status"
+// @Router /mfa [get]
+func (api *mfaApi) HandleStatus(c *gin.Context) {
+	status, err := api.mfaService.Status(c.Request.Context(), currentUserID(c))
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Two-factor authentication status", status)
+}
+
+// HandleEnroll
+// @Summary Set up an authenticator
+// @Description Create the secret of a TOTP authenticator app. Two-factor authentication is enabled once a first code is confirmed; until then, setting up again replaces the secret.
+// @Tags mfa
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.SuccessResponse[service.MFAEnrollment] "Authenticator created"
+// @Failure 409 {object} response.ErrorResponse[string] "Two-factor authentication already enabled"
+// @Router /mfa/totp [post]
+func (api *mfaApi) HandleEnroll(c *gin.Context) {
+	enrollment, err := api.mfaService.Enroll(c.Request.Context(), currentUserID(c))
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Authenticator created", enrollment)
+}
+
+// HandleConfirm
+// @Summary Enable two-factor authentication
+// @Description Confirm the authenticator with a code it shows, enabling two-factor authentication. Returns the recovery codes, which are not shown again. The other sessions of the user are revoked.
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param code body service.MFACodeRequest true "Code of the authenticator"
+// @Success 200 {object} response.SuccessResponse[service.RecoveryCodes] "Two-factor authentication enabled"
+// @Failure 400 {object} response.ErrorResponse[string] "Invalid code"
+// @Failure 409 {object} response.ErrorResponse[string] "Two-factor authentication already enabled"
+// @Router /mfa/totp/confirm [post]
+func (api *mfaApi) HandleConfirm(c *gin.Context) {
+	var req service.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendBadRequest(c, response.ErrInvalidRequest, err.Error())
+		return
+	}
+
+	sessionID, _ := currentSessionID(c)
+	codes, err := api.mfaService.Confirm(c.Request.Context(), currentUserID(c), sessionID, &req)
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Two-factor authentication enabled", codes)
+}
+
+// HandleDisable
+// @Summary Disable two-factor authentication
+// @Description Remove the authenticator and the recovery codes. Requires a code of the authenticator or a recovery code.
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param code body service.MFACodeRequest true "Code of the authenticator or recovery code"
+// @Success 200 {object} response.SuccessResponse[string] "Two-factor authentication disabled"
+// @Failure 400 {object} response.ErrorResponse[string] "Invalid code"
+// @Router /mfa/totp [delete]
+func (api *mfaApi) HandleDisable(c *gin.Context) {
+	var req service.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendBadRequest(c, response.ErrInvalidRequest, err.Error())
+		return
+	}
+
+	if err := api.mfaService.Disable(c.Request.Context(), currentUserID(c), &req); err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Two-factor authentication disabled", nil)
+}
+
+// HandleRegenerateRecoveryCodes
+// @Summary Regenerate recovery codes
+// @Description Replace the recovery codes, invalidating the previous ones. Requires a code of the authenticator or a recovery code.
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param code body service.MFACodeRequest true "Code of the authenticator or recovery code"
+// @Success 200 {object} response.SuccessResponse[service.RecoveryCodes] "Recovery codes regenerated"
+// @Failure 400 {object} response.ErrorResponse[string] "Invalid code"
+// @Router /mfa/recovery-codes [post]
+func (api *mfaApi) HandleRegenerateRecoveryCodes(c *gin.Context) {
+	var req service.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendBadRequest(c, response.ErrInvalidRequest, err.Error())
+		return
+	}
+
+	codes, err := api.mfaService.RegenerateRecoveryCodes(c.Request.Context(), currentUserID(c), &req)
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Recovery codes regenerated", codes)
+}
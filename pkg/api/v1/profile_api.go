@@ -0,0 +1,140 @@
+package v1
+
+import (
+	"flagon/pkg/api/v1/response"
+	"flagon/pkg/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProfileAPI interface {
+	Register(router gin.IRouter)
+}
+
+type profileApi struct {
+	profileService service.ProfileService
+}
+
+func NewProfileAPI(profileService service.ProfileService) ProfileAPI {
+	return &profileApi{
+		profileService: profileService,
+	}
+}
+
+func (api *profileApi) Register(router gin.IRouter) {
+	me := router.Group("/me")
+	me.GET("", api.HandleGet)
+	me.PATCH("", api.HandleUpdate)
+	me.PUT("/password", api.HandleChangePassword)
+	me.GET("/groups", api.HandleListGroups)
+	me.GET("/projects", api.HandleListProjects)
+}
+
+// HandleGet
+// @Summary Get the current user
+// @Description Get the profile of the current user
+// @Tags me
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.SuccessResponse[model.User] "User"
+// @Router /me [get]
+func (api *profileApi) HandleGet(c *gin.Context) {
+	user, err := api.profileService.Get(c.Request.Context(), currentUserID(c))
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "User", user)
+}
+
+// HandleUpdate
+// @Summary Update the current user
+// @Description Change the name, avatar or email address of the current user. Changing the email address requires the current password, and a code of the authenticator when two-factor authentication is enabled. A new email address is unverified until the user opens the link emailed to it.
+// @Tags me
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param profile body service.UpdateProfileRequest true "Fields to update"
+// @Success 200 {object} response.SuccessResponse[model.User] "Profile updated"
+// @Failure 400 {object} response.ErrorResponse[string] "Invalid request, wrong password or code"
+// @Failure 409 {object} response.ErrorResponse[string] "Email address already in use"
+// @Router /me [patch]
+func (api *profileApi) HandleUpdate(c *gin.Context) {
+	var req service.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendBadRequest(c, response.ErrInvalidRequest, err.Error())
+		return
+	}
+
+	user, err := api.profileService.Update(c.Request.Context(), currentUserID(c), &req)
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Profile updated", user)
+}
+
+// HandleChangePassword
+// @Summary Change the password
+// @Description Change the password of the current user, who must enter the current one. Every other session of the user is revoked.
+// @Tags me
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body service.ChangePasswordRequest true "Current and new password"
+// @Success 200 {object} response.SuccessResponse[string] "Password changed"
+// @Failure 400 {object} response.ErrorResponse[string] "Wrong current password"
+// @Router /me/password [put]
+func (api *profileApi) HandleChangePassword(c *gin.Context) {
+	var req service.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendBadRequest(c, response.ErrInvalidRequest, err.Error())
+		return
+	}
+
+	sessionID, _ := currentSessionID(c)
+	if err := api.profileService.ChangePassword(c.Request.Context(), currentUserID(c), sessionID, &req); err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Password changed", nil)
+}
+
+// HandleListGroups
+// @Summary List the groups of the current user
+// @Description List the project groups the current user owns or was added to, with their role in each. Sub-groups they can access through a parent group are not listed.
+// @Tags me
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.SuccessResponse[[]model.ProjectGroupMembership] "Project groups"
+// @Router /me/groups [get]
+func (api *profileApi) HandleListGroups(c *gin.Context) {
+	groups, err := api.profileService.ListGroups(c.Request.Context(), currentUserID(c))
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Project groups", groups)
+}
+
+// HandleListProjects
+// @Summary List the projects of the current user
+// @Description List the projects the current user owns or was added to, with their role in each. Projects they can access through a project group are not listed.
+// @Tags me
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.SuccessResponse[[]model.ProjectMembership] "Projects"
+// @Router /me/projects [get]
+func (api *profileApi) HandleListProjects(c *gin.Context) {
+	projects, err := api.profileService.ListProjects(c.Request.Context(), currentUserID(c))
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Projects", projects)
+}
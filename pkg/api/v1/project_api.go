@@ -0,0 +1,286 @@
+package v1
+
+import (
+	"flagon/pkg/api/v1/response"
+	"flagon/pkg/model"
+	"flagon/pkg/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProjectAPI interface {
+	Register(router gin.IRouter)
+}
+
+type projectApi struct {
+	projectService service.ProjectService
+	authz          Authorizer
+}
+
+func NewProjectAPI(projectService service.ProjectService, authz Authorizer) ProjectAPI {
+	return &projectApi{
+		projectService: projectService,
+		authz:          authz,
+	}
+}
+
+func (api *projectApi) Register(router gin.IRouter) {
+	projects := router.Group("/projects")
+	projects.POST("", api.HandleCreate)
+	projects.GET("", api.HandleList)
+	projects.GET("/:slug", api.authz.Require(model.PermissionView), api.HandleGet)
+	projects.PATCH("/:slug", api.authz.Require(model.PermissionManage), api.HandleUpdate)
+	projects.PUT("/:slug/owner", api.authz.Require(model.PermissionOwn), api.HandleTransferOwnership)
+	projects.DELETE("/:slug", api.authz.Require(model.PermissionOwn), api.HandleDelete)
+	projects.GET("/:slug/members", api.authz.Require(model.PermissionView), api.HandleListMembers)
+	projects.POST("/:slug/members", api.authz.Require(model.PermissionManage), api.HandleAddMember)
+	projects.PUT("/:slug/members/:userId", api.authz.Require(model.PermissionManage), api.HandleUpdateMember)
+	projects.DELETE("/:slug/members/:userId", api.authz.Require(model.PermissionManage), api.HandleRemoveMember)
+}
+
+// HandleCreate
+// @Summary Create a project
+// @Description Create a project inside a project group; the caller becomes its owner
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param project body service.CreateProjectRequest true "Project details"
+// @Success 201 {object} response.SuccessResponse[model.Project] "Project created"
+// @Failure 400 {object} response.ErrorResponse[string] "Invalid request"
+// @Failure 404 {object} response.ErrorResponse[string] "Project group not found"
+// @Failure 409 {object} response.ErrorResponse[string] "Slug already in use"
+// @Router /projects [post]
+func (api *projectApi) HandleCreate(c *gin.Context) {
+	var req service.CreateProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendBadRequest(c, response.ErrInvalidRequest, err.Error())
+		return
+	}
+
+	project, err := api.projectService.Create(c.Request.Context(), currentUserID(c), &req)
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendCreated(c, "Project created", project)
+}
+
+// HandleList
+// @Summary List projects
+// @Description List the projects visible to the caller through ownership, membership or their project groups
+// @Tags projects
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.SuccessResponse[[]model.Project] "Projects"
+// @Failure 401 {object} response.ErrorResponse[string] "Unauthorized"
+// @Router /projects [get]
+func (api *projectApi) HandleList(c *gin.Context) {
+	projects, err := api.projectService.List(c.Request.Context(), currentUserID(c))
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Projects", projects)
+}
+
+// HandleGet
+// @Summary Get a project
+// @Tags projects
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "Project slug"
+// @Success 200 {object} response.SuccessResponse[model.Project] "Project"
+// @Failure 404 {object} response.ErrorResponse[string] "Project not found"
+// @Router /projects/{slug} [get]
+func (api *projectApi) HandleGet(c *gin.Context) {
+	project, err := api.projectService.Get(c.Request.Context(), c.Param("slug"))
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Project", project)
+}
+
+// HandleUpdate
+// @Summary Update a project
+// @Description Change the slug, name or description of a project
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "Project slug"
+// @Param project body service.UpdateProjectRequest true "Fields to update"
+// @Success 200 {object} response.SuccessResponse[model.Project] "Project updated"
+// @Failure 400 {object} response.ErrorResponse[string] "Invalid request"
+// @Failure 404 {object} response.ErrorResponse[string] "Project not found"
+// @Failure 409 {object} response.ErrorResponse[string] "Slug already in use"
+// @Router /projects/{slug} [patch]
+func (api *projectApi) HandleUpdate(c *gin.Context) {
+	var req service.UpdateProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendBadRequest(c, response.ErrInvalidRequest, err.Error())
+		return
+	}
+
+	project, err := api.projectService.Update(c.Request.Context(), c.Param("slug"), &req)
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Project updated", project)
+}
+
+// HandleTransferOwnership
+// @Summary Transfer project ownership
+// @Description Make another user the owner of the project
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "Project slug"
+// @Param owner body service.TransferProjectRequest true "New owner"
+// @Success 200 {object} response.SuccessResponse[model.Project] "Project ownership transferred"
+// @Failure 404 {object} response.ErrorResponse[string] "Project or user not found"
+// @Router /projects/{slug}/owner [put]
+func (api *projectApi) HandleTransferOwnership(c *gin.Context) {
+	var req service.TransferProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendBadRequest(c, response.ErrInvalidRequest, err.Error())
+		return
+	}
+
+	project, err := api.projectService.TransferOwnership(c.Request.Context(), c.Param("slug"), &req)
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Project ownership transferred", project)
+}
+
+// HandleDelete
+// @Summary Delete a project
+// @Description Delete a project with all of its environments, features and flags
+// @Tags projects
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "Project slug"
+// @Success 200 {object} response.SuccessResponse[string] "Project deleted"
+// @Failure 404 {object} response.ErrorResponse[string] "Project not found"
+// @Router /projects/{slug} [delete]
+func (api *projectApi) HandleDelete(c *gin.Context) {
+	if err := api.projectService.Delete(c.Request.Context(), c.Param("slug")); err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Project deleted", nil)
+}
+
+// HandleListMembers
+// @Summary List project members
+// @Tags projects
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "Project slug"
+// @Success 200 {object} response.SuccessResponse[[]model.ProjectMember] "Project members"
+// @Failure 404 {object} response.ErrorResponse[string] "Project not found"
+// @Router /projects/{slug}/members [get]
+func (api *projectApi) HandleListMembers(c *gin.Context) {
+	members, err := api.projectService.ListMembers(c.Request.Context(), c.Param("slug"))
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Project members", members)
+}
+
+// HandleAddMember
+// @Summary Add a project member
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "Project slug"
+// @Param member body service.AddProjectMemberRequest true "User to add"
+// @Success 201 {object} response.SuccessResponse[string] "Project member added"
+// @Failure 404 {object} response.ErrorResponse[string] "Project or user not found"
+// @Failure 409 {object} response.ErrorResponse[string] "User is already a member"
+// @Router /projects/{slug}/members [post]
+func (api *projectApi) HandleAddMember(c *gin.Context) {
+	var req service.AddProjectMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendBadRequest(c, response.ErrInvalidRequest, err.Error())
+		return
+	}
+
+	if err := api.projectService.AddMember(c.Request.Context(), c.Param("slug"), &req); err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendCreated(c, "Project member added", nil)
+}
+
+// HandleUpdateMember
+// @Summary Change the role of a project member
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "Project slug"
+// @Param userId path string true "User ID"
+// @Param member body service.UpdateMemberRequest true "New role"
+// @Success 200 {object} response.SuccessResponse[string] "Project member updated"
+// @Failure 404 {object} response.ErrorResponse[string] "Project or member not found"
+// @Router /projects/{slug}/members/{userId} [put]
+func (api *projectApi) HandleUpdateMember(c *gin.Context) {
+	userID, ok := uuidParam(c, "userId")
+	if !ok {
+		return
+	}
+
+	var req service.UpdateMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendBadRequest(c, response.ErrInvalidRequest, err.Error())
+		return
+	}
+
+	if err := api.projectService.UpdateMember(c.Request.Context(), c.Param("slug"), userID, &req); err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Project member updated", nil)
+}
+
+// HandleRemoveMember
+// @Summary Remove a project member
+// @Tags projects
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "Project slug"
+// @Param userId path string true "User ID"
+// @Success 200 {object} response.SuccessResponse[string] "Project member removed"
+// @Failure 400 {object} response.ErrorResponse[string] "The owner cannot be removed"
+// @Failure 404 {object} response.ErrorResponse[string] "Project or member not found"
+// @Router /projects/{slug}/members/{userId} [delete]
+func (api *projectApi) HandleRemoveMember(c *gin.Context) {
+	userID, ok := uuidParam(c, "userId")
+	if !ok {
+		return
+	}
+
+	if err := api.projectService.RemoveMember(c.Request.Context(), c.Param("slug"), userID); err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Project member removed", nil)
+}
@@ -0,0 +1,345 @@
+package v1
+
+import (
+	"flagon/pkg/api/v1/response"
+	"flagon/pkg/model"
+	"flagon/pkg/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProjectGroupAPI interface {
+	Register(router gin.IRouter)
+}
+
+type projectGroupApi struct {
+	groupService service.ProjectGroupService
+	authz        Authorizer
+}
+
+func NewProjectGroupAPI(groupService service.ProjectGroupService, authz Authorizer) ProjectGroupAPI {
+	return &projectGroupApi{
+		groupService: groupService,
+		authz:        authz,
+	}
+}
+
+func (api *projectGroupApi) Register(router gin.IRouter) {
+	groups := router.Group("/groups")
+	groups.POST("", api.HandleCreate)
+	groups.GET("", api.HandleList)
+	groups.GET("/tree", api.HandleTree)
+	groups.GET("/:id", api.authz.Require(model.PermissionView), api.HandleGet)
+	groups.PATCH("/:id", api.authz.Require(model.PermissionManage), api.HandleUpdate)
+	groups.PUT("/:id/parent", api.authz.Require(model.PermissionManage), api.HandleMove)
+	groups.DELETE("/:id", api.authz.Require(model.PermissionOwn), api.HandleDelete)
+	groups.GET("/:id/members", api.authz.Require(model.PermissionView), api.HandleListMembers)
+	groups.POST("/:id/members", api.authz.Require(model.PermissionManage), api.HandleAddMember)
+	groups.PUT("/:id/members/:userId", api.authz.Require(model.PermissionManage), api.HandleUpdateMember)
+	groups.DELETE("/:id/members/:userId", api.authz.Require(model.PermissionManage), api.HandleRemoveMember)
+}
+
+// HandleCreate
+// @Summary Create a project group
+// @Description Create a project group, optionally nested under a parent group
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param group body service.CreateProjectGroupRequest true "Project group details"
+// @Success 201 {object} response.SuccessResponse[model.ProjectGroup] "Project group created"
+// @Failure 400 {object} response.ErrorResponse[string] "Invalid request"
+// @Failure 404 {object} response.ErrorResponse[string] "Parent group not found"
+// @Failure 409 {object} response.ErrorResponse[string] "Slug already in use"
+// @Router /groups [post]
+func (api *projectGroupApi) HandleCreate(c *gin.Context) {
+	var req service.CreateProjectGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendBadRequest(c, response.ErrInvalidRequest, err.Error())
+		return
+	}
+
+	group, err := api.groupService.Create(c.Request.Context(), currentUserID(c), &req)
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendCreated(c, "Project group created", group)
+}
+
+// HandleList
+// @Summary List project groups
+// @Description List the project groups the caller belongs to, including their sub-groups
+// @Tags groups
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.SuccessResponse[[]model.ProjectGroup] "Project groups"
+// @Failure 401 {object} response.ErrorResponse[string] "Unauthorized"
+// @Router /groups [get]
+func (api *projectGroupApi) HandleList(c *gin.Context) {
+	groups, err := api.groupService.List(c.Request.Context(), currentUserID(c))
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Project groups", groups)
+}
+
+// HandleTree
+// @Summary Project group tree
+// @Description List the project groups the caller belongs to as a nested tree
+// @Tags groups
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.SuccessResponse[[]service.ProjectGroupNode] "Project group tree"
+// @Failure 401 {object} response.ErrorResponse[string] "Unauthorized"
+// @Router /groups/tree [get]
+func (api *projectGroupApi) HandleTree(c *gin.Context) {
+	tree, err := api.groupService.Tree(c.Request.Context(), currentUserID(c))
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Project group tree", tree)
+}
+
+// HandleGet
+// @Summary Get a project group
+// @Tags groups
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project group ID"
+// @Success 200 {object} response.SuccessResponse[model.ProjectGroup] "Project group"
+// @Failure 404 {object} response.ErrorResponse[string] "Project group not found"
+// @Router /groups/{id} [get]
+func (api *projectGroupApi) HandleGet(c *gin.Context) {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
+
+	group, err := api.groupService.Get(c.Request.Context(), id)
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Project group", group)
+}
+
+// HandleUpdate
+// @Summary Update a project group
+// @Description Rename a project group or change its slug or description
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project group ID"
+// @Param group body service.UpdateProjectGroupRequest true "Fields to update"
+// @Success 200 {object} response.SuccessResponse[model.ProjectGroup] "Project group updated"
+// @Failure 400 {object} response.ErrorResponse[string] "Invalid request"
+// @Failure 404 {object} response.ErrorResponse[string] "Project group not found"
+// @Failure 409 {object} response.ErrorResponse[string] "Slug already in use"
+// @Router /groups/{id} [patch]
+func (api *projectGroupApi) HandleUpdate(c *gin.Context) {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req service.UpdateProjectGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendBadRequest(c, response.ErrInvalidRequest, err.Error())
+		return
+	}
+
+	group, err := api.groupService.Update(c.Request.Context(), id, &req)
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Project group updated", group)
+}
+
+// HandleMove
+// @Summary Move a project group
+// @Description Re-parent a project group; a null parent moves it to the top level
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project group ID"
+// @Param parent body service.MoveProjectGroupRequest true "New parent group"
+// @Success 200 {object} response.SuccessResponse[model.ProjectGroup] "Project group moved"
+// @Failure 400 {object} response.ErrorResponse[string] "Move would create a cycle"
+// @Failure 404 {object} response.ErrorResponse[string] "Project group not found"
+// @Router /groups/{id}/parent [put]
+func (api *projectGroupApi) HandleMove(c *gin.Context) {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req service.MoveProjectGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendBadRequest(c, response.ErrInvalidRequest, err.Error())
+		return
+	}
+
+	group, err := api.groupService.Move(c.Request.Context(), currentUserID(c), id, &req)
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Project group moved", group)
+}
+
+// HandleDelete
+// @Summary Delete a project group
+// @Description Delete a project group together with its sub-groups and projects
+// @Tags groups
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project group ID"
+// @Success 200 {object} response.SuccessResponse[string] "Project group deleted"
+// @Failure 404 {object} response.ErrorResponse[string] "Project group not found"
+// @Router /groups/{id} [delete]
+func (api *projectGroupApi) HandleDelete(c *gin.Context) {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := api.groupService.Delete(c.Request.Context(), id); err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Project group deleted", nil)
+}
+
+// HandleListMembers
+// @Summary List project group members
+// @Description List the direct members of a project group; they also have their role in every sub-group and project
+// @Tags groups
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project group ID"
+// @Success 200 {object} response.SuccessResponse[[]model.ProjectGroupMember] "Project group members"
+// @Failure 404 {object} response.ErrorResponse[string] "Project group not found"
+// @Router /groups/{id}/members [get]
+func (api *projectGroupApi) HandleListMembers(c *gin.Context) {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
+
+	members, err := api.groupService.ListMembers(c.Request.Context(), id)
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Project group members", members)
+}
+
+// HandleAddMember
+// @Summary Add a project group member
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project group ID"
+// @Param member body service.AddGroupMemberRequest true "User to add"
+// @Success 201 {object} response.SuccessResponse[string] "Project group member added"
+// @Failure 404 {object} response.ErrorResponse[string] "Project group or user not found"
+// @Failure 409 {object} response.ErrorResponse[string] "User is already a member"
+// @Router /groups/{id}/members [post]
+func (api *projectGroupApi) HandleAddMember(c *gin.Context) {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req service.AddGroupMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendBadRequest(c, response.ErrInvalidRequest, err.Error())
+		return
+	}
+
+	if err := api.groupService.AddMember(c.Request.Context(), id, &req); err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendCreated(c, "Project group member added", nil)
+}
+
+// HandleUpdateMember
+// @Summary Change the role of a project group member
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project group ID"
+// @Param userId path string true "User ID"
+// @Param member body service.UpdateMemberRequest true "New role"
+// @Success 200 {object} response.SuccessResponse[string] "Project group member updated"
+// @Failure 404 {object} response.ErrorResponse[string] "Project group or member not found"
+// @Router /groups/{id}/members/{userId} [put]
+func (api *projectGroupApi) HandleUpdateMember(c *gin.Context) {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
+	userID, ok := uuidParam(c, "userId")
+	if !ok {
+		return
+	}
+
+	var req service.UpdateMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendBadRequest(c, response.ErrInvalidRequest, err.Error())
+		return
+	}
+
+	if err := api.groupService.UpdateMember(c.Request.Context(), id, userID, &req); err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Project group member updated", nil)
+}
+
+// HandleRemoveMember
+// @Summary Remove a project group member
+// @Tags groups
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project group ID"
+// @Param userId path string true "User ID"
+// @Success 200 {object} response.SuccessResponse[string] "Project group member removed"
+// @Failure 404 {object} response.ErrorResponse[string] "Project group or member not found"
+// @Router /groups/{id}/members/{userId} [delete]
+func (api *projectGroupApi) HandleRemoveMember(c *gin.Context) {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
+	userID, ok := uuidParam(c, "userId")
+	if !ok {
+		return
+	}
+
+	if err := api.groupService.RemoveMember(c.Request.Context(), id, userID); err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Project group member removed", nil)
+}
@@ -36,6 +36,16 @@ func SendNotFound(c *gin.Context, message string, details any) {
 	SendError(c, http.StatusNotFound, message, details)
 }
 
+// SendConflict sends a 409 Conflict response
+func SendConflict(c *gin.Context, message string, details any) {
+	SendError(c, http.StatusConflict, message, details)
+}
+
+// SendTooManyRequests sends a 429 Too Many Requests response
+func SendTooManyRequests(c *gin.Context, message string, details any) {
+	SendError(c, http.StatusTooManyRequests, message, details)
+}
+
 // SendInternalServerError sends a 500 Internal Server Error response
 func SendInternalServerError(c *gin.Context, message string, details any) {
 	SendError(c, http.StatusInternalServerError, message, details)
@@ -45,4 +45,5 @@ const (
 	ErrTokenExpired       = "Token expired"
 	ErrTokenRevoked       = "Token has been revoked"
 	ErrInvalidCredentials = "Invalid credentials"
+	ErrTooManyRequests    = "Too many requests"
 )
@@ -0,0 +1,196 @@
+package v1
+
+import (
+	"errors"
+	"flagon/pkg/api/v1/response"
+	"flagon/pkg/model"
+	"flagon/pkg/service"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SdkAPI interface {
+	Register(router gin.IRouter)
+}
+
+// streamHeartbeat is how often an idle event stream sends a comment, keeping proxies from closing it
+// and letting clients detect dead connections. The token of the stream is checked again as often.
+const streamHeartbeat = 15 * time.Second
+
+type sdkApi struct {
+	sdkService    service.SdkService
+	streamService service.StreamService
+	heartbeat     time.Duration
+}
+
+func NewSdkAPI(sdkService service.SdkService, streamService service.StreamService) SdkAPI {
+	return &sdkApi{
+		sdkService:    sdkService,
+		streamService: streamService,
+		heartbeat:     streamHeartbeat,
+	}
+}
+
+func (api *sdkApi) Register(router gin.IRouter) {
+	sdk := router.Group("/sdk", api.TokenRequired())
+	sdk.POST("/evaluate", api.HandleEvaluate)
+	sdk.GET("/config", api.HandleConfig)
+	sdk.GET("/stream", api.HandleStream)
+}
+
+// HandleEvaluate
+// @Summary Evaluate flags
+// @Description Evaluate every flag of the access token's environment for a context
+// @Tags sdk
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body service.EvaluateRequest true "Evaluation context"
+// @Success 200 {object} response.SuccessResponse[[]evaluation.Result] "Flags evaluated"
+// @Failure 401 {object} response.ErrorResponse[string] "Invalid access token"
+// @Router /sdk/evaluate [post]
+func (api *sdkApi) HandleEvaluate(c *gin.Context) {
+	var req service.EvaluateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendBadRequest(c, response.ErrInvalidRequest, err.Error())
+		return
+	}
+
+	results, err := api.sdkService.Evaluate(c.Request.Context(), currentAccessToken(c), &req)
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Flags evaluated", results)
+}
+
+// HandleConfig
+// @Summary Get flag configuration
+// @Description Get the flag configuration of the access token's environment, for SDKs evaluating flags locally
+// @Tags sdk
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.SuccessResponse[evaluation.Snapshot] "Flag configuration"
+// @Failure 401 {object} response.ErrorResponse[string] "Invalid access token"
+// @Router /sdk/config [get]
+func (api *sdkApi) HandleConfig(c *gin.Context) {
+	snapshot, err := api.sdkService.Config(c.Request.Context(), currentAccessToken(c))
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Flag configuration", snapshot)
+}
+
+// HandleStream
+// @Summary Stream flag changes
+// @Description Stream the flag configuration of the access token's environment as Server-Sent Events.
+// @Description The stream starts with a "snapshot" event holding the whole configuration, followed by a
+// @Description "patch" event for every change. A client reconnecting with the Last-Event-ID header
+// @Description receives the events it missed instead, or a new snapshot when they are no longer known.
+// @Description The stream is closed once the access token is revoked or no longer valid.
+// @Tags sdk
+// @Produce text/event-stream
+// @Security BearerAuth
+// @Param Last-Event-ID header string false "ID of the last event received"
+// @Success 200 {string} string "Event stream"
+// @Failure 401 {object} response.ErrorResponse[string] "Invalid access token"
+// @Router /sdk/stream [get]
+func (api *sdkApi) HandleStream(c *gin.Context) {
+	events, err := api.streamService.Subscribe(c.Request.Context(), currentAccessToken(c), c.GetHeader("Last-Event-ID"))
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	secret, _ := bearerToken(c)
+	heartbeat := time.NewTicker(api.heartbeat)
+	defer heartbeat.Stop()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			_, err := fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, event.Data)
+			return err == nil
+		case <-heartbeat.C:
+			// Streams last for days, so a revoked token, or one whose user lost access, must not
+			// keep receiving changes until the client reconnects.
+			if !api.stillAuthenticated(c, secret) {
+				return false
+			}
+			_, err := io.WriteString(w, ": heartbeat\n\n")
+			return err == nil
+		}
+	})
+}
+
+// TokenRequired authenticates SDK requests with an environment-scoped access token sent as a bearer token.
+func (api *sdkApi) TokenRequired() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		secret, found := bearerToken(c)
+		if !found {
+			response.SendUnauthorized(c, response.ErrUnauthorized, "Access token is required")
+			c.Abort()
+			return
+		}
+
+		token, err := api.sdkService.Authenticate(c.Request.Context(), secret)
+		if errors.Is(err, service.ErrInvalidToken) {
+			response.SendUnauthorized(c, response.ErrInvalidToken, "Invalid access token")
+			c.Abort()
+			return
+		}
+		if err != nil {
+			_ = c.Error(err)
+			response.SendInternalServerError(c, response.ErrInternalServer, "Failed to validate access token")
+			c.Abort()
+			return
+		}
+
+		c.Set("accessToken", token)
+		c.Next()
+	}
+}
+
+// stillAuthenticated checks the secret of a stream again. Errors other than an invalid token, like
+// the database being unavailable for a moment, keep the stream open.
+func (api *sdkApi) stillAuthenticated(c *gin.Context, secret string) bool {
+	_, err := api.sdkService.Authenticate(c.Request.Context(), secret)
+	if errors.Is(err, service.ErrInvalidToken) {
+		return false
+	}
+	if err != nil {
+		slog.WarnContext(c.Request.Context(), "Failed to check the access token of an event stream", "error", err)
+	}
+	return true
+}
+
+// bearerToken returns the bearer token of the Authorization header.
+func bearerToken(c *gin.Context) (string, bool) {
+	scheme, secret, found := strings.Cut(c.GetHeader("Authorization"), " ")
+	return secret, found && scheme == "Bearer" && secret != ""
+}
+
+// currentAccessToken returns the access token set by TokenRequired.
+func currentAccessToken(c *gin.Context) *model.AccessToken {
+	token, _ := c.Get("accessToken")
+	accessToken, _ := token.(*model.AccessToken)
+	return accessToken
+}
@@ -0,0 +1,132 @@
+package v1
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flagon/pkg/model"
+	"flagon/pkg/service"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// fakeSdkService accepts the secret "sdk" until err is set, which it then returns.
+type fakeSdkService struct {
+	service.SdkService
+	mu  sync.Mutex
+	err error
+}
+
+func (s *fakeSdkService) Authenticate(_ context.Context, secret string) (*model.AccessToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if secret != "sdk" {
+		return nil, service.ErrInvalidToken
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+	envID := uuid.New()
+	return &model.AccessToken{ID: uuid.New(), TokenType: model.AccessTokenTypeSDK, EnvironmentID: &envID}, nil
+}
+
+func (s *fakeSdkService) fail(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+// fakeStreamService sends a snapshot, then nothing until the subscription ends.
+type fakeStreamService struct {
+	service.StreamService
+}
+
+func (fakeStreamService) Subscribe(ctx context.Context, _ *model.AccessToken, _ string) (<-chan *model.FlagEvent, error) {
+	events := make(chan *model.FlagEvent, 1)
+	events <- &model.FlagEvent{ID: "1-0", Type: model.FlagEventTypeSnapshot, Data: []byte("{}")}
+	go func() {
+		<-ctx.Done()
+		close(events)
+	}()
+	return events, nil
+}
+
+func TestStreamChecksTokenOnHeartbeat(t *testing.T) {
+	tests := []struct {
+		name string
+		// err is returned by Authenticate once the stream sent its first heartbeat.
+		err        error
+		wantClosed bool
+	}{
+		{name: "token revoked", err: service.ErrInvalidToken, wantClosed: true},
+		{name: "database unavailable", err: errors.New("connection refused")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sdk := &fakeSdkService{}
+			api := &sdkApi{sdkService: sdk, streamService: fakeStreamService{}, heartbeat: 20 * time.Millisecond}
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			api.Register(router)
+			server := httptest.NewServer(router)
+			defer server.Close()
+
+			req, err := http.NewRequest(http.MethodGet, server.URL+"/sdk/stream", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Authorization", "Bearer sdk")
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("status %d, want 200", resp.StatusCode)
+			}
+
+			// Count the heartbeats until the stream closes, or up to 5.
+			lines := bufio.NewScanner(resp.Body)
+			heartbeats := 0
+			for heartbeats < 5 && lines.Scan() {
+				if strings.HasPrefix(lines.Text(), ": heartbeat") {
+					heartbeats++
+					if heartbeats == 1 {
+						sdk.fail(tt.err)
+					}
+				}
+			}
+
+			if tt.wantClosed && heartbeats != 1 {
+				t.Errorf("%d heartbeats, want the stream closed after the first", heartbeats)
+			}
+			if !tt.wantClosed && heartbeats != 5 {
+				t.Errorf("stream closed after %d heartbeats, want it kept open", heartbeats)
+			}
+		})
+	}
+}
+
+func TestStreamRequiresToken(t *testing.T) {
+	api := &sdkApi{sdkService: &fakeSdkService{}, streamService: fakeStreamService{}, heartbeat: time.Hour}
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	api.Register(router)
+
+	for _, authorization := range []string{"", "Bearer", "Bearer ", "Basic sdk", "Bearer revoked"} {
+		req := httptest.NewRequest(http.MethodGet, "/sdk/stream", nil)
+		req.Header.Set("Authorization", authorization)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		if recorder.Code != http.StatusUnauthorized {
+			t.Errorf("Authorization %q: status %d, want 401", authorization, recorder.Code)
+		}
+	}
+}
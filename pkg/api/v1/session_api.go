@@ -0,0 +1,84 @@
+package v1
+
+import (
+	"flagon/pkg/api/v1/response"
+	"flagon/pkg/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SessionAPI interface {
+	Register(router gin.IRouter)
+}
+
+type sessionApi struct {
+	sessionService service.SessionService
+}
+
+func NewSessionAPI(sessionService service.SessionService) SessionAPI {
+	return &sessionApi{
+		sessionService: sessionService,
+	}
+}
+
+func (api *sessionApi) Register(router gin.IRouter) {
+	sessions := router.Group("/sessions")
+	sessions.GET("", api.HandleList)
+	sessions.DELETE("", api.HandleRevokeAll)
+	sessions.DELETE("/:sessionId", api.HandleRevoke)
+}
+
+// HandleList
+// @Summary List sessions
+// @Description List the active sessions of the current user, most recently used first. The session of the request is flagged as current.
+// @Tags sessions
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.SuccessResponse[[]model.Session] "Sessions"
+// @Router /sessions [get]
+func (api *sessionApi) HandleList(c *gin.Context) {
+	currentID, _ := currentSessionID(c)
+	sessions, err := api.sessionService.List(c.Request.Context(), currentUserID(c), currentID)
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Sessions", sessions)
+}
+
+// HandleRevokeAll
+// @Summary Log out everywhere
+// @Description Revoke every session of the current user, including the current one
+// @Tags sessions
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.SuccessResponse[string] "Sessions revoked"
+// @Router /sessions [delete]
+func (api *sessionApi) HandleRevokeAll(c *gin.Context) {
+	if err := api.sessionService.RevokeAll(c.Request.Context(), currentUserID(c)); err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Sessions revoked", nil)
+}
+
+// HandleRevoke
+// @Summary Revoke a session
+// @Description Revoke a session of the current user, logging it out
+// @Tags sessions
+// @Produce json
+// @Security BearerAuth
+// @Param sessionId path string true "Session ID"
+// @Success 200 {object} response.SuccessResponse[string] "Session revoked"
+// @Failure 404 {object} response.ErrorResponse[string] "Session not found"
+// @Router /sessions/{sessionId} [delete]
+func (api *sessionApi) HandleRevoke(c *gin.Context) {
+	if err := api.sessionService.Revoke(c.Request.Context(), currentUserID(c), c.Param("sessionId")); err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Session revoked", nil)
+}
@@ -0,0 +1,291 @@
+package v1
+
+import (
+	"flagon/pkg/api/v1/response"
+	"flagon/pkg/model"
+	"flagon/pkg/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TargetGroupAPI interface {
+	Register(router gin.IRouter)
+}
+
+type targetGroupApi struct {
+	targetGroupService service.TargetGroupService
+	authz              Authorizer
+}
+
+func NewTargetGroupAPI(targetGroupService service.TargetGroupService, authz Authorizer) TargetGroupAPI {
+	return &targetGroupApi{
+		targetGroupService: targetGroupService,
+		authz:              authz,
+	}
+}
+
+func (api *targetGroupApi) Register(router gin.IRouter) {
+	view := api.authz.Require(model.PermissionView)
+	edit := api.authz.Require(model.PermissionEdit)
+	toggle := api.authz.Require(model.PermissionToggle)
+
+	groups := router.Group("/projects/:slug/target-groups")
+	groups.GET("", view, api.HandleList)
+	groups.POST("", edit, api.HandleCreate)
+	groups.PUT("/order", edit, api.HandleReorder)
+	groups.GET("/:targetGroupId", view, api.HandleGet)
+	groups.PATCH("/:targetGroupId", edit, api.HandleUpdate)
+	groups.DELETE("/:targetGroupId", edit, api.HandleDelete)
+	groups.GET("/:targetGroupId/environments", view, api.HandleListEnvironments)
+	groups.PUT("/:targetGroupId/environments/:envId", toggle, api.HandleAddEnvironment)
+	groups.DELETE("/:targetGroupId/environments/:envId", toggle, api.HandleRemoveEnvironment)
+}
+
+// HandleList
+// @Summary List target groups
+// @Description List the target groups of a project by precedence, highest first
+// @Tags target-groups
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "Project slug"
+// @Success 200 {object} response.SuccessResponse[[]model.TargetGroup] "Target groups"
+// @Failure 404 {object} response.ErrorResponse[string] "Project not found"
+// @Router /projects/{slug}/target-groups [get]
+func (api *targetGroupApi) HandleList(c *gin.Context) {
+	groups, err := api.targetGroupService.List(c.Request.Context(), c.Param("slug"))
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Target groups", groups)
+}
+
+// HandleCreate
+// @Summary Create a target group
+// @Description Create an audience defined by targeting rules, e.g. {"and": [{"attribute": "country", "operator": "in", "values": ["VN", "SG"]}, {"attribute": "appVersion", "operator": "semver_gte", "values": ["2.3.0"]}]}
+// @Tags target-groups
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "Project slug"
+// @Param group body service.CreateTargetGroupRequest true "Target group details"
+// @Success 201 {object} response.SuccessResponse[model.TargetGroup] "Target group created"
+// @Failure 400 {object} response.ErrorResponse[string] "Invalid rules"
+// @Failure 404 {object} response.ErrorResponse[string] "Project not found"
+// @Failure 409 {object} response.ErrorResponse[string] "Target group name already in use"
+// @Router /projects/{slug}/target-groups [post]
+func (api *targetGroupApi) HandleCreate(c *gin.Context) {
+	var req service.CreateTargetGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendBadRequest(c, response.ErrInvalidRequest, err.Error())
+		return
+	}
+
+	group, err := api.targetGroupService.Create(c.Request.Context(), c.Param("slug"), &req)
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendCreated(c, "Target group created", group)
+}
+
+// HandleReorder
+// @Summary Reorder target groups
+// @Description Set the precedence of all target groups of a project. When a context is in several groups with a state for the same flag, the first group listed wins.
+// @Tags target-groups
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "Project slug"
+// @Param order body service.ReorderTargetGroupsRequest true "Target group IDs, highest precedence first"
+// @Success 200 {object} response.SuccessResponse[[]model.TargetGroup] "Target groups reordered"
+// @Failure 400 {object} response.ErrorResponse[string] "The IDs do not match the project's target groups"
+// @Failure 404 {object} response.ErrorResponse[string] "Project not found"
+// @Router /projects/{slug}/target-groups/order [put]
+func (api *targetGroupApi) HandleReorder(c *gin.Context) {
+	var req service.ReorderTargetGroupsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendBadRequest(c, response.ErrInvalidRequest, err.Error())
+		return
+	}
+
+	groups, err := api.targetGroupService.Reorder(c.Request.Context(), c.Param("slug"), &req)
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Target groups reordered", groups)
+}
+
+// HandleGet
+// @Summary Get a target group
+// @Tags target-groups
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "Project slug"
+// @Param targetGroupId path string true "Target group ID"
+// @Success 200 {object} response.SuccessResponse[model.TargetGroup] "Target group"
+// @Failure 404 {object} response.ErrorResponse[string] "Target group not found"
+// @Router /projects/{slug}/target-groups/{targetGroupId} [get]
+func (api *targetGroupApi) HandleGet(c *gin.Context) {
+	id, ok := uuidParam(c, "targetGroupId")
+	if !ok {
+		return
+	}
+
+	group, err := api.targetGroupService.Get(c.Request.Context(), c.Param("slug"), id)
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Target group", group)
+}
+
+// HandleUpdate
+// @Summary Update a target group
+// @Tags target-groups
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "Project slug"
+// @Param targetGroupId path string true "Target group ID"
+// @Param group body service.UpdateTargetGroupRequest true "Fields to update"
+// @Success 200 {object} response.SuccessResponse[model.TargetGroup] "Target group updated"
+// @Failure 400 {object} response.ErrorResponse[string] "Invalid rules"
+// @Failure 404 {object} response.ErrorResponse[string] "Target group not found"
+// @Failure 409 {object} response.ErrorResponse[string] "Target group name already in use"
+// @Router /projects/{slug}/target-groups/{targetGroupId} [patch]
+func (api *targetGroupApi) HandleUpdate(c *gin.Context) {
+	id, ok := uuidParam(c, "targetGroupId")
+	if !ok {
+		return
+	}
+
+	var req service.UpdateTargetGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendBadRequest(c, response.ErrInvalidRequest, err.Error())
+		return
+	}
+
+	group, err := api.targetGroupService.Update(c.Request.Context(), c.Param("slug"), id, &req)
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Target group updated", group)
+}
+
+// HandleDelete
+// @Summary Delete a target group
+// @Description Delete a target group together with its flag states
+// @Tags target-groups
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "Project slug"
+// @Param targetGroupId path string true "Target group ID"
+// @Success 200 {object} response.SuccessResponse[string] "Target group deleted"
+// @Failure 404 {object} response.ErrorResponse[string] "Target group not found"
+// @Router /projects/{slug}/target-groups/{targetGroupId} [delete]
+func (api *targetGroupApi) HandleDelete(c *gin.Context) {
+	id, ok := uuidParam(c, "targetGroupId")
+	if !ok {
+		return
+	}
+
+	if err := api.targetGroupService.Delete(c.Request.Context(), c.Param("slug"), id); err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Target group deleted", nil)
+}
+
+// HandleListEnvironments
+// @Summary List the environments of a target group
+// @Description List the environments the target group is enabled in
+// @Tags target-groups
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "Project slug"
+// @Param targetGroupId path string true "Target group ID"
+// @Success 200 {object} response.SuccessResponse[[]model.ProjectEnvironment] "Environments"
+// @Failure 404 {object} response.ErrorResponse[string] "Target group not found"
+// @Router /projects/{slug}/target-groups/{targetGroupId}/environments [get]
+func (api *targetGroupApi) HandleListEnvironments(c *gin.Context) {
+	id, ok := uuidParam(c, "targetGroupId")
+	if !ok {
+		return
+	}
+
+	envs, err := api.targetGroupService.ListEnvironments(c.Request.Context(), c.Param("slug"), id)
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Environments", envs)
+}
+
+// HandleAddEnvironment
+// @Summary Enable a target group in an environment
+// @Tags target-groups
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "Project slug"
+// @Param targetGroupId path string true "Target group ID"
+// @Param envId path string true "Environment ID"
+// @Success 200 {object} response.SuccessResponse[string] "Target group enabled"
+// @Failure 404 {object} response.ErrorResponse[string] "Target group or environment not found"
+// @Router /projects/{slug}/target-groups/{targetGroupId}/environments/{envId} [put]
+func (api *targetGroupApi) HandleAddEnvironment(c *gin.Context) {
+	id, ok := uuidParam(c, "targetGroupId")
+	if !ok {
+		return
+	}
+	envID, ok := uuidParam(c, "envId")
+	if !ok {
+		return
+	}
+
+	if err := api.targetGroupService.AddEnvironment(c.Request.Context(), c.Param("slug"), id, envID); err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Target group enabled", nil)
+}
+
+// HandleRemoveEnvironment
+// @Summary Disable a target group in an environment
+// @Description Stop evaluating the target group in the environment; its flag states there are kept
+// @Tags target-groups
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "Project slug"
+// @Param targetGroupId path string true "Target group ID"
+// @Param envId path string true "Environment ID"
+// @Success 200 {object} response.SuccessResponse[string] "Target group disabled"
+// @Failure 404 {object} response.ErrorResponse[string] "Target group or environment not found"
+// @Router /projects/{slug}/target-groups/{targetGroupId}/environments/{envId} [delete]
+func (api *targetGroupApi) HandleRemoveEnvironment(c *gin.Context) {
+	id, ok := uuidParam(c, "targetGroupId")
+	if !ok {
+		return
+	}
+	envID, ok := uuidParam(c, "envId")
+	if !ok {
+		return
+	}
+
+	if err := api.targetGroupService.RemoveEnvironment(c.Request.Context(), c.Param("slug"), id, envID); err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Target group disabled", nil)
+}
@@ -0,0 +1,222 @@
+package v1
+
+import (
+	"flagon/pkg/api/v1/response"
+	"flagon/pkg/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UserAPI interface {
+	Register(router gin.IRouter)
+}
+
+type userApi struct {
+	userService service.UserService
+}
+
+func NewUserAPI(userService service.UserService) UserAPI {
+	return &userApi{
+		userService: userService,
+	}
+}
+
+func (api *userApi) Register(router gin.IRouter) {
+	users := router.Group("/admin/users", api.AdminRequired())
+	users.GET("", api.HandleList)
+	users.GET("/:id", api.HandleGet)
+	users.PATCH("/:id", api.HandleUpdate)
+	users.POST("/:id/password-reset", api.HandleForcePasswordReset)
+	users.DELETE("/:id/sessions", api.HandleRevokeSessions)
+	users.DELETE("/:id", api.HandleDelete)
+}
+
+// AdminRequired lets instance admins through. It must run after AuthRequired, which already refuses
+// access tokens scoped to a project group or project on these routes.
+func (api *userApi) AdminRequired() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		isAdmin, err := api.userService.IsAdmin(c.Request.Context(), currentUserID(c))
+		if err != nil {
+			sendServiceError(c, err)
+			c.Abort()
+			return
+		}
+		if !isAdmin {
+			response.SendForbidden(c, response.ErrForbidden, "instance admin required")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// HandleList
+// @Summary List users
+// @Description List the users of the instance by username. Requires an instance admin.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param search query string false "Part of the username, email address, first or last name"
+// @Param limit query int false "Maximum number of users, 50 by default and at most 500"
+// @Param offset query int false "Number of users to skip"
+// @Success 200 {object} response.SuccessResponse[service.UserList] "Users"
+// @Failure 400 {object} response.ErrorResponse[string] "Invalid request"
+// @Failure 403 {object} response.ErrorResponse[string] "Instance admin required"
+// @Router /admin/users [get]
+func (api *userApi) HandleList(c *gin.Context) {
+	var req service.ListUsersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.SendBadRequest(c, response.ErrInvalidRequest, err.Error())
+		return
+	}
+
+	users, err := api.userService.List(c.Request.Context(), &req)
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Users", users)
+}
+
+// HandleGet
+// @Summary Get a user
+// @Description Get a user of the instance. Requires an instance admin.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} response.SuccessResponse[model.User] "User"
+// @Failure 403 {object} response.ErrorResponse[string] "Instance admin required"
+// @Failure 404 {object} response.ErrorResponse[string] "User not found"
+// @Router /admin/users/{id} [get]
+func (api *userApi) HandleGet(c *gin.Context) {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
+
+	user, err := api.userService.Get(c.Request.Context(), id)
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "User", user)
+}
+
+// HandleUpdate
+// @Summary Update a user
+// @Description Grant or revoke instance admin rights, or disable or enable a user. Disabled users cannot log in, are logged out everywhere, and their access tokens stop working except SDK tokens. Admins cannot disable or demote themselves. Requires an instance admin.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param user body service.UpdateUserRequest true "Fields to update"
+// @Success 200 {object} response.SuccessResponse[model.User] "User updated"
+// @Failure 400 {object} response.ErrorResponse[string] "Invalid request"
+// @Failure 403 {object} response.ErrorResponse[string] "Instance admin required"
+// @Failure 404 {object} response.ErrorResponse[string] "User not found"
+// @Router /admin/users/{id} [patch]
+func (api *userApi) HandleUpdate(c *gin.Context) {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
+	var req service.UpdateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendBadRequest(c, response.ErrInvalidRequest, err.Error())
+		return
+	}
+
+	user, err := api.userService.Update(c.Request.Context(), currentUserID(c), id, &req)
+	if err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "User updated", user)
+}
+
+// HandleForcePasswordReset
+// @Summary Force a password reset
+// @Description Clear the password of a user and log them out everywhere, then email them a link to choose a new password. Requires an instance admin.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} response.SuccessResponse[string] "Password reset"
+// @Failure 403 {object} response.ErrorResponse[string] "Instance admin required"
+// @Failure 404 {object} response.ErrorResponse[string] "User not found"
+// @Router /admin/users/{id}/password-reset [post]
+func (api *userApi) HandleForcePasswordReset(c *gin.Context) {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := api.userService.ForcePasswordReset(c.Request.Context(), id); err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Password reset", nil)
+}
+
+// HandleRevokeSessions
+// @Summary Revoke the sessions of a user
+// @Description Log a user out everywhere, for instance when they leave the company. Their access tokens are not revoked. Requires an instance admin.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} response.SuccessResponse[string] "Sessions revoked"
+// @Failure 403 {object} response.ErrorResponse[string] "Instance admin required"
+// @Failure 404 {object} response.ErrorResponse[string] "User not found"
+// @Router /admin/users/{id}/sessions [delete]
+func (api *userApi) HandleRevokeSessions(c *gin.Context) {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := api.userService.RevokeSessions(c.Request.Context(), id); err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "Sessions revoked", nil)
+}
+
+// HandleDelete
+// @Summary Delete a user
+// @Description Delete a user with their memberships and access tokens. The project groups and projects they own go to the new owner, who is required when there are any and becomes an admin member of the projects. Admins cannot delete themselves. Requires an instance admin.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param new_owner_id query string false "ID of the user receiving the project groups and projects of the deleted user"
+// @Success 200 {object} response.SuccessResponse[string] "User deleted"
+// @Failure 400 {object} response.ErrorResponse[string] "Invalid request"
+// @Failure 403 {object} response.ErrorResponse[string] "Instance admin required"
+// @Failure 404 {object} response.ErrorResponse[string] "User not found"
+// @Router /admin/users/{id} [delete]
+func (api *userApi) HandleDelete(c *gin.Context) {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
+	var req service.DeleteUserRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.SendBadRequest(c, response.ErrInvalidRequest, err.Error())
+		return
+	}
+
+	if err := api.userService.Delete(c.Request.Context(), currentUserID(c), id, &req); err != nil {
+		sendServiceError(c, err)
+		return
+	}
+
+	response.SendOK(c, "User deleted", nil)
+}
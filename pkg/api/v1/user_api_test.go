@@ -0,0 +1,92 @@
+package v1
+
+import (
+	"context"
+	"flagon/pkg/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// fakeUserService knows which users are instance admins, and records whose sessions were revoked.
+type fakeUserService struct {
+	service.UserService
+	admins  map[uuid.UUID]bool
+	revoked []uuid.UUID
+}
+
+func (s *fakeUserService) IsAdmin(_ context.Context, userID uuid.UUID) (bool, error) {
+	return s.admins[userID], nil
+}
+
+func (s *fakeUserService) RevokeSessions(_ context.Context, id uuid.UUID) error {
+	s.revoked = append(s.revoked, id)
+	return nil
+}
+
+// newSessionRouter serves the session routes of the user and session APIs to the caller, as
+// AuthRequired would.
+func newSessionRouter(users service.UserService, caller uuid.UUID) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	protected := router.Group("/api/v1", func(c *gin.Context) {
+		c.Set("userID", caller)
+	})
+	NewUserAPI(users).Register(protected)
+	NewSessionAPI(nil).Register(protected)
+	return router
+}
+
+func TestRevokeSessionsRequiresAdmin(t *testing.T) {
+	groupOwner, outsider, admin := uuid.New(), uuid.New(), uuid.New()
+	groupID := uuid.New()
+
+	tests := []struct {
+		name       string
+		caller     uuid.UUID
+		path       string
+		wantStatus int
+		wantRevoke bool
+	}{
+		{
+			name:       "group owner through the admin route",
+			caller:     groupOwner,
+			path:       "/api/v1/admin/users/" + outsider.String() + "/sessions",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			// Any user can create a group and add others to it, so groups grant no such right.
+			name:       "group owner through the group route",
+			caller:     groupOwner,
+			path:       "/api/v1/groups/" + groupID.String() + "/members/" + outsider.String() + "/sessions",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "instance admin",
+			caller:     admin,
+			path:       "/api/v1/admin/users/" + outsider.String() + "/sessions",
+			wantStatus: http.StatusOK,
+			wantRevoke: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &fakeUserService{admins: map[uuid.UUID]bool{admin: true}}
+			router := newSessionRouter(users, tt.caller)
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodDelete, tt.path, nil))
+
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", recorder.Code, tt.wantStatus, recorder.Body)
+			}
+			revoked := len(users.revoked) == 1 && users.revoked[0] == outsider
+			if revoked != tt.wantRevoke || len(users.revoked) > 1 {
+				t.Errorf("revoked sessions of %v, want revoked = %t", users.revoked, tt.wantRevoke)
+			}
+		})
+	}
+}
@@ -8,25 +8,83 @@ type API interface {
 	Register(router gin.IRouter)
 }
 
-func New(authAPI AuthAPI) API {
+func New(
+	authAPI AuthAPI,
+	projectGroupAPI ProjectGroupAPI,
+	projectAPI ProjectAPI,
+	environmentAPI EnvironmentAPI,
+	featureAPI FeatureAPI,
+	targetGroupAPI TargetGroupAPI,
+	sdkAPI SdkAPI,
+	accessTokenAPI AccessTokenAPI,
+	auditAPI AuditAPI,
+	jwksAPI JWKSAPI,
+	sessionAPI SessionAPI,
+	mFAAPI MFAAPI,
+	accountAPI AccountAPI,
+	userAPI UserAPI,
+	profileAPI ProfileAPI,
+) API {
 	return &api{
-		Auth: authAPI,
+		Auth:         authAPI,
+		ProjectGroup: projectGroupAPI,
+		Project:      projectAPI,
+		Environment:  environmentAPI,
+		Feature:      featureAPI,
+		TargetGroup:  targetGroupAPI,
+		Sdk:          sdkAPI,
+		AccessToken:  accessTokenAPI,
+		Audit:        auditAPI,
+		JWKS:         jwksAPI,
+		Session:      sessionAPI,
+		MFA:          mFAAPI,
+		Account:      accountAPI,
+		User:         userAPI,
+		Profile:      profileAPI,
 	}
 
 }
 
 type api struct {
-	Auth AuthAPI
+	Auth         AuthAPI
+	ProjectGroup ProjectGroupAPI
+	Project      ProjectAPI
+	Environment  EnvironmentAPI
+	Feature      FeatureAPI
+	TargetGroup  TargetGroupAPI
+	Sdk          SdkAPI
+	AccessToken  AccessTokenAPI
+	Audit        AuditAPI
+	JWKS         JWKSAPI
+	Session      SessionAPI
+	MFA          MFAAPI
+	Account      AccountAPI
+	User         UserAPI
+	Profile      ProfileAPI
 }
 
 func (a *api) Register(r gin.IRouter) {
+	a.JWKS.Register(r)
 	v1 := r.Group("/api/v1")
 	{
 		// Auth routes
 		a.Auth.Register(v1)
+		a.Account.Register(v1)
+		// SDK routes authenticate with access tokens instead of user sessions
+		a.Sdk.Register(v1)
 		protected := v1.Group("/", a.Auth.AuthRequired())
 		{
-			_ = protected
+			a.ProjectGroup.Register(protected)
+			a.Project.Register(protected)
+			a.Environment.Register(protected)
+			a.Feature.Register(protected)
+			a.TargetGroup.Register(protected)
+			a.AccessToken.Register(protected)
+			a.Audit.Register(protected)
+			a.Session.Register(protected)
+			a.MFA.Register(protected)
+			a.User.Register(protected)
+			a.Profile.Register(protected)
 		}
 	}
 }
@@ -5,4 +5,19 @@ import "github.com/google/wire"
 var WireSet = wire.NewSet(
 	New,
 	NewAuthAPI,
+	NewProjectGroupAPI,
+	NewProjectAPI,
+	NewEnvironmentAPI,
+	NewFeatureAPI,
+	NewTargetGroupAPI,
+	NewSdkAPI,
+	NewAccessTokenAPI,
+	NewAuthorizer,
+	NewAuditAPI,
+	NewJWKSAPI,
+	NewSessionAPI,
+	NewMFAAPI,
+	NewAccountAPI,
+	NewUserAPI,
+	NewProfileAPI,
 )
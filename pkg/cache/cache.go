@@ -0,0 +1,83 @@
+// Package cache stores the short-lived state of Flagon, such as sessions, login challenges and rate
+// limit counters. The driver set in config.Cache keeps it in Redis, in the memory of the process, or
+// in a table of the database.
+package cache
+
+import (
+	"context"
+	"errors"
+	"flagon/pkg/config"
+	"flagon/pkg/database"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	// sweepInterval is how often the memory and database drivers drop expired keys.
+	sweepInterval = time.Minute
+	// maxUpdateAttempts bounds how often Update retries when other writers keep changing the key.
+	maxUpdateAttempts = 10
+)
+
+var (
+	// ErrMiss is returned for keys that do not exist or have expired.
+	ErrMiss = errors.New("cache: key not found")
+	// ErrConflict is returned by Update when the key kept changing while being updated.
+	ErrConflict = errors.New("cache: key changed concurrently")
+)
+
+// Cache is a key-value store whose keys can expire. Keys without an expiration live until deleted.
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Set stores the value under the key. A zero expiration keeps it until it is deleted.
+	Set(ctx context.Context, key string, value []byte, expiration time.Duration) error
+	// Take returns and deletes the value of the key, so that a single caller gets it.
+	Take(ctx context.Context, key string) ([]byte, error)
+	// Delete removes the keys, returning how many of them existed.
+	Delete(ctx context.Context, keys ...string) (int64, error)
+	// Increment adds one to the counter of the key, returning the count and how long the counter
+	// lives. A new counter starts at one and expires after the expiration.
+	Increment(ctx context.Context, key string, expiration time.Duration) (int64, time.Duration, error)
+	// TTL returns how long the key lives, or 0 when it has no expiration.
+	TTL(ctx context.Context, key string) (time.Duration, error)
+	// Update replaces the value of the key with the one fn returns, running fn again when the key
+	// changes meanwhile. fn must not use the cache.
+	Update(ctx context.Context, key string, fn UpdateFunc) error
+}
+
+// UpdateFunc returns the new value of a key and its expiration, given the current ones. The value is
+// nil when the key does not exist. Returning a nil value deletes the key, and returning an error
+// leaves it unchanged.
+type UpdateFunc func(value []byte, ttl time.Duration) ([]byte, time.Duration, error)
+
+// New returns the cache of the configured driver.
+func New(db *database.DB) (Cache, error) {
+	cfg := config.GetConfig().Cache
+	switch cfg.Driver {
+	case "redis":
+		return NewRedisCache(cfg)
+	case "memory":
+		return NewMemoryCache(), nil
+	case "database":
+		return NewDatabaseCache(db), nil
+	}
+	return nil, fmt.Errorf("unsupported cache driver %q", cfg.Driver)
+}
+
+// increment is the UpdateFunc of counters, which are stored as decimal text like in Redis. It
+// records the count and how long the counter lives.
+func increment(count *int64, lives *time.Duration, expiration time.Duration) UpdateFunc {
+	return func(value []byte, ttl time.Duration) ([]byte, time.Duration, error) {
+		if value == nil {
+			*count, *lives = 1, expiration
+			return []byte("1"), expiration, nil
+		}
+		current, err := strconv.ParseInt(string(value), 10, 64)
+		if err != nil {
+			return nil, 0, fmt.Errorf("cache: value is not a counter: %w", err)
+		}
+		*count, *lives = current+1, ttl
+		return strconv.AppendInt(nil, *count, 10), ttl, nil
+	}
+}
@@ -0,0 +1,335 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"flagon/pkg/config"
+	"flagon/pkg/database"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// cacheDriver opens caches of one driver for the contract tests.
+type cacheDriver struct {
+	name string
+	// open returns two caches over the same storage when the driver shares it between instances,
+	// or two independent caches otherwise.
+	open   func(t *testing.T) (Cache, Cache)
+	shared bool
+}
+
+// cacheDrivers returns every driver. Redis is tested when FLAGON_TEST_REDIS_ADDR points to a
+// server whose database may be written to.
+func cacheDrivers() []cacheDriver {
+	return []cacheDriver{
+		{
+			name: "memory",
+			open: func(*testing.T) (Cache, Cache) {
+				return NewMemoryCache(), NewMemoryCache()
+			},
+		},
+		{
+			name: "database",
+			open: func(t *testing.T) (Cache, Cache) {
+				db := openSQLite(t)
+				return NewDatabaseCache(db), NewDatabaseCache(db)
+			},
+			shared: true,
+		},
+		{
+			name: "redis",
+			open: func(t *testing.T) (Cache, Cache) {
+				addr := os.Getenv("FLAGON_TEST_REDIS_ADDR")
+				if addr == "" {
+					t.Skip("FLAGON_TEST_REDIS_ADDR is not set")
+				}
+				first, err := NewRedisCache(config.Cache{Addr: addr})
+				if err != nil {
+					t.Skipf("redis is not available: %v", err)
+				}
+				second, err := NewRedisCache(config.Cache{Addr: addr})
+				if err != nil {
+					t.Fatal(err)
+				}
+				t.Cleanup(func() {
+					_ = first.Client.Close()
+					_ = second.Client.Close()
+				})
+				return first, second
+			},
+			shared: true,
+		},
+	}
+}
+
+// openSQLite returns a database with the cache_entries table, in a file like the sqlite driver of
+// Flagon uses.
+func openSQLite(t *testing.T) *database.DB {
+	t.Helper()
+	dsn := fmt.Sprintf("file:%s?_fk=true", filepath.Join(t.TempDir(), "flagon.db"))
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{TranslateError: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema, err := os.ReadFile("../migrations/sqlite/000011_create_cache_entries.up.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Exec(string(schema)).Error; err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	return &database.DB{DB: db}
+}
+
+// testKey returns a key of the test, so that tests sharing a Redis database do not see each other's keys.
+func testKey(t *testing.T, name string) string {
+	return fmt.Sprintf("test:%s:%d:%s", t.Name(), time.Now().UnixNano(), name)
+}
+
+func TestCacheContract(t *testing.T) {
+	for _, driver := range cacheDrivers() {
+		t.Run(driver.name, func(t *testing.T) {
+			t.Run("set and get", func(t *testing.T) {
+				c, _ := driver.open(t)
+				testSetAndGet(t, c)
+			})
+			t.Run("concurrent take", func(t *testing.T) {
+				c, other := driver.open(t)
+				testConcurrentTake(t, c, other)
+			})
+			t.Run("increment", func(t *testing.T) {
+				c, _ := driver.open(t)
+				testIncrement(t, c)
+			})
+			t.Run("expired", func(t *testing.T) {
+				c, _ := driver.open(t)
+				testExpired(t, c)
+			})
+			t.Run("update conflict", func(t *testing.T) {
+				c, other := driver.open(t)
+				testUpdateConflict(t, c, other, driver.shared)
+			})
+		})
+	}
+}
+
+func testSetAndGet(t *testing.T, c Cache) {
+	ctx := context.Background()
+	key := testKey(t, "key")
+
+	if _, err := c.Get(ctx, key); !errors.Is(err, ErrMiss) {
+		t.Fatalf("Get of a missing key: %v, want ErrMiss", err)
+	}
+	if err := c.Set(ctx, key, []byte("value"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := c.Get(ctx, key); err != nil || string(value) != "value" {
+		t.Errorf("Get: %q, %v, want value", value, err)
+	}
+	if ttl, err := c.TTL(ctx, key); err != nil || ttl != 0 {
+		t.Errorf("TTL without expiration: %v, %v, want 0", ttl, err)
+	}
+	if deleted, err := c.Delete(ctx, key, testKey(t, "missing")); err != nil || deleted != 1 {
+		t.Errorf("Delete: %d, %v, want 1", deleted, err)
+	}
+	if _, err := c.Get(ctx, key); !errors.Is(err, ErrMiss) {
+		t.Errorf("Get of a deleted key: %v, want ErrMiss", err)
+	}
+}
+
+func testConcurrentTake(t *testing.T, c, other Cache) {
+	ctx := context.Background()
+	key := testKey(t, "challenge")
+	if err := c.Set(ctx, key, []byte("secret"), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	const takers = 20
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	winners := 0
+	for i := range takers {
+		// Half of the takers use the other instance, which shares the storage of drivers that do.
+		taker := c
+		if i%2 == 1 {
+			taker = other
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := taker.Take(ctx, key)
+			if errors.Is(err, ErrMiss) {
+				return
+			}
+			if err != nil {
+				t.Errorf("Take: %v", err)
+				return
+			}
+			if string(value) != "secret" {
+				t.Errorf("Take: %q, want secret", value)
+			}
+			mu.Lock()
+			winners++
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if winners != 1 {
+		t.Errorf("%d takers got the value, want exactly 1", winners)
+	}
+	if _, err := c.Get(ctx, key); !errors.Is(err, ErrMiss) {
+		t.Errorf("Get after Take: %v, want ErrMiss", err)
+	}
+}
+
+func testIncrement(t *testing.T, c Cache) {
+	ctx := context.Background()
+	key := testKey(t, "counter")
+
+	count, ttl, err := c.Increment(ctx, key, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 || ttl <= 59*time.Second || ttl > time.Minute {
+		t.Errorf("first Increment: %d living %v, want 1 living a minute", count, ttl)
+	}
+
+	// Later increments keep the expiration of the counter rather than extending it.
+	count, next, err := c.Increment(ctx, key, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 || next <= 0 || next > ttl {
+		t.Errorf("second Increment: %d living %v, want 2 living at most %v", count, next, ttl)
+	}
+	if got, err := c.TTL(ctx, key); err != nil || got <= 0 || got > time.Minute {
+		t.Errorf("TTL: %v, %v, want at most a minute", got, err)
+	}
+
+	forever := testKey(t, "forever")
+	for want := int64(1); want <= 2; want++ {
+		count, ttl, err := c.Increment(ctx, forever, 0)
+		if err != nil || count != want || ttl != 0 {
+			t.Errorf("Increment without expiration: %d living %v, %v, want %d living forever", count, ttl, err, want)
+		}
+	}
+
+	text := testKey(t, "text")
+	if err := c.Set(ctx, text, []byte("not a number"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := c.Increment(ctx, text, time.Minute); err == nil {
+		t.Error("Increment of a value that is not a counter succeeded")
+	}
+}
+
+// testExpired checks that keys which expired are missing before the sweep, which runs every minute,
+// deletes them.
+func testExpired(t *testing.T, c Cache) {
+	ctx := context.Background()
+	key := testKey(t, "expired")
+	counter := testKey(t, "expired-counter")
+	if err := c.Set(ctx, key, []byte("value"), 50*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := c.Increment(ctx, counter, 50*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	if _, err := c.Get(ctx, key); !errors.Is(err, ErrMiss) {
+		t.Errorf("Get: %v, want ErrMiss", err)
+	}
+	if _, err := c.TTL(ctx, key); !errors.Is(err, ErrMiss) {
+		t.Errorf("TTL: %v, want ErrMiss", err)
+	}
+	if _, err := c.Take(ctx, key); !errors.Is(err, ErrMiss) {
+		t.Errorf("Take: %v, want ErrMiss", err)
+	}
+	if deleted, err := c.Delete(ctx, key); err != nil || deleted != 0 {
+		t.Errorf("Delete: %d, %v, want 0", deleted, err)
+	}
+	if count, ttl, err := c.Increment(ctx, counter, time.Minute); err != nil || count != 1 || ttl <= 59*time.Second {
+		t.Errorf("Increment: %d living %v, %v, want a new counter living a minute", count, ttl, err)
+	}
+
+	if err := c.Set(ctx, key, []byte("value"), 50*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	err := c.Update(ctx, key, func(value []byte, ttl time.Duration) ([]byte, time.Duration, error) {
+		if value != nil || ttl != 0 {
+			t.Errorf("Update got %q living %v, want a missing key", value, ttl)
+		}
+		return []byte("new"), 0, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, err := c.Get(ctx, key); err != nil || string(value) != "new" {
+		t.Errorf("Get after Update: %q, %v, want new", value, err)
+	}
+}
+
+// testUpdateConflict writes the key from the other cache while Update runs. Drivers sharing their
+// storage give up with ErrConflict once every attempt saw a change.
+func testUpdateConflict(t *testing.T, c, other Cache, shared bool) {
+	ctx := context.Background()
+	key := testKey(t, "contended")
+	if err := c.Set(ctx, key, []byte("0"), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	// An error of fn leaves the key unchanged without retrying.
+	errStop := errors.New("stop")
+	attempts := 0
+	err := c.Update(ctx, key, func([]byte, time.Duration) ([]byte, time.Duration, error) {
+		attempts++
+		return []byte("changed"), 0, errStop
+	})
+	if !errors.Is(err, errStop) || attempts != 1 {
+		t.Errorf("Update: %v after %d attempts, want the error of fn after 1", err, attempts)
+	}
+	if value, err := c.Get(ctx, key); err != nil || string(value) != "0" {
+		t.Errorf("Get after a failed Update: %q, %v, want 0", value, err)
+	}
+
+	attempts = 0
+	err = c.Update(ctx, key, func(value []byte, ttl time.Duration) ([]byte, time.Duration, error) {
+		attempts++
+		if err := other.Set(ctx, key, fmt.Appendf(nil, "other %d", attempts), time.Minute); err != nil {
+			t.Fatal(err)
+		}
+		return []byte("mine"), ttl, nil
+	})
+
+	if !shared {
+		if err != nil || attempts != 1 {
+			t.Errorf("Update: %v after %d attempts, want success after 1", err, attempts)
+		}
+		return
+	}
+	if !errors.Is(err, ErrConflict) {
+		t.Fatalf("Update: %v, want ErrConflict", err)
+	}
+	if attempts != maxUpdateAttempts {
+		t.Errorf("%d attempts, want %d", attempts, maxUpdateAttempts)
+	}
+	want := fmt.Sprintf("other %d", maxUpdateAttempts)
+	if value, err := c.Get(ctx, key); err != nil || string(value) != want {
+		t.Errorf("Get: %q, %v, want the last write of the other cache %q", value, err, want)
+	}
+}
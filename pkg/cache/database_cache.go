@@ -0,0 +1,199 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"flagon/pkg/database"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// databaseEntry is a row of the cache_entries table. Version increases with each write, so that
+// concurrent updates of a key can tell they conflict.
+type databaseEntry struct {
+	Key   string `gorm:"primaryKey"`
+	Value []byte
+	// ExpiresAt is in Unix milliseconds, or nil for entries without an expiration.
+	ExpiresAt *int64
+	Version   int64
+}
+
+func (databaseEntry) TableName() string {
+	return "cache_entries"
+}
+
+func (e *databaseEntry) live(now time.Time) bool {
+	return e.ExpiresAt == nil || now.UnixMilli() < *e.ExpiresAt
+}
+
+func (e *databaseEntry) ttl(now time.Time) time.Duration {
+	if e.ExpiresAt == nil {
+		return 0
+	}
+	return time.Duration(*e.ExpiresAt-now.UnixMilli()) * time.Millisecond
+}
+
+// DatabaseCache keeps the cache in the cache_entries table, so that it survives restarts without a
+// Redis server.
+type DatabaseCache struct {
+	db *database.DB
+}
+
+// NewDatabaseCache returns the cache of the database, deleting its expired entries every minute for
+// as long as the process runs.
+func NewDatabaseCache(db *database.DB) *DatabaseCache {
+	c := &DatabaseCache{db: db}
+	go c.sweep()
+	return c
+}
+
+// live scopes queries to the entries that have not expired.
+func live(now time.Time) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("expires_at IS NULL OR expires_at > ?", now.UnixMilli())
+	}
+}
+
+// find returns the entry of the key, or nil. Misses are common, so they do not go through
+// gorm.ErrRecordNotFound, which the database logger reports as an error.
+func (c *DatabaseCache) find(db *gorm.DB, key string) (*databaseEntry, error) {
+	var entry databaseEntry
+	result := db.Where("key = ?", key).Limit(1).Find(&entry)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, result.Error
+	}
+	return &entry, nil
+}
+
+func (c *DatabaseCache) Get(ctx context.Context, key string) ([]byte, error) {
+	entry, err := c.find(c.db.WithContext(ctx).Scopes(live(time.Now())), key)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, ErrMiss
+	}
+	return entry.Value, nil
+}
+
+func (c *DatabaseCache) Set(ctx context.Context, key string, value []byte, expiration time.Duration) error {
+	return c.Update(ctx, key, func([]byte, time.Duration) ([]byte, time.Duration, error) {
+		return value, expiration, nil
+	})
+}
+
+func (c *DatabaseCache) Take(ctx context.Context, key string) ([]byte, error) {
+	var taken []byte
+	err := c.Update(ctx, key, func(value []byte, _ time.Duration) ([]byte, time.Duration, error) {
+		if value == nil {
+			return nil, 0, ErrMiss
+		}
+		taken = value
+		return nil, 0, nil
+	})
+	return taken, err
+}
+
+func (c *DatabaseCache) Delete(ctx context.Context, keys ...string) (int64, error) {
+	if len(keys) == 0 {
+		return 0, nil
+	}
+	now := time.Now()
+	var deleted int64
+	err := c.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&databaseEntry{}).Scopes(live(now)).Where("key IN ?", keys).Count(&deleted).Error; err != nil {
+			return err
+		}
+		return tx.Where("key IN ?", keys).Delete(&databaseEntry{}).Error
+	})
+	return deleted, err
+}
+
+func (c *DatabaseCache) Increment(ctx context.Context, key string, expiration time.Duration) (int64, time.Duration, error) {
+	var count int64
+	var ttl time.Duration
+	err := c.Update(ctx, key, increment(&count, &ttl, expiration))
+	return count, ttl, err
+}
+
+func (c *DatabaseCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	now := time.Now()
+	entry, err := c.find(c.db.WithContext(ctx).Scopes(live(now)), key)
+	if err != nil {
+		return 0, err
+	}
+	if entry == nil {
+		return 0, ErrMiss
+	}
+	return entry.ttl(now), nil
+}
+
+// Update writes the new value only if the version of the entry is still the one read, and tries
+// again otherwise. Expired entries that were not swept yet count as missing.
+func (c *DatabaseCache) Update(ctx context.Context, key string, fn UpdateFunc) error {
+	db := c.db.WithContext(ctx)
+	for range maxUpdateAttempts {
+		now := time.Now()
+		entry, err := c.find(db, key)
+		if err != nil {
+			return err
+		}
+		exists := entry != nil
+
+		var value []byte
+		var ttl time.Duration
+		if exists && entry.live(now) {
+			value, ttl = entry.Value, entry.ttl(now)
+		}
+		value, expiration, err := fn(value, ttl)
+		if err != nil {
+			return err
+		}
+
+		var result *gorm.DB
+		switch {
+		case value == nil && !exists:
+			return nil
+		case value == nil:
+			result = db.Where("key = ? AND version = ?", key, entry.Version).Delete(&databaseEntry{})
+		case !exists:
+			result = db.Create(&databaseEntry{Key: key, Value: value, ExpiresAt: expiresAt(now, expiration), Version: 1})
+			if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+				// Another writer created the key first.
+				continue
+			}
+		default:
+			result = db.Model(&databaseEntry{}).Where("key = ? AND version = ?", key, entry.Version).Updates(map[string]any{
+				"value":      value,
+				"expires_at": expiresAt(now, expiration),
+				"version":    entry.Version + 1,
+			})
+		}
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			return nil
+		}
+	}
+	return ErrConflict
+}
+
+func expiresAt(now time.Time, expiration time.Duration) *int64 {
+	if expiration <= 0 {
+		return nil
+	}
+	milliseconds := now.Add(expiration).UnixMilli()
+	return &milliseconds
+}
+
+func (c *DatabaseCache) sweep() {
+	for now := range time.Tick(sweepInterval) {
+		err := c.db.Where("expires_at <= ?", now.UnixMilli()).Delete(&databaseEntry{}).Error
+		if err != nil {
+			slog.Warn("Failed to delete expired cache entries", "error", err)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryCache keeps the cache in the memory of the process. It is lost on restart and not shared
+// between instances, which suits a single Flagon instance.
+type MemoryCache struct {
+	mu      sync.Mutex
+	entries map[string]*memoryEntry
+}
+
+type memoryEntry struct {
+	value []byte
+	// expiresAt is zero for entries without an expiration.
+	expiresAt time.Time
+}
+
+func (e *memoryEntry) live(now time.Time) bool {
+	return e.expiresAt.IsZero() || now.Before(e.expiresAt)
+}
+
+func (e *memoryEntry) ttl(now time.Time) time.Duration {
+	if e.expiresAt.IsZero() {
+		return 0
+	}
+	return e.expiresAt.Sub(now)
+}
+
+// NewMemoryCache returns an empty cache, dropping its expired entries every minute for as long as
+// the process runs.
+func NewMemoryCache() *MemoryCache {
+	c := &MemoryCache{entries: make(map[string]*memoryEntry)}
+	go c.sweep()
+	return c
+}
+
+// entry returns the live entry of the key, or nil. The caller holds the lock.
+func (c *MemoryCache) entry(key string, now time.Time) *memoryEntry {
+	e, ok := c.entries[key]
+	if !ok || !e.live(now) {
+		return nil
+	}
+	return e
+}
+
+// put stores the entry of the key, or deletes it when the value is nil. The caller holds the lock.
+func (c *MemoryCache) put(key string, value []byte, expiration time.Duration, now time.Time) {
+	if value == nil {
+		delete(c.entries, key)
+		return
+	}
+	e := &memoryEntry{value: value}
+	if expiration > 0 {
+		e.expiresAt = now.Add(expiration)
+	}
+	c.entries[key] = e
+}
+
+func (c *MemoryCache) Get(_ context.Context, key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e := c.entry(key, time.Now())
+	if e == nil {
+		return nil, ErrMiss
+	}
+	return e.value, nil
+}
+
+func (c *MemoryCache) Set(_ context.Context, key string, value []byte, expiration time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// Copied, so callers can reuse the slice.
+	c.put(key, append([]byte{}, value...), expiration, time.Now())
+	return nil
+}
+
+func (c *MemoryCache) Take(_ context.Context, key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e := c.entry(key, time.Now())
+	if e == nil {
+		return nil, ErrMiss
+	}
+	delete(c.entries, key)
+	return e.value, nil
+}
+
+func (c *MemoryCache) Delete(_ context.Context, keys ...string) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	var deleted int64
+	for _, key := range keys {
+		if c.entry(key, now) != nil {
+			deleted++
+		}
+		delete(c.entries, key)
+	}
+	return deleted, nil
+}
+
+func (c *MemoryCache) Increment(ctx context.Context, key string, expiration time.Duration) (int64, time.Duration, error) {
+	var count int64
+	var ttl time.Duration
+	err := c.Update(ctx, key, increment(&count, &ttl, expiration))
+	return count, ttl, err
+}
+
+func (c *MemoryCache) TTL(_ context.Context, key string) (time.Duration, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	e := c.entry(key, now)
+	if e == nil {
+		return 0, ErrMiss
+	}
+	return e.ttl(now), nil
+}
+
+// Update holds the lock while fn runs, so fn sees no concurrent change.
+func (c *MemoryCache) Update(_ context.Context, key string, fn UpdateFunc) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	var value []byte
+	var ttl time.Duration
+	if e := c.entry(key, now); e != nil {
+		value, ttl = e.value, e.ttl(now)
+	}
+	value, expiration, err := fn(value, ttl)
+	if err != nil {
+		return err
+	}
+	c.put(key, value, expiration, now)
+	return nil
+}
+
+func (c *MemoryCache) sweep() {
+	for now := range time.Tick(sweepInterval) {
+		c.mu.Lock()
+		for key, e := range c.entries {
+			if !e.live(now) {
+				delete(c.entries, key)
+			}
+		}
+		c.mu.Unlock()
+	}
+}
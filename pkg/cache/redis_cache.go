@@ -2,23 +2,129 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"flagon/pkg/config"
+	"time"
+
 	"github.com/redis/go-redis/v9"
 )
 
+// incrementScript counts in a counter, returning the count and the milliseconds it lives, or -1
+// when it has no expiration.
+var incrementScript = redis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+if count == 1 and tonumber(ARGV[1]) > 0 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return {count, redis.call("PTTL", KEYS[1])}
+`)
+
+// RedisCache shares the cache between every Flagon instance using the Redis server.
 type RedisCache struct {
-	*redis.Client
+	Client *redis.Client
 }
 
-func New() (*RedisCache, error) {
-	cfg := config.GetConfig()
+func NewRedisCache(cfg config.Cache) (*RedisCache, error) {
 	client := redis.NewClient(&redis.Options{
-		Addr:     cfg.Cache.Addr,
-		Password: cfg.Cache.Password,
-		DB:       cfg.Cache.DB,
+		Addr:     cfg.Addr,
+		Password: cfg.Password,
+		DB:       cfg.DB,
 	})
 	if err := client.Ping(context.Background()).Err(); err != nil {
 		return nil, err
 	}
 	return &RedisCache{Client: client}, nil
 }
+
+func (c *RedisCache) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := c.Client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrMiss
+	}
+	return value, err
+}
+
+func (c *RedisCache) Set(ctx context.Context, key string, value []byte, expiration time.Duration) error {
+	return c.Client.Set(ctx, key, value, expiration).Err()
+}
+
+func (c *RedisCache) Take(ctx context.Context, key string) ([]byte, error) {
+	value, err := c.Client.GetDel(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrMiss
+	}
+	return value, err
+}
+
+func (c *RedisCache) Delete(ctx context.Context, keys ...string) (int64, error) {
+	if len(keys) == 0 {
+		return 0, nil
+	}
+	return c.Client.Del(ctx, keys...).Result()
+}
+
+func (c *RedisCache) Increment(ctx context.Context, key string, expiration time.Duration) (int64, time.Duration, error) {
+	result, err := incrementScript.Run(ctx, c.Client, []string{key}, expiration.Milliseconds()).Int64Slice()
+	if err != nil {
+		return 0, 0, err
+	}
+	return result[0], redisTTL(result[1]), nil
+}
+
+func (c *RedisCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := c.Client.PTTL(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	// go-redis returns the -2 of missing keys as is, without converting it to milliseconds.
+	if ttl == -2 {
+		return 0, ErrMiss
+	}
+	return max(ttl, 0), nil
+}
+
+// Update watches the key, so the transaction writing the new value fails if the key changed
+// after it was read.
+func (c *RedisCache) Update(ctx context.Context, key string, fn UpdateFunc) error {
+	for range maxUpdateAttempts {
+		err := c.Client.Watch(ctx, func(tx *redis.Tx) error {
+			value, err := tx.Get(ctx, key).Bytes()
+			if errors.Is(err, redis.Nil) {
+				value = nil
+			} else if err != nil {
+				return err
+			}
+			var ttl time.Duration
+			if value != nil {
+				pttl, err := tx.PTTL(ctx, key).Result()
+				if err != nil {
+					return err
+				}
+				ttl = max(pttl, 0)
+			}
+
+			value, expiration, err := fn(value, ttl)
+			if err != nil {
+				return err
+			}
+			_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				if value == nil {
+					pipe.Del(ctx, key)
+				} else {
+					pipe.Set(ctx, key, value, expiration)
+				}
+				return nil
+			})
+			return err
+		}, key)
+		if !errors.Is(err, redis.TxFailedErr) {
+			return err
+		}
+	}
+	return ErrConflict
+}
+
+// redisTTL converts a PTTL in milliseconds, which is negative for keys without an expiration.
+func redisTTL(milliseconds int64) time.Duration {
+	return max(time.Duration(milliseconds)*time.Millisecond, 0)
+}
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"github.com/spf13/viper"
 	"strings"
 	"time"
@@ -9,14 +11,57 @@ import (
 var config Config
 
 type Config struct {
-	Log      Log
-	Server   Server
-	Database Database
-	Auth     Authentication
-	Cache    Cache
+	Log       Log
+	Server    Server
+	Database  Database
+	Auth      Authentication
+	Cache     Cache
+	Mail      Mail
+	RateLimit RateLimit
 }
 
 func (conf Config) Validate() error {
+	if conf.Auth.SigningAlgorithm != "RS256" && conf.Auth.SigningAlgorithm != "EdDSA" {
+		return errors.New("auth.signingAlgorithm must be RS256 or EdDSA")
+	}
+	if conf.Auth.KeyRotationInterval <= 0 {
+		return errors.New("auth.keyRotationInterval must be positive")
+	}
+	if key := conf.Auth.SigningKeyEncryptionKey; key != "" && len(key) < 32 {
+		return errors.New("auth.signingKeyEncryptionKey must be at least 32 characters")
+	}
+	if conf.Auth.AccessTokenLifetime <= 0 || conf.Auth.RefreshTokenLifetime <= 0 {
+		return errors.New("auth.accessTokenLifetime and auth.refreshTokenLifetime must be positive")
+	}
+	if oidc := conf.Auth.OIDC; oidc.Enabled && (oidc.Issuer == "" || oidc.ClientID == "" || oidc.RedirectURL == "") {
+		return errors.New("auth.oidc requires issuer, clientID and redirectURL when enabled")
+	}
+	if lockout := conf.Auth.Lockout; lockout.Threshold > 0 && (lockout.Duration <= 0 || lockout.MaxDuration < lockout.Duration) {
+		return errors.New("auth.lockout requires a positive duration and a maxDuration no shorter than it")
+	}
+	for _, rule := range conf.RateLimit.Rules {
+		if err := rule.Validate(); err != nil {
+			return err
+		}
+	}
+	switch conf.Cache.Driver {
+	case "redis", "memory", "database":
+	default:
+		return errors.New("cache.driver must be redis, memory or database")
+	}
+	switch conf.Mail.Driver {
+	case "smtp":
+		if conf.Mail.SMTP.Host == "" {
+			return errors.New("mail.smtp.host is required by the smtp mail driver")
+		}
+	case "file":
+		if conf.Mail.Dir == "" {
+			return errors.New("mail.dir is required by the file mail driver")
+		}
+	case "log":
+	default:
+		return errors.New("mail.driver must be smtp, file or log")
+	}
 	return nil
 }
 
@@ -59,6 +104,8 @@ func init() {
 	viper.SetDefault("http.certFile", "")
 	viper.SetDefault("http.keyFile", "")
 
+	viper.SetDefault("server.trustedProxies", []string{})
+
 	viper.SetDefault("database.driver", "sqlite")
 	viper.SetDefault("database.host", "")
 	viper.SetDefault("database.port", "")
@@ -71,10 +118,56 @@ func init() {
 	viper.SetDefault("database.maxOpenConns", 0)
 	viper.SetDefault("database.maxIdleConns", 0)
 
-	viper.SetDefault("auth.secret", "top-secret")
-	viper.SetDefault("auth.accessTokenLifetime", 0)
-	viper.SetDefault("auth.refreshTokenLifetime", 0)
+	viper.SetDefault("auth.signingAlgorithm", "RS256")
+	viper.SetDefault("auth.keyRotationInterval", 30*24*time.Hour)
+	viper.SetDefault("auth.signingKeyEncryptionKey", "")
+	viper.SetDefault("auth.accessTokenLifetime", 15*time.Minute)
+	viper.SetDefault("auth.refreshTokenLifetime", 7*24*time.Hour)
+	viper.SetDefault("auth.oidc.enabled", false)
+	viper.SetDefault("auth.oidc.provider", "oidc")
+	viper.SetDefault("auth.oidc.issuer", "")
+	viper.SetDefault("auth.oidc.clientID", "")
+	viper.SetDefault("auth.oidc.clientSecret", "")
+	viper.SetDefault("auth.oidc.redirectURL", "")
+	viper.SetDefault("auth.oidc.scopes", []string{"openid", "email", "profile"})
+	viper.SetDefault("auth.oidc.allowSignup", true)
+	viper.SetDefault("auth.mfa.issuer", "Flagon")
+	viper.SetDefault("auth.lockout.threshold", 5)
+	viper.SetDefault("auth.lockout.duration", time.Minute)
+	viper.SetDefault("auth.lockout.maxDuration", time.Hour)
+	viper.SetDefault("auth.registration.enabled", true)
+	viper.SetDefault("auth.registration.allowedDomains", []string{})
+
+	viper.SetDefault("mail.driver", "log")
+	viper.SetDefault("mail.from", "Flagon <noreply@localhost>")
+	viper.SetDefault("mail.baseURL", "http://localhost:8080")
+	viper.SetDefault("mail.dir", "")
+	viper.SetDefault("mail.smtp.host", "")
+	viper.SetDefault("mail.smtp.port", 587)
+	viper.SetDefault("mail.smtp.username", "")
+	viper.SetDefault("mail.smtp.password", "")
+	viper.SetDefault("mail.smtp.tls", false)
+
+	viper.SetDefault("rateLimit.enabled", true)
+	viper.SetDefault("rateLimit.rules", []map[string]any{
+		{"route": "POST /api/v1/login", "key": RateLimitKeyIP, "limit": 20, "window": time.Minute},
+		{"route": "POST /api/v1/login", "key": RateLimitKeyUsername, "limit": 10, "window": time.Minute},
+		{"route": "POST /api/v1/login/mfa", "key": RateLimitKeyIP, "limit": 20, "window": time.Minute},
+		{"route": "POST /api/v1/register", "key": RateLimitKeyIP, "limit": 10, "window": time.Hour},
+		{"route": "POST /api/v1/refresh-token", "key": RateLimitKeyIP, "limit": 60, "window": time.Minute},
+		{"route": "POST /api/v1/password/forgot", "key": RateLimitKeyIP, "limit": 5, "window": 15 * time.Minute},
+		{"route": "POST /api/v1/password/reset", "key": RateLimitKeyIP, "limit": 10, "window": 15 * time.Minute},
+		{"route": "PUT /api/v1/me/password", "key": RateLimitKeyIP, "limit": 10, "window": 15 * time.Minute},
+		// Changing the email address checks the password and the code of the authenticator.
+		{"route": "PATCH /api/v1/me", "key": RateLimitKeyIP, "limit": 10, "window": 15 * time.Minute},
+		{"route": "POST /api/v1/sdk/evaluate", "key": RateLimitKeyToken, "limit": 600, "window": time.Minute},
+		{"route": "GET /api/v1/sdk/config", "key": RateLimitKeyToken, "limit": 60, "window": time.Minute},
+		// Stream connections are long-lived, so these limit how often SDKs reconnect, and connection floods.
+		{"route": "GET /api/v1/sdk/stream", "key": RateLimitKeyToken, "limit": 60, "window": time.Minute},
+		{"route": "GET /api/v1/sdk/stream", "key": RateLimitKeyIP, "limit": 120, "window": time.Minute},
+	})
 
+	viper.SetDefault("cache.driver", "redis")
 	viper.SetDefault("cache.addr", "localhost:6379")
 	viper.SetDefault("cache.db", 0)
 	viper.SetDefault("cache.password", "")
@@ -92,6 +185,10 @@ type Server struct {
 	EnableTLS bool
 	CertFile  string
 	KeyFile   string
+
+	// TrustedProxies are the addresses or CIDR ranges of the reverse proxies whose X-Forwarded-For
+	// header gives the IP address of clients. Other clients could fake it to evade rate limits.
+	TrustedProxies []string
 }
 
 type Database struct {
@@ -109,12 +206,127 @@ type Database struct {
 }
 
 type Authentication struct {
-	Secret               string
-	AccessTokenLifetime  time.Duration
-	RefreshTokenLifetime time.Duration
+	// SigningAlgorithm signs new JWTs, RS256 or EdDSA.
+	SigningAlgorithm string
+	// KeyRotationInterval is how long a JWT signing key is used before a new one is created.
+	KeyRotationInterval time.Duration
+	// SigningKeyEncryptionKey encrypts the private JWT signing keys stored in the database. Without
+	// it they are stored in plain text, and anyone reading the database can issue tokens for any
+	// user. Keep it out of the database and its backups, such as in the
+	// FLAGON_AUTH_SIGNINGKEYENCRYPTIONKEY environment variable.
+	SigningKeyEncryptionKey string
+	AccessTokenLifetime     time.Duration
+	RefreshTokenLifetime    time.Duration
+	OIDC                    OIDC
+	MFA                     MFA
+	Lockout                 Lockout
+	Registration            Registration
+}
+
+// Registration configures who can create an account through /register. Single sign-on signups are
+// configured by OIDC.AllowSignup instead.
+type Registration struct {
+	Enabled bool
+	// AllowedDomains restricts registration to email addresses at these domains, unless empty.
+	AllowedDomains []string
+}
+
+// Lockout locks accounts out of password logins after failures in a row.
+type Lockout struct {
+	// Threshold is how many failures lock the account, or 0 to never lock it.
+	Threshold int
+	// Duration is how long the first lockout lasts. Each further failure doubles it, up to MaxDuration.
+	Duration    time.Duration
+	MaxDuration time.Duration
+}
+
+// MFA configures two-factor authentication.
+type MFA struct {
+	// Issuer names Flagon in authenticator apps.
+	Issuer string
+}
+
+// OIDC configures single sign-on with an OpenID Connect provider.
+type OIDC struct {
+	Enabled bool
+	// Provider is the name the accounts of the provider are linked under.
+	Provider     string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is the callback route of Flagon, such as https://flagon.example.com/api/v1/auth/oidc/callback.
+	RedirectURL string
+	Scopes      []string
+	// AllowSignup creates an account on the first login of a provider user without one.
+	AllowSignup bool
+}
+
+// Mail configures how emails are sent.
+type Mail struct {
+	// Driver is smtp, or file or log for development.
+	Driver string
+	From   string
+	// BaseURL is the URL of the Flagon UI, which the links in emails point to.
+	BaseURL string
+	// Dir is where the file driver writes emails.
+	Dir  string
+	SMTP SMTP
+}
+
+type SMTP struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	// TLS connects with TLS from the start, usually on port 465, instead of upgrading with STARTTLS.
+	TLS bool
+}
+
+// RateLimit limits how many requests clients make to a route in a time window.
+type RateLimit struct {
+	Enabled bool
+	Rules   []RateLimitRule
+}
+
+// Keys a rate limit counts the requests by.
+const (
+	RateLimitKeyIP = "ip"
+	// RateLimitKeyUsername counts by the username field of the JSON body.
+	RateLimitKeyUsername = "username"
+	// RateLimitKeyToken counts by the bearer token, such as the access token of an SDK.
+	RateLimitKeyToken = "token"
+)
+
+// RateLimitRule allows Limit requests to Route per key in each Window. A route can have several rules,
+// such as one per IP address and one per username.
+type RateLimitRule struct {
+	// Route is the method and path pattern of the route, such as "POST /api/v1/login".
+	Route  string
+	Key    string
+	Limit  int
+	Window time.Duration
+}
+
+func (r RateLimitRule) Validate() error {
+	if method, path, found := strings.Cut(r.Route, " "); !found || method == "" || !strings.HasPrefix(path, "/") {
+		return fmt.Errorf("rateLimit.rules: route %q must be a method and a path, such as \"POST /api/v1/login\"", r.Route)
+	}
+	switch r.Key {
+	case RateLimitKeyIP, RateLimitKeyUsername, RateLimitKeyToken:
+	default:
+		return fmt.Errorf("rateLimit.rules: the key of %s must be ip, username or token", r.Route)
+	}
+	if r.Limit <= 0 || r.Window <= 0 {
+		return fmt.Errorf("rateLimit.rules: the limit and window of %s must be positive", r.Route)
+	}
+	return nil
 }
 
+// Cache configures where sessions, login challenges and rate limit counts are kept.
 type Cache struct {
+	// Driver is redis, memory or database. Memory is lost on restart and not shared between
+	// instances; redis is needed for more than one instance to stream flag changes to each SDK.
+	Driver   string
 	Addr     string
 	DB       int
 	Password string
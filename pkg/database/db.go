@@ -26,7 +26,8 @@ func (db *DB) DriverName() string {
 func Open() (*DB, error) {
 	dbCfg := config.GetConfig().Database
 	var gormCfg = &gorm.Config{
-		Logger: &slogLogger{},
+		Logger:         &slogLogger{},
+		TranslateError: true,
 	}
 	var db *gorm.DB
 	var err error
@@ -0,0 +1,23 @@
+package evaluation
+
+// Context describes who a flag is evaluated for.
+type Context struct {
+	// Key identifies the subject, usually a user ID. It is also the default rollout bucketing value.
+	Key string `json:"key"`
+	// Attributes are arbitrary properties target group rules can match on, e.g. country or appVersion.
+	Attributes map[string]any `json:"attributes,omitempty"`
+}
+
+// Attribute returns the named attribute; "key" resolves to Context.Key unless overridden by an attribute.
+func (c *Context) Attribute(name string) (any, bool) {
+	if c == nil {
+		return nil, false
+	}
+	if value, ok := c.Attributes[name]; ok {
+		return value, true
+	}
+	if name == "key" && c.Key != "" {
+		return c.Key, true
+	}
+	return nil, false
+}
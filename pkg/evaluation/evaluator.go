@@ -0,0 +1,108 @@
+// Package evaluation decides the value of feature flags for an evaluation context.
+//
+// A flag is evaluated against the Snapshot of one environment with the following precedence:
+//
+//  1. Target group states of the flag, by TargetGroup.Position. The first group whose
+//     rules match the context and whose rollout includes it decides the value; the reason is
+//     TARGET_MATCH, or ROLLOUT when the group only covers part of its audience.
+//  2. The environment-wide state of the flag (ENVIRONMENT).
+//  3. The default value of the feature (DEFAULT).
+package evaluation
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Reason explains which rule produced a flag value.
+type Reason string
+
+const (
+	ReasonDefault     Reason = "DEFAULT"
+	ReasonEnvironment Reason = "ENVIRONMENT"
+	ReasonTargetMatch Reason = "TARGET_MATCH"
+	ReasonRollout     Reason = "ROLLOUT"
+)
+
+// Result is the value of one flag for a context.
+type Result struct {
+	Key    string `json:"key"`
+	Value  bool   `json:"value"`
+	Reason Reason `json:"reason"`
+	// TargetGroupID is the group that decided the value for TARGET_MATCH and ROLLOUT results.
+	TargetGroupID *uuid.UUID `json:"targetGroupId,omitempty"`
+}
+
+// Store loads the flag configuration of an environment.
+type Store interface {
+	Snapshot(ctx context.Context, projectID, environmentID uuid.UUID) (*Snapshot, error)
+}
+
+// Evaluator evaluates every flag of an environment for a context.
+type Evaluator interface {
+	Evaluate(ctx context.Context, projectID, environmentID uuid.UUID, evalCtx *Context) ([]Result, error)
+}
+
+type evaluator struct {
+	store Store
+}
+
+func NewEvaluator(store Store) Evaluator {
+	return &evaluator{store: store}
+}
+
+func (e *evaluator) Evaluate(ctx context.Context, projectID, environmentID uuid.UUID, evalCtx *Context) ([]Result, error) {
+	snapshot, err := e.store.Snapshot(ctx, projectID, environmentID)
+	if err != nil {
+		return nil, err
+	}
+	return snapshot.EvaluateAll(evalCtx), nil
+}
+
+// EvaluateAll evaluates every flag of the snapshot, in snapshot order.
+func (s *Snapshot) EvaluateAll(evalCtx *Context) []Result {
+	results := make([]Result, 0, len(s.Flags))
+	for _, flag := range s.Flags {
+		results = append(results, s.Evaluate(flag, evalCtx))
+	}
+	return results
+}
+
+// EvaluateKey evaluates the flag with the given key, reporting false when the snapshot has no such flag.
+func (s *Snapshot) EvaluateKey(key string, evalCtx *Context) (Result, bool) {
+	flag, ok := s.Flag(key)
+	if !ok {
+		return Result{}, false
+	}
+	return s.Evaluate(flag, evalCtx), true
+}
+
+// Evaluate evaluates one flag of the snapshot following the package precedence rules.
+func (s *Snapshot) Evaluate(flag *Flag, evalCtx *Context) Result {
+	for _, group := range s.TargetGroups {
+		target, ok := flag.target(group.ID)
+		if !ok || !group.matcher(evalCtx) || !group.inRollout(flag.Key, evalCtx) {
+			continue
+		}
+		reason := ReasonTargetMatch
+		if group.partial() {
+			reason = ReasonRollout
+		}
+		return Result{Key: flag.Key, Value: target.Enabled, Reason: reason, TargetGroupID: &group.ID}
+	}
+
+	if flag.Enabled != nil {
+		return Result{Key: flag.Key, Value: *flag.Enabled, Reason: ReasonEnvironment}
+	}
+	return Result{Key: flag.Key, Value: flag.Default, Reason: ReasonDefault}
+}
+
+func (f *Flag) target(groupID uuid.UUID) (*Target, bool) {
+	for _, target := range f.Targets {
+		if target.TargetGroupID == groupID {
+			return target, true
+		}
+	}
+	return nil, false
+}
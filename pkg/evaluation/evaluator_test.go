@@ -0,0 +1,452 @@
+package evaluation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+var (
+	betaGroupID  = uuid.MustParse("00000000-0000-0000-0000-00000000000a")
+	staffGroupID = uuid.MustParse("00000000-0000-0000-0000-00000000000b")
+)
+
+// newTestSnapshot builds a compiled snapshot with one flag, "checkout", and the given target groups.
+func newTestSnapshot(flag *Flag, groups ...*TargetGroup) *Snapshot {
+	flag.Key = "checkout"
+	snapshot := &Snapshot{Flags: []*Flag{flag}, TargetGroups: groups}
+	snapshot.Compile()
+	return snapshot
+}
+
+func betaGroup(position int) *TargetGroup {
+	return &TargetGroup{
+		ID:                betaGroupID,
+		Name:              "beta",
+		Position:          position,
+		RolloutPercentage: 100,
+		Rules:             json.RawMessage(`{"attribute": "beta", "operator": "equals", "values": [true]}`),
+	}
+}
+
+func staffGroup(position int) *TargetGroup {
+	return &TargetGroup{
+		ID:                staffGroupID,
+		Name:              "staff",
+		Position:          position,
+		RolloutPercentage: 100,
+		Rules:             json.RawMessage(`{"attribute": "email", "operator": "ends_with", "values": ["@example.com"]}`),
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func TestEvaluatePrecedence(t *testing.T) {
+	betaUser := &Context{Key: "user-1", Attributes: map[string]any{"beta": true}}
+	betaStaff := &Context{Key: "user-2", Attributes: map[string]any{"beta": true, "email": "ana@example.com"}}
+	other := &Context{Key: "user-3", Attributes: map[string]any{"beta": false}}
+
+	tests := []struct {
+		name       string
+		snapshot   *Snapshot
+		ctx        *Context
+		wantValue  bool
+		wantReason Reason
+		wantGroup  *uuid.UUID
+	}{
+		{
+			name:       "default",
+			snapshot:   newTestSnapshot(&Flag{Default: true}),
+			ctx:        other,
+			wantValue:  true,
+			wantReason: ReasonDefault,
+		},
+		{
+			name:       "environment overrides default",
+			snapshot:   newTestSnapshot(&Flag{Default: true, Enabled: boolPtr(false)}),
+			ctx:        other,
+			wantValue:  false,
+			wantReason: ReasonEnvironment,
+		},
+		{
+			name:       "flag enabled in environment",
+			snapshot:   newTestSnapshot(&Flag{Enabled: boolPtr(true)}),
+			ctx:        nil,
+			wantValue:  true,
+			wantReason: ReasonEnvironment,
+		},
+		{
+			name: "target group overrides environment",
+			snapshot: newTestSnapshot(
+				&Flag{Enabled: boolPtr(false), Targets: []*Target{{TargetGroupID: betaGroupID, Enabled: true}}},
+				betaGroup(0),
+			),
+			ctx:        betaUser,
+			wantValue:  true,
+			wantReason: ReasonTargetMatch,
+			wantGroup:  &betaGroupID,
+		},
+		{
+			name: "flag disabled for target group",
+			snapshot: newTestSnapshot(
+				&Flag{Default: true, Enabled: boolPtr(true), Targets: []*Target{{TargetGroupID: betaGroupID, Enabled: false}}},
+				betaGroup(0),
+			),
+			ctx:        betaUser,
+			wantValue:  false,
+			wantReason: ReasonTargetMatch,
+			wantGroup:  &betaGroupID,
+		},
+		{
+			name: "context outside the target group",
+			snapshot: newTestSnapshot(
+				&Flag{Enabled: boolPtr(false), Targets: []*Target{{TargetGroupID: betaGroupID, Enabled: true}}},
+				betaGroup(0),
+			),
+			ctx:        other,
+			wantValue:  false,
+			wantReason: ReasonEnvironment,
+		},
+		{
+			name: "target group without a state for the flag",
+			snapshot: newTestSnapshot(
+				&Flag{Default: true},
+				betaGroup(0),
+			),
+			ctx:        betaUser,
+			wantValue:  true,
+			wantReason: ReasonDefault,
+		},
+		{
+			name: "state of a group not in the environment",
+			snapshot: newTestSnapshot(
+				&Flag{Targets: []*Target{{TargetGroupID: betaGroupID, Enabled: true}}},
+			),
+			ctx:        betaUser,
+			wantValue:  false,
+			wantReason: ReasonDefault,
+		},
+		{
+			name: "lowest position wins",
+			snapshot: newTestSnapshot(
+				&Flag{Targets: []*Target{
+					{TargetGroupID: betaGroupID, Enabled: true},
+					{TargetGroupID: staffGroupID, Enabled: false},
+				}},
+				betaGroup(1),
+				staffGroup(0),
+			),
+			ctx:        betaStaff,
+			wantValue:  false,
+			wantReason: ReasonTargetMatch,
+			wantGroup:  &staffGroupID,
+		},
+		{
+			name: "lower position without a state for the flag",
+			snapshot: newTestSnapshot(
+				&Flag{Targets: []*Target{{TargetGroupID: betaGroupID, Enabled: true}}},
+				betaGroup(1),
+				staffGroup(0),
+			),
+			ctx:        betaStaff,
+			wantValue:  true,
+			wantReason: ReasonTargetMatch,
+			wantGroup:  &betaGroupID,
+		},
+		{
+			name: "group with no rules includes everyone",
+			snapshot: newTestSnapshot(
+				&Flag{Targets: []*Target{{TargetGroupID: betaGroupID, Enabled: true}}},
+				&TargetGroup{ID: betaGroupID, RolloutPercentage: 100},
+			),
+			ctx:        nil,
+			wantValue:  true,
+			wantReason: ReasonTargetMatch,
+			wantGroup:  &betaGroupID,
+		},
+		{
+			name: "group with malformed stored rules matches nobody",
+			snapshot: newTestSnapshot(
+				&Flag{Enabled: boolPtr(false), Targets: []*Target{{TargetGroupID: betaGroupID, Enabled: true}}},
+				&TargetGroup{ID: betaGroupID, RolloutPercentage: 100, Rules: json.RawMessage(`{"attribute": "beta"}`)},
+			),
+			ctx:        betaUser,
+			wantValue:  false,
+			wantReason: ReasonEnvironment,
+		},
+		{
+			name: "group with a zero rollout",
+			snapshot: newTestSnapshot(
+				&Flag{Enabled: boolPtr(false), Targets: []*Target{{TargetGroupID: betaGroupID, Enabled: true}}},
+				&TargetGroup{ID: betaGroupID, RolloutPercentage: 0},
+			),
+			ctx:        betaUser,
+			wantValue:  false,
+			wantReason: ReasonEnvironment,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.snapshot.EvaluateKey("checkout", tt.ctx)
+			if !ok {
+				t.Fatal("flag not found")
+			}
+			if got.Value != tt.wantValue || got.Reason != tt.wantReason {
+				t.Errorf("got %t (%s), want %t (%s)", got.Value, got.Reason, tt.wantValue, tt.wantReason)
+			}
+			if (got.TargetGroupID == nil) != (tt.wantGroup == nil) ||
+				got.TargetGroupID != nil && *got.TargetGroupID != *tt.wantGroup {
+				t.Errorf("target group = %v, want %v", got.TargetGroupID, tt.wantGroup)
+			}
+		})
+	}
+}
+
+func TestEvaluatePartialRollout(t *testing.T) {
+	group := &TargetGroup{ID: betaGroupID, RolloutPercentage: 50, RolloutSalt: "salt"}
+	snapshot := newTestSnapshot(
+		&Flag{Enabled: boolPtr(false), Targets: []*Target{{TargetGroupID: betaGroupID, Enabled: true}}},
+		group,
+	)
+
+	included := 0
+	for i := range 200 {
+		key := fmt.Sprintf("user-%d", i)
+		got, _ := snapshot.EvaluateKey("checkout", &Context{Key: key})
+		want := Result{Key: "checkout", Value: false, Reason: ReasonEnvironment}
+		if Bucket("checkout", "salt", key) < RolloutThreshold(50) {
+			want = Result{Key: "checkout", Value: true, Reason: ReasonRollout}
+			included++
+		}
+		if got.Value != want.Value || got.Reason != want.Reason {
+			t.Errorf("%s: got %t (%s), want %t (%s)", key, got.Value, got.Reason, want.Value, want.Reason)
+		}
+	}
+	if included == 0 || included == 200 {
+		t.Errorf("%d of 200 contexts in a 50%% rollout", included)
+	}
+
+	if got, _ := snapshot.EvaluateKey("checkout", &Context{}); got.Reason != ReasonEnvironment {
+		t.Errorf("context without a key: reason %s, want it outside the rollout", got.Reason)
+	}
+}
+
+func TestApplyPatchOrdersTargetGroups(t *testing.T) {
+	snapshot := newTestSnapshot(&Flag{Targets: []*Target{
+		{TargetGroupID: betaGroupID, Enabled: true},
+		{TargetGroupID: staffGroupID, Enabled: false},
+	}})
+	ctx := &Context{Key: "user-2", Attributes: map[string]any{"beta": true, "email": "ana@example.com"}}
+
+	patched := snapshot.Apply(&Patch{TargetGroups: []*TargetGroup{staffGroup(1), betaGroup(0)}})
+	if got, _ := patched.EvaluateKey("checkout", ctx); !got.Value || *got.TargetGroupID != betaGroupID {
+		t.Errorf("got %t from %v, want the beta group to win", got.Value, got.TargetGroupID)
+	}
+
+	patched = patched.Apply(&Patch{TargetGroups: []*TargetGroup{betaGroup(1), staffGroup(0)}})
+	if got, _ := patched.EvaluateKey("checkout", ctx); got.Value || *got.TargetGroupID != staffGroupID {
+		t.Errorf("got %t from %v, want the staff group to win once moved first", got.Value, got.TargetGroupID)
+	}
+}
+
+type fakeStore struct {
+	snapshot *Snapshot
+}
+
+func (s *fakeStore) Snapshot(context.Context, uuid.UUID, uuid.UUID) (*Snapshot, error) {
+	if s.snapshot == nil {
+		return nil, errors.New("no snapshot")
+	}
+	return s.snapshot, nil
+}
+
+func TestEvaluator(t *testing.T) {
+	snapshot := &Snapshot{Flags: []*Flag{
+		{Key: "a", Default: true},
+		{Key: "b", Enabled: boolPtr(true)},
+	}}
+	snapshot.Compile()
+
+	results, err := NewEvaluator(&fakeStore{snapshot: snapshot}).Evaluate(context.Background(), uuid.New(), uuid.New(), &Context{Key: "user"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Result{
+		{Key: "a", Value: true, Reason: ReasonDefault},
+		{Key: "b", Value: true, Reason: ReasonEnvironment},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(results), len(want))
+	}
+	for i := range want {
+		if results[i] != want[i] {
+			t.Errorf("result %d = %+v, want %+v", i, results[i], want[i])
+		}
+	}
+
+	if _, err := NewEvaluator(&fakeStore{}).Evaluate(context.Background(), uuid.New(), uuid.New(), nil); err == nil {
+		t.Error("store error not returned")
+	}
+	if _, ok := snapshot.EvaluateKey("missing", nil); ok {
+		t.Error("unknown flag found")
+	}
+}
+
+func TestRules(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules string
+		attrs map[string]any
+		want  bool
+	}{
+		{name: "equals", rules: `{"attribute": "country", "operator": "equals", "values": ["VN"]}`, attrs: map[string]any{"country": "VN"}, want: true},
+		{name: "equals other", rules: `{"attribute": "country", "operator": "equals", "values": ["VN"]}`, attrs: map[string]any{"country": "SG"}},
+		{name: "equals number text", rules: `{"attribute": "tier", "operator": "equals", "values": [1]}`, attrs: map[string]any{"tier": "1"}, want: true},
+		{name: "equals boolean", rules: `{"attribute": "beta", "operator": "equals", "values": [true]}`, attrs: map[string]any{"beta": true}, want: true},
+		{name: "not_equals", rules: `{"attribute": "country", "operator": "not_equals", "values": ["VN"]}`, attrs: map[string]any{"country": "SG"}, want: true},
+		{name: "not_equals same", rules: `{"attribute": "country", "operator": "not_equals", "values": ["VN"]}`, attrs: map[string]any{"country": "VN"}},
+		{name: "not_equals missing attribute", rules: `{"attribute": "country", "operator": "not_equals", "values": ["VN"]}`},
+		{name: "in", rules: `{"attribute": "country", "operator": "in", "values": ["VN", "SG"]}`, attrs: map[string]any{"country": "SG"}, want: true},
+		{name: "in other", rules: `{"attribute": "country", "operator": "in", "values": ["VN", "SG"]}`, attrs: map[string]any{"country": "FR"}},
+		{name: "not_in", rules: `{"attribute": "country", "operator": "not_in", "values": ["VN", "SG"]}`, attrs: map[string]any{"country": "FR"}, want: true},
+		{name: "not_in listed", rules: `{"attribute": "country", "operator": "not_in", "values": ["VN", "SG"]}`, attrs: map[string]any{"country": "VN"}},
+		{name: "contains", rules: `{"attribute": "email", "operator": "contains", "values": ["@corp"]}`, attrs: map[string]any{"email": "a@corp.example"}, want: true},
+		{name: "contains other", rules: `{"attribute": "email", "operator": "contains", "values": ["@corp"]}`, attrs: map[string]any{"email": "a@example.com"}},
+		{name: "starts_with", rules: `{"attribute": "plan", "operator": "starts_with", "values": ["ent"]}`, attrs: map[string]any{"plan": "enterprise"}, want: true},
+		{name: "starts_with other", rules: `{"attribute": "plan", "operator": "starts_with", "values": ["ent"]}`, attrs: map[string]any{"plan": "free"}},
+		{name: "ends_with", rules: `{"attribute": "email", "operator": "ends_with", "values": ["@a.com", "@b.com"]}`, attrs: map[string]any{"email": "x@b.com"}, want: true},
+		{name: "ends_with other", rules: `{"attribute": "email", "operator": "ends_with", "values": ["@a.com"]}`, attrs: map[string]any{"email": "x@a.com.evil"}},
+		{name: "regex", rules: `{"attribute": "device", "operator": "regex", "values": ["^iPhone1[0-9]$"]}`, attrs: map[string]any{"device": "iPhone15"}, want: true},
+		{name: "regex other", rules: `{"attribute": "device", "operator": "regex", "values": ["^iPhone1[0-9]$"]}`, attrs: map[string]any{"device": "iPhone9"}},
+		{name: "gt", rules: `{"attribute": "age", "operator": "gt", "values": [18]}`, attrs: map[string]any{"age": 19}, want: true},
+		{name: "gt equal", rules: `{"attribute": "age", "operator": "gt", "values": [18]}`, attrs: map[string]any{"age": 18}},
+		{name: "gte", rules: `{"attribute": "age", "operator": "gte", "values": [18]}`, attrs: map[string]any{"age": 18.0}, want: true},
+		{name: "gte below", rules: `{"attribute": "age", "operator": "gte", "values": [18]}`, attrs: map[string]any{"age": 17}},
+		{name: "lt", rules: `{"attribute": "age", "operator": "lt", "values": [18]}`, attrs: map[string]any{"age": "17"}, want: true},
+		{name: "lt equal", rules: `{"attribute": "age", "operator": "lt", "values": [18]}`, attrs: map[string]any{"age": 18}},
+		{name: "lte", rules: `{"attribute": "age", "operator": "lte", "values": [18]}`, attrs: map[string]any{"age": 18}, want: true},
+		{name: "lte above", rules: `{"attribute": "age", "operator": "lte", "values": [18]}`, attrs: map[string]any{"age": 18.5}},
+		{name: "number against text", rules: `{"attribute": "age", "operator": "gt", "values": [18]}`, attrs: map[string]any{"age": "old"}},
+		{name: "semver_eq", rules: `{"attribute": "appVersion", "operator": "semver_eq", "values": ["2.3.0"]}`, attrs: map[string]any{"appVersion": "2.3.0"}, want: true},
+		{name: "semver_eq other", rules: `{"attribute": "appVersion", "operator": "semver_eq", "values": ["2.3.0"]}`, attrs: map[string]any{"appVersion": "2.3.1"}},
+		{name: "semver_gt", rules: `{"attribute": "appVersion", "operator": "semver_gt", "values": ["2.3.0"]}`, attrs: map[string]any{"appVersion": "2.10.0"}, want: true},
+		{name: "semver_gt prerelease", rules: `{"attribute": "appVersion", "operator": "semver_gt", "values": ["2.3.0"]}`, attrs: map[string]any{"appVersion": "2.3.0-beta.1"}},
+		{name: "semver_gte", rules: `{"attribute": "appVersion", "operator": "semver_gte", "values": ["2.3.0"]}`, attrs: map[string]any{"appVersion": "2.3.0"}, want: true},
+		{name: "semver_gte below", rules: `{"attribute": "appVersion", "operator": "semver_gte", "values": ["2.3.0"]}`, attrs: map[string]any{"appVersion": "2.2.9"}},
+		{name: "semver_lt", rules: `{"attribute": "appVersion", "operator": "semver_lt", "values": ["2.3.0"]}`, attrs: map[string]any{"appVersion": "2.3.0-rc.1"}, want: true},
+		{name: "semver_lt equal", rules: `{"attribute": "appVersion", "operator": "semver_lt", "values": ["2.3.0"]}`, attrs: map[string]any{"appVersion": "2.3.0"}},
+		{name: "semver_lte", rules: `{"attribute": "appVersion", "operator": "semver_lte", "values": ["2.3.0"]}`, attrs: map[string]any{"appVersion": "2.3.0"}, want: true},
+		{name: "semver_lte above", rules: `{"attribute": "appVersion", "operator": "semver_lte", "values": ["2.3.0"]}`, attrs: map[string]any{"appVersion": "3.0.0"}},
+		{name: "semver invalid attribute", rules: `{"attribute": "appVersion", "operator": "semver_lte", "values": ["2.3.0"]}`, attrs: map[string]any{"appVersion": "latest"}},
+		{name: "before", rules: `{"attribute": "signupDate", "operator": "before", "values": ["2024-01-01"]}`, attrs: map[string]any{"signupDate": "2023-12-31T23:59:59Z"}, want: true},
+		{name: "before later", rules: `{"attribute": "signupDate", "operator": "before", "values": ["2024-01-01"]}`, attrs: map[string]any{"signupDate": "2024-01-01"}},
+		{name: "after", rules: `{"attribute": "signupDate", "operator": "after", "values": ["2024-01-01T00:00:00Z"]}`, attrs: map[string]any{"signupDate": 1735689600}, want: true},
+		{name: "after earlier", rules: `{"attribute": "signupDate", "operator": "after", "values": ["2024-01-01T00:00:00Z"]}`, attrs: map[string]any{"signupDate": "2023-06-01"}},
+		{name: "key attribute", rules: `{"attribute": "key", "operator": "in", "values": ["user-1"]}`, want: true},
+		{
+			name:  "and",
+			rules: `{"and": [{"attribute": "country", "operator": "in", "values": ["VN", "SG"]}, {"attribute": "appVersion", "operator": "semver_gte", "values": ["2.3.0"]}]}`,
+			attrs: map[string]any{"country": "VN", "appVersion": "2.3.0"},
+			want:  true,
+		},
+		{
+			name:  "and with one failing",
+			rules: `{"and": [{"attribute": "country", "operator": "in", "values": ["VN", "SG"]}, {"attribute": "appVersion", "operator": "semver_gte", "values": ["2.3.0"]}]}`,
+			attrs: map[string]any{"country": "VN", "appVersion": "2.2.0"},
+		},
+		{
+			name:  "or",
+			rules: `{"or": [{"attribute": "beta", "operator": "equals", "values": [true]}, {"attribute": "email", "operator": "ends_with", "values": ["@example.com"]}]}`,
+			attrs: map[string]any{"email": "ana@example.com"},
+			want:  true,
+		},
+		{
+			name:  "or with none matching",
+			rules: `{"or": [{"attribute": "beta", "operator": "equals", "values": [true]}, {"attribute": "email", "operator": "ends_with", "values": ["@example.com"]}]}`,
+			attrs: map[string]any{"beta": false, "email": "ana@example.org"},
+		},
+		{
+			name:  "or nested in and",
+			rules: `{"and": [{"attribute": "country", "operator": "equals", "values": ["VN"]}, {"or": [{"attribute": "beta", "operator": "equals", "values": [true]}, {"attribute": "age", "operator": "gte", "values": [30]}]}]}`,
+			attrs: map[string]any{"country": "VN", "age": 31},
+			want:  true,
+		},
+		{
+			name:  "or nested in and failing",
+			rules: `{"and": [{"attribute": "country", "operator": "equals", "values": ["VN"]}, {"or": [{"attribute": "beta", "operator": "equals", "values": [true]}, {"attribute": "age", "operator": "gte", "values": [30]}]}]}`,
+			attrs: map[string]any{"country": "VN", "age": 29},
+		},
+		{
+			name:  "and nested in or",
+			rules: `{"or": [{"attribute": "staff", "operator": "equals", "values": [true]}, {"and": [{"attribute": "country", "operator": "equals", "values": ["SG"]}, {"attribute": "plan", "operator": "in", "values": ["pro"]}]}]}`,
+			attrs: map[string]any{"country": "SG", "plan": "pro"},
+			want:  true,
+		},
+		{name: "null rules", rules: `null`, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseRules([]byte(tt.rules)); err != nil {
+				t.Fatalf("ParseRules: %v", err)
+			}
+			match := compileRules([]byte(tt.rules))
+			if got := match(&Context{Key: "user-1", Attributes: tt.attrs}); got != tt.want {
+				t.Errorf("match = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRulesErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		rules    string
+		wantPath string
+	}{
+		{name: "invalid JSON", rules: `{"and": [`, wantPath: "rules"},
+		{name: "trailing data", rules: `{"attribute": "a", "operator": "equals", "values": [1]} {}`, wantPath: "rules"},
+		{name: "unknown field", rules: `{"attribute": "a", "operator": "equals", "values": [1], "not": true}`, wantPath: "rules"},
+		{name: "not an object", rules: `{"and": [null]}`, wantPath: "rules.and[0]"},
+		{name: "empty object", rules: `{}`, wantPath: "rules"},
+		{name: "and with a condition", rules: `{"and": [{"attribute": "a", "operator": "equals", "values": [1]}], "attribute": "b"}`, wantPath: "rules"},
+		{name: "empty and", rules: `{"and": []}`, wantPath: "rules.and"},
+		{name: "empty or", rules: `{"and": [{"or": []}]}`, wantPath: "rules.and[0].or"},
+		{name: "missing attribute", rules: `{"operator": "equals", "values": [1]}`, wantPath: "rules.attribute"},
+		{name: "missing operator", rules: `{"or": [{"attribute": "a", "values": [1]}]}`, wantPath: "rules.or[0].operator"},
+		{name: "unknown operator", rules: `{"attribute": "a", "operator": "like", "values": ["x"]}`, wantPath: "rules.operator"},
+		{name: "missing values", rules: `{"attribute": "a", "operator": "in"}`, wantPath: "rules.values"},
+		{name: "several values for a single operator", rules: `{"attribute": "a", "operator": "equals", "values": [1, 2]}`, wantPath: "rules.values"},
+		{name: "object value", rules: `{"attribute": "a", "operator": "in", "values": ["x", {}]}`, wantPath: "rules.values[1]"},
+		{name: "number for a text operator", rules: `{"attribute": "a", "operator": "contains", "values": [1]}`, wantPath: "rules.values[0]"},
+		{name: "invalid regex", rules: `{"attribute": "a", "operator": "regex", "values": ["("]}`, wantPath: "rules.values[0]"},
+		{name: "text for a number operator", rules: `{"attribute": "a", "operator": "gt", "values": ["1"]}`, wantPath: "rules.values[0]"},
+		{name: "invalid version", rules: `{"attribute": "a", "operator": "semver_gt", "values": ["two"]}`, wantPath: "rules.values[0]"},
+		{name: "invalid time", rules: `{"attribute": "a", "operator": "before", "values": ["yesterday"]}`, wantPath: "rules.values[0]"},
+		{
+			name:     "nested error",
+			rules:    `{"and": [{"attribute": "a", "operator": "equals", "values": [1]}, {"or": [{"attribute": "b", "operator": "in", "values": [1]}, {"attribute": "c", "operator": "semver_gte", "values": ["x"]}]}]}`,
+			wantPath: "rules.and[1].or[1].values[0]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseRules([]byte(tt.rules))
+			var ruleErr *RuleError
+			if !errors.As(err, &ruleErr) {
+				t.Fatalf("ParseRules = %v, want a RuleError", err)
+			}
+			if ruleErr.Path != tt.wantPath {
+				t.Errorf("path = %q, want %q (%v)", ruleErr.Path, tt.wantPath, err)
+			}
+			if compileRules([]byte(tt.rules))(&Context{Key: "user-1", Attributes: map[string]any{"a": 1, "b": 1, "c": "9.9.9"}}) {
+				t.Error("invalid rules matched a context")
+			}
+		})
+	}
+}
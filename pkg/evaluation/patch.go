@@ -0,0 +1,55 @@
+package evaluation
+
+// Patch is an incremental change to a Snapshot, sent to SDKs after a flag, feature or target group changes.
+type Patch struct {
+	// Flags replace the flags with the same keys, or are added when the snapshot does not have them.
+	Flags []*Flag `json:"flags,omitempty"`
+	// DeletedFlags are the keys of flags removed from the environment.
+	DeletedFlags []string `json:"deletedFlags,omitempty"`
+	// TargetGroups replaces every target group of the snapshot when it is not null.
+	TargetGroups []*TargetGroup `json:"targetGroups"`
+}
+
+// Apply returns a compiled copy of the snapshot with the patch applied. The snapshot itself is not
+// modified, so it can keep being evaluated while the patched copy is built. Flags and target groups
+// are shared with the patch, which must not be modified afterwards.
+func (s *Snapshot) Apply(patch *Patch) *Snapshot {
+	patched := &Snapshot{
+		ProjectID:     s.ProjectID,
+		EnvironmentID: s.EnvironmentID,
+		Flags:         make([]*Flag, 0, len(s.Flags)+len(patch.Flags)),
+		TargetGroups:  s.TargetGroups,
+	}
+	if patch.TargetGroups != nil {
+		compileTargetGroups(patch.TargetGroups)
+		patched.TargetGroups = patch.TargetGroups
+	}
+
+	replaced := make(map[string]*Flag, len(patch.Flags))
+	for _, flag := range patch.Flags {
+		replaced[flag.Key] = flag
+	}
+	deleted := make(map[string]bool, len(patch.DeletedFlags))
+	for _, key := range patch.DeletedFlags {
+		deleted[key] = true
+	}
+
+	for _, flag := range s.Flags {
+		if deleted[flag.Key] {
+			continue
+		}
+		if replacement, ok := replaced[flag.Key]; ok {
+			flag = replacement
+			delete(replaced, flag.Key)
+		}
+		patched.Flags = append(patched.Flags, flag)
+	}
+	for _, flag := range patch.Flags {
+		if _, ok := replaced[flag.Key]; ok {
+			patched.Flags = append(patched.Flags, flag)
+		}
+	}
+
+	patched.indexFlags()
+	return patched
+}
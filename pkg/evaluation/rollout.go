@@ -0,0 +1,69 @@
+package evaluation
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math"
+)
+
+const (
+	// BucketCount is the number of rollout buckets, which resolves percentages to a thousandth of a percent.
+	BucketCount = 100_000
+	// bucketsPerPercent is the number of buckets covered by one percent of rollout.
+	bucketsPerPercent = BucketCount / 100
+)
+
+// Bucket returns the rollout bucket of a bucketing value for a flag, in [0, BucketCount).
+//
+// The bucket is the first 8 bytes of SHA-256("<flagKey>:<salt>:<value>") read as a big-endian unsigned
+// integer, modulo BucketCount. Hashing the flag key spreads each flag's rollout over different users,
+// and the salt lets a target group reshuffle its audience. A value always lands in the same bucket, so
+// raising a percentage only ever adds users.
+func Bucket(flagKey, salt, value string) int {
+	sum := sha256.Sum256([]byte(flagKey + ":" + salt + ":" + value))
+	return int(binary.BigEndian.Uint64(sum[:8]) % BucketCount)
+}
+
+// RolloutThreshold converts a percentage to the number of buckets it covers; a value is included in the
+// rollout when its bucket is below the threshold.
+func RolloutThreshold(percentage float64) int {
+	return int(math.Round(math.Max(0, math.Min(100, percentage)) * bucketsPerPercent))
+}
+
+// partial reports whether the group's rollout covers only part of the contexts matching its rules.
+func (g *TargetGroup) partial() bool {
+	return RolloutThreshold(g.RolloutPercentage) < BucketCount
+}
+
+// inRollout reports whether the context falls into the group's rollout for the flag.
+// Contexts without a bucketing value are only included in a full rollout.
+func (g *TargetGroup) inRollout(flagKey string, ctx *Context) bool {
+	threshold := RolloutThreshold(g.RolloutPercentage)
+	if threshold >= BucketCount {
+		return true
+	}
+	if threshold <= 0 {
+		return false
+	}
+	value, ok := g.bucketValue(ctx)
+	if !ok {
+		return false
+	}
+	return Bucket(flagKey, g.RolloutSalt, value) < threshold
+}
+
+// bucketValue returns the context value the group buckets by: the BucketBy attribute, or the context key.
+func (g *TargetGroup) bucketValue(ctx *Context) (string, bool) {
+	if g.BucketBy == "" {
+		if ctx == nil || ctx.Key == "" {
+			return "", false
+		}
+		return ctx.Key, true
+	}
+	value, ok := ctx.Attribute(g.BucketBy)
+	if !ok {
+		return "", false
+	}
+	text, ok := scalarText(value)
+	return text, ok && text != ""
+}
@@ -0,0 +1,70 @@
+package evaluation
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+// rolloutVectors is the format of docs/rollout-vectors.json, which SDKs are checked against too.
+type rolloutVectors struct {
+	BucketCount int `json:"bucketCount"`
+	Buckets     []struct {
+		FlagKey string `json:"flagKey"`
+		Salt    string `json:"salt"`
+		Value   string `json:"value"`
+		Bucket  int    `json:"bucket"`
+	} `json:"buckets"`
+	Rollouts []struct {
+		FlagKey    string  `json:"flagKey"`
+		Salt       string  `json:"salt"`
+		Value      string  `json:"value"`
+		Percentage float64 `json:"percentage"`
+		Threshold  int     `json:"threshold"`
+		Included   bool    `json:"included"`
+	} `json:"rollouts"`
+}
+
+func loadRolloutVectors(t *testing.T) *rolloutVectors {
+	t.Helper()
+	data, err := os.ReadFile("../../docs/rollout-vectors.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var vectors rolloutVectors
+	if err := json.Unmarshal(data, &vectors); err != nil {
+		t.Fatal(err)
+	}
+	if len(vectors.Buckets) == 0 || len(vectors.Rollouts) == 0 {
+		t.Fatal("no test vectors")
+	}
+	return &vectors
+}
+
+func TestRolloutVectors(t *testing.T) {
+	vectors := loadRolloutVectors(t)
+	if vectors.BucketCount != BucketCount {
+		t.Errorf("bucketCount = %d, want %d", vectors.BucketCount, BucketCount)
+	}
+
+	for _, v := range vectors.Buckets {
+		if got := Bucket(v.FlagKey, v.Salt, v.Value); got != v.Bucket {
+			t.Errorf("Bucket(%q, %q, %q) = %d, want %d", v.FlagKey, v.Salt, v.Value, got, v.Bucket)
+		}
+	}
+
+	for _, v := range vectors.Rollouts {
+		threshold := RolloutThreshold(v.Percentage)
+		if threshold != v.Threshold {
+			t.Errorf("RolloutThreshold(%v) = %d, want %d", v.Percentage, threshold, v.Threshold)
+		}
+		if got := Bucket(v.FlagKey, v.Salt, v.Value) < threshold; got != v.Included {
+			t.Errorf("%q, %q, %q at %v%%: included = %t, want %t", v.FlagKey, v.Salt, v.Value, v.Percentage, got, v.Included)
+		}
+
+		group := &TargetGroup{RolloutPercentage: v.Percentage, RolloutSalt: v.Salt}
+		if got := group.inRollout(v.FlagKey, &Context{Key: v.Value}); got != v.Included {
+			t.Errorf("%q, %q, %q at %v%%: target group includes the context = %t, want %t", v.FlagKey, v.Salt, v.Value, v.Percentage, got, v.Included)
+		}
+	}
+}
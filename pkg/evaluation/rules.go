@@ -0,0 +1,384 @@
+package evaluation
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Operator compares a context attribute with the values of a condition.
+type Operator string
+
+const (
+	OpEquals     Operator = "equals"
+	OpNotEquals  Operator = "not_equals"
+	OpIn         Operator = "in"
+	OpNotIn      Operator = "not_in"
+	OpContains   Operator = "contains"
+	OpStartsWith Operator = "starts_with"
+	OpEndsWith   Operator = "ends_with"
+	OpRegex      Operator = "regex"
+
+	OpGreater        Operator = "gt"
+	OpGreaterOrEqual Operator = "gte"
+	OpLess           Operator = "lt"
+	OpLessOrEqual    Operator = "lte"
+
+	OpSemverEquals         Operator = "semver_eq"
+	OpSemverGreater        Operator = "semver_gt"
+	OpSemverGreaterOrEqual Operator = "semver_gte"
+	OpSemverLess           Operator = "semver_lt"
+	OpSemverLessOrEqual    Operator = "semver_lte"
+
+	OpBefore Operator = "before"
+	OpAfter  Operator = "after"
+)
+
+// Rule is a node of the rules of a target group. A node is either a combination of child rules,
+// "and" requiring all of them to match and "or" any of them, or a condition on one attribute:
+//
+//	{"and": [
+//	  {"attribute": "country", "operator": "in", "values": ["VN", "SG"]},
+//	  {"attribute": "appVersion", "operator": "semver_gte", "values": ["2.3.0"]}
+//	]}
+//
+// Operators:
+//   - equals, not_equals, in, not_in: the attribute equals one of the values. Strings, numbers and
+//     booleans are compared by their text form, so 1 and "1" are equal. equals and not_equals take one value.
+//   - contains, starts_with, ends_with, regex: the attribute text matches any of the string values.
+//   - gt, gte, lt, lte: numeric comparison with a single number.
+//   - semver_eq, semver_gt, semver_gte, semver_lt, semver_lte: semantic version comparison with a single version.
+//   - before, after: comparison with a single RFC 3339 timestamp or YYYY-MM-DD date. Attributes may
+//     also be Unix timestamps in seconds.
+//
+// A condition on an attribute the context does not have never matches, not even a negated one.
+type Rule struct {
+	And []*Rule `json:"and,omitempty"`
+	Or  []*Rule `json:"or,omitempty"`
+
+	Attribute string   `json:"attribute,omitempty"`
+	Operator  Operator `json:"operator,omitempty"`
+	Values    []any    `json:"values,omitempty"`
+}
+
+// RuleError reports an invalid rule together with the JSON path of the offending element,
+// e.g. rules.and[1].values[0].
+type RuleError struct {
+	Path    string
+	Message string
+}
+
+func (e *RuleError) Error() string {
+	return e.Path + ": " + e.Message
+}
+
+func ruleErrorf(path, format string, args ...any) error {
+	return &RuleError{Path: path, Message: fmt.Sprintf(format, args...)}
+}
+
+// ParseRules decodes and validates the rules of a target group. Empty input and null yield a nil rule,
+// which matches every context.
+func ParseRules(data []byte) (*Rule, error) {
+	rule, _, err := parseRules(data)
+	return rule, err
+}
+
+// matcher decides whether a context belongs to a target group.
+type matcher func(ctx *Context) bool
+
+func matchAll(*Context) bool { return true }
+
+func matchNone(*Context) bool { return false }
+
+// compileRules turns the stored rules of a target group into a matcher. Rules that fail to parse
+// never match, so a group that cannot be understood never exposes a flag to an unintended audience.
+func compileRules(data []byte) matcher {
+	_, match, err := parseRules(data)
+	if err != nil {
+		return matchNone
+	}
+	return match
+}
+
+func parseRules(data []byte) (*Rule, matcher, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, matchAll, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	decoder.DisallowUnknownFields()
+
+	var rule *Rule
+	if err := decoder.Decode(&rule); err != nil {
+		return nil, nil, ruleErrorf("rules", "invalid JSON: %v", err)
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return nil, nil, ruleErrorf("rules", "invalid JSON: unexpected data after the top-level value")
+	}
+	if rule == nil {
+		return nil, matchAll, nil
+	}
+
+	match, err := rule.compile("rules")
+	if err != nil {
+		return nil, nil, err
+	}
+	return rule, match, nil
+}
+
+func (r *Rule) compile(path string) (matcher, error) {
+	if r == nil {
+		return nil, ruleErrorf(path, "must be an object")
+	}
+
+	kinds := 0
+	if r.And != nil {
+		kinds++
+	}
+	if r.Or != nil {
+		kinds++
+	}
+	if r.Attribute != "" || r.Operator != "" || r.Values != nil {
+		kinds++
+	}
+	if kinds != 1 {
+		return nil, ruleErrorf(path, `must contain exactly one of "and", "or" or a condition`)
+	}
+
+	switch {
+	case r.And != nil:
+		matchers, err := compileChildren(path+".and", r.And)
+		if err != nil {
+			return nil, err
+		}
+		return func(ctx *Context) bool {
+			for _, match := range matchers {
+				if !match(ctx) {
+					return false
+				}
+			}
+			return true
+		}, nil
+	case r.Or != nil:
+		matchers, err := compileChildren(path+".or", r.Or)
+		if err != nil {
+			return nil, err
+		}
+		return func(ctx *Context) bool {
+			for _, match := range matchers {
+				if match(ctx) {
+					return true
+				}
+			}
+			return false
+		}, nil
+	default:
+		return r.compileCondition(path)
+	}
+}
+
+func compileChildren(path string, rules []*Rule) ([]matcher, error) {
+	if len(rules) == 0 {
+		return nil, ruleErrorf(path, "must not be empty")
+	}
+	matchers := make([]matcher, 0, len(rules))
+	for i, rule := range rules {
+		match, err := rule.compile(fmt.Sprintf("%s[%d]", path, i))
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, match)
+	}
+	return matchers, nil
+}
+
+func (r *Rule) compileCondition(path string) (matcher, error) {
+	if strings.TrimSpace(r.Attribute) == "" {
+		return nil, ruleErrorf(path+".attribute", "is required")
+	}
+	if r.Operator == "" {
+		return nil, ruleErrorf(path+".operator", "is required")
+	}
+	op, ok := operators[r.Operator]
+	if !ok {
+		return nil, ruleErrorf(path+".operator", "unknown operator %q", r.Operator)
+	}
+	if len(r.Values) == 0 {
+		return nil, ruleErrorf(path+".values", "must not be empty")
+	}
+	if op.single && len(r.Values) != 1 {
+		return nil, ruleErrorf(path+".values", "operator %q takes exactly one value", r.Operator)
+	}
+
+	test, err := op.compile(path+".values", r.Values)
+	if err != nil {
+		return nil, err
+	}
+	attribute := r.Attribute
+	return func(ctx *Context) bool {
+		value, ok := ctx.Attribute(attribute)
+		return ok && test(value)
+	}, nil
+}
+
+type operator struct {
+	// single operators take exactly one value.
+	single  bool
+	compile func(path string, values []any) (func(attr any) bool, error)
+}
+
+var operators = map[Operator]operator{
+	OpEquals:     {single: true, compile: compileIn(false)},
+	OpNotEquals:  {single: true, compile: compileIn(true)},
+	OpIn:         {compile: compileIn(false)},
+	OpNotIn:      {compile: compileIn(true)},
+	OpContains:   {compile: compileText(strings.Contains)},
+	OpStartsWith: {compile: compileText(strings.HasPrefix)},
+	OpEndsWith:   {compile: compileText(strings.HasSuffix)},
+	OpRegex:      {compile: compileRegex},
+
+	OpGreater:        {single: true, compile: compileNumber(func(c int) bool { return c > 0 })},
+	OpGreaterOrEqual: {single: true, compile: compileNumber(func(c int) bool { return c >= 0 })},
+	OpLess:           {single: true, compile: compileNumber(func(c int) bool { return c < 0 })},
+	OpLessOrEqual:    {single: true, compile: compileNumber(func(c int) bool { return c <= 0 })},
+
+	OpSemverEquals:         {single: true, compile: compileSemver(func(c int) bool { return c == 0 })},
+	OpSemverGreater:        {single: true, compile: compileSemver(func(c int) bool { return c > 0 })},
+	OpSemverGreaterOrEqual: {single: true, compile: compileSemver(func(c int) bool { return c >= 0 })},
+	OpSemverLess:           {single: true, compile: compileSemver(func(c int) bool { return c < 0 })},
+	OpSemverLessOrEqual:    {single: true, compile: compileSemver(func(c int) bool { return c <= 0 })},
+
+	OpBefore: {single: true, compile: compileTime(func(c int) bool { return c < 0 })},
+	OpAfter:  {single: true, compile: compileTime(func(c int) bool { return c > 0 })},
+}
+
+func valuePath(path string, i int) string {
+	return fmt.Sprintf("%s[%d]", path, i)
+}
+
+func compileIn(negate bool) func(string, []any) (func(any) bool, error) {
+	return func(path string, values []any) (func(any) bool, error) {
+		set := make(map[string]struct{}, len(values))
+		for i, value := range values {
+			text, ok := scalarText(value)
+			if !ok {
+				return nil, ruleErrorf(valuePath(path, i), "must be a string, number or boolean")
+			}
+			set[text] = struct{}{}
+		}
+		return func(attr any) bool {
+			text, ok := scalarText(attr)
+			if !ok {
+				return false
+			}
+			_, found := set[text]
+			return found != negate
+		}, nil
+	}
+}
+
+func compileText(test func(s, value string) bool) func(string, []any) (func(any) bool, error) {
+	return func(path string, values []any) (func(any) bool, error) {
+		texts := make([]string, 0, len(values))
+		for i, value := range values {
+			text, ok := value.(string)
+			if !ok {
+				return nil, ruleErrorf(valuePath(path, i), "must be a string")
+			}
+			texts = append(texts, text)
+		}
+		return func(attr any) bool {
+			s, ok := scalarText(attr)
+			if !ok {
+				return false
+			}
+			for _, text := range texts {
+				if test(s, text) {
+					return true
+				}
+			}
+			return false
+		}, nil
+	}
+}
+
+func compileRegex(path string, values []any) (func(any) bool, error) {
+	patterns := make([]*regexp.Regexp, 0, len(values))
+	for i, value := range values {
+		text, ok := value.(string)
+		if !ok {
+			return nil, ruleErrorf(valuePath(path, i), "must be a string")
+		}
+		pattern, err := regexp.Compile(text)
+		if err != nil {
+			return nil, ruleErrorf(valuePath(path, i), "invalid regular expression: %v", err)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return func(attr any) bool {
+		s, ok := scalarText(attr)
+		if !ok {
+			return false
+		}
+		for _, pattern := range patterns {
+			if pattern.MatchString(s) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+func compileNumber(test func(c int) bool) func(string, []any) (func(any) bool, error) {
+	return func(path string, values []any) (func(any) bool, error) {
+		if _, isText := values[0].(string); isText {
+			return nil, ruleErrorf(valuePath(path, 0), "must be a number")
+		}
+		want, ok := toNumber(values[0])
+		if !ok {
+			return nil, ruleErrorf(valuePath(path, 0), "must be a number")
+		}
+		return func(attr any) bool {
+			got, ok := toNumber(attr)
+			return ok && test(compareNumbers(got, want))
+		}, nil
+	}
+}
+
+func compileSemver(test func(c int) bool) func(string, []any) (func(any) bool, error) {
+	return func(path string, values []any) (func(any) bool, error) {
+		text, _ := values[0].(string)
+		want, ok := parseVersion(text)
+		if !ok {
+			return nil, ruleErrorf(valuePath(path, 0), "must be a semantic version such as 2.3.0")
+		}
+		return func(attr any) bool {
+			text, ok := scalarText(attr)
+			if !ok {
+				return false
+			}
+			got, ok := parseVersion(text)
+			return ok && test(got.compare(want))
+		}, nil
+	}
+}
+
+func compileTime(test func(c int) bool) func(string, []any) (func(any) bool, error) {
+	return func(path string, values []any) (func(any) bool, error) {
+		text, _ := values[0].(string)
+		want, ok := parseTime(text)
+		if !ok {
+			return nil, ruleErrorf(valuePath(path, 0), "must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+		}
+		return func(attr any) bool {
+			got, ok := toTime(attr)
+			return ok && test(got.Compare(want))
+		}, nil
+	}
+}
@@ -0,0 +1,90 @@
+package evaluation
+
+import (
+	"cmp"
+	"strconv"
+	"strings"
+)
+
+// version is a semantic version. Build metadata is ignored as it does not affect precedence.
+type version struct {
+	core       [3]uint64
+	prerelease []string
+}
+
+// parseVersion parses versions such as 2.3.0, v2.3 or 2.3.0-beta.1+build.5.
+// Missing minor and patch numbers default to zero.
+func parseVersion(text string) (version, bool) {
+	var v version
+	text = strings.TrimPrefix(strings.TrimSpace(text), "v")
+	if i := strings.IndexByte(text, '+'); i >= 0 {
+		text = text[:i]
+	}
+	if i := strings.IndexByte(text, '-'); i >= 0 {
+		for _, identifier := range strings.Split(text[i+1:], ".") {
+			if identifier == "" {
+				return version{}, false
+			}
+			v.prerelease = append(v.prerelease, identifier)
+		}
+		text = text[:i]
+	}
+
+	parts := strings.Split(text, ".")
+	if len(parts) > 3 {
+		return version{}, false
+	}
+	for i, part := range parts {
+		if part == "" || strings.TrimLeft(part, "0123456789") != "" {
+			return version{}, false
+		}
+		number, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return version{}, false
+		}
+		v.core[i] = number
+	}
+	return v, true
+}
+
+// compare orders versions following semantic versioning precedence.
+func (v version) compare(other version) int {
+	for i := range v.core {
+		if c := cmp.Compare(v.core[i], other.core[i]); c != 0 {
+			return c
+		}
+	}
+
+	// A prerelease has lower precedence than the release itself.
+	switch {
+	case len(v.prerelease) == 0 && len(other.prerelease) == 0:
+		return 0
+	case len(v.prerelease) == 0:
+		return 1
+	case len(other.prerelease) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(v.prerelease) && i < len(other.prerelease); i++ {
+		if c := comparePrerelease(v.prerelease[i], other.prerelease[i]); c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(len(v.prerelease), len(other.prerelease))
+}
+
+// comparePrerelease compares numeric identifiers numerically, which sort before alphanumeric ones.
+func comparePrerelease(a, b string) int {
+	na, errA := strconv.ParseUint(a, 10, 64)
+	nb, errB := strconv.ParseUint(b, 10, 64)
+	switch {
+	case errA == nil && errB == nil:
+		return cmp.Compare(na, nb)
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
@@ -0,0 +1,84 @@
+package evaluation
+
+import (
+	"cmp"
+	"encoding/json"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// Snapshot is the complete flag configuration of one environment. The server evaluates against it,
+// and SDKs download the same structure to evaluate locally.
+type Snapshot struct {
+	ProjectID     uuid.UUID      `json:"projectId"`
+	EnvironmentID uuid.UUID      `json:"environmentId"`
+	Flags         []*Flag        `json:"flags"`
+	TargetGroups  []*TargetGroup `json:"targetGroups"`
+
+	flags map[string]*Flag
+}
+
+// Flag is a feature as seen from one environment.
+type Flag struct {
+	Key string `json:"key"`
+	// Default is the feature's default value, used when the environment has no state for it.
+	Default bool `json:"default"`
+	// Enabled is the environment-wide state, or nil when the environment does not override the default.
+	Enabled *bool `json:"enabled,omitempty"`
+	// Targets are the states for specific target groups of the environment.
+	Targets []*Target `json:"targets,omitempty"`
+}
+
+// Target is the state of a flag for the members of a target group.
+type Target struct {
+	TargetGroupID uuid.UUID `json:"targetGroupId"`
+	Enabled       bool      `json:"enabled"`
+}
+
+// TargetGroup is an audience enabled in the environment. Target groups take precedence by position:
+// when a context matches several groups with a state for the same flag, the lowest position wins.
+type TargetGroup struct {
+	ID       uuid.UUID `json:"id"`
+	Name     string    `json:"name"`
+	Position int       `json:"position"`
+	// RolloutPercentage is the share of matching contexts included, with a precision of 0.001.
+	RolloutPercentage float64 `json:"rolloutPercentage"`
+	// RolloutSalt is hashed with the flag key and bucketing value, see Bucket.
+	RolloutSalt string `json:"rolloutSalt"`
+	// BucketBy names the attribute rollouts are bucketed by; empty buckets by the context key.
+	BucketBy string          `json:"bucketBy,omitempty"`
+	Rules    json.RawMessage `json:"rules,omitempty" swaggertype:"object"`
+
+	matcher matcher
+}
+
+// Compile indexes the snapshot and prepares target group rules for evaluation.
+// It must be called after the snapshot is built or decoded and before it is evaluated.
+func (s *Snapshot) Compile() {
+	s.indexFlags()
+	compileTargetGroups(s.TargetGroups)
+}
+
+func (s *Snapshot) indexFlags() {
+	s.flags = make(map[string]*Flag, len(s.Flags))
+	for _, flag := range s.Flags {
+		s.flags[flag.Key] = flag
+	}
+}
+
+// compileTargetGroups sorts the groups by precedence and compiles their rules.
+func compileTargetGroups(groups []*TargetGroup) {
+	slices.SortStableFunc(groups, func(a, b *TargetGroup) int {
+		return cmp.Compare(a.Position, b.Position)
+	})
+	for _, group := range groups {
+		group.matcher = compileRules(group.Rules)
+	}
+}
+
+// Flag returns the flag with the given key.
+func (s *Snapshot) Flag(key string) (*Flag, bool) {
+	flag, ok := s.flags[key]
+	return flag, ok
+}
@@ -0,0 +1,92 @@
+package evaluation
+
+import (
+	"cmp"
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// scalarText returns the text form of a string, number or boolean attribute.
+// Numbers are formatted canonically so 1, 1.0 and "1" compare equal.
+func scalarText(value any) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case bool:
+		return strconv.FormatBool(v), true
+	}
+	if number, ok := toNumber(value); ok {
+		return strconv.FormatFloat(number, 'f', -1, 64), true
+	}
+	return "", false
+}
+
+// toNumber converts numeric attributes, including numbers sent as strings, to float64.
+func toNumber(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, !math.IsNaN(v)
+	case float32:
+		return float64(v), !math.IsNaN(float64(v))
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case json.Number:
+		number, err := v.Float64()
+		return number, err == nil
+	case string:
+		number, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return number, err == nil && !math.IsNaN(number)
+	}
+	return 0, false
+}
+
+func compareNumbers(a, b float64) int {
+	return cmp.Compare(a, b)
+}
+
+// parseTime accepts RFC 3339 timestamps and YYYY-MM-DD dates, the latter at midnight UTC.
+func parseTime(text string) (time.Time, bool) {
+	if t, err := time.Parse(time.RFC3339, text); err == nil {
+		return t, true
+	}
+	if t, err := time.Parse(time.DateOnly, text); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+// toTime converts time attributes: time.Time values, strings accepted by parseTime and Unix timestamps in seconds.
+func toTime(value any) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, true
+	case string:
+		return parseTime(v)
+	}
+	if seconds, ok := toNumber(value); ok {
+		whole, fraction := math.Modf(seconds)
+		return time.Unix(int64(whole), int64(fraction*1e9)), true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,7 @@
+package evaluation
+
+import "github.com/google/wire"
+
+var WireSet = wire.NewSet(
+	NewEvaluator,
+)
@@ -0,0 +1,51 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// fileMailer writes each email to a .eml file of the directory, which mail clients can open.
+type fileMailer struct {
+	dir  string
+	from *mail.Address
+}
+
+func (m *fileMailer) Send(ctx context.Context, msg *Message) error {
+	data, err := format(m.from, msg)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(m.dir, 0o700); err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(m.dir, time.Now().Format("20060102-150405")+"-*.eml")
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if _, err := file.Write(data); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "Wrote email", "to", msg.To, "subject", msg.Subject, "file", filepath.Base(file.Name()))
+	return file.Close()
+}
+
+// logMailer logs emails instead of sending them, for development.
+type logMailer struct {
+	from *mail.Address
+}
+
+func (m *logMailer) Send(ctx context.Context, msg *Message) error {
+	if _, err := mail.ParseAddress(msg.To); err != nil {
+		return fmt.Errorf("invalid recipient: %w", err)
+	}
+	slog.InfoContext(ctx, "Email not sent, the log mail driver is configured",
+		"from", m.from.String(), "to", msg.To, "subject", msg.Subject, "text", msg.Text)
+	return nil
+}
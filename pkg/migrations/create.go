@@ -0,0 +1,78 @@
+package migrations
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+)
+
+// dialects are the databases with a directory of migrations.
+var dialects = []string{"postgres", "sqlite"}
+
+var (
+	migrationName = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+	migrationFile = regexp.MustCompile(`^(\d+)_.*\.(up|down)\.sql$`)
+)
+
+// Create writes empty up and down migrations for every dialect into dir, which holds the directories
+// of the dialects, and returns their paths. Their version follows the last migration of any dialect,
+// so that versions stay the same across dialects.
+func Create(dir, name string) ([]string, error) {
+	if !migrationName.MatchString(name) {
+		return nil, fmt.Errorf("invalid migration name %q: use lowercase letters, digits and underscores", name)
+	}
+	last, err := lastVersion(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, dialect := range dialects {
+		for _, direction := range []string{"up", "down"} {
+			file := fmt.Sprintf("%06d_%s.%s.sql", last+1, name, direction)
+			paths = append(paths, filepath.Join(dir, dialect, file))
+		}
+	}
+	for i, path := range paths {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err == nil {
+			err = f.Close()
+		}
+		if err != nil {
+			// Leave no dialect without the migration.
+			for _, created := range paths[:i] {
+				_ = os.Remove(created)
+			}
+			return nil, fmt.Errorf("error creating migration: %w", err)
+		}
+	}
+	return paths, nil
+}
+
+func lastVersion(dir string) (uint64, error) {
+	var last uint64
+	for _, dialect := range dialects {
+		entries, err := os.ReadDir(filepath.Join(dir, dialect))
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, fmt.Errorf("no %s migrations in %s: run the command from the root of the repository or set --dir", dialect, dir)
+		}
+		if err != nil {
+			return 0, err
+		}
+		for _, entry := range entries {
+			match := migrationFile.FindStringSubmatch(entry.Name())
+			if match == nil {
+				continue
+			}
+			version, err := strconv.ParseUint(match[1], 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid migration %s: %w", entry.Name(), err)
+			}
+			last = max(last, version)
+		}
+	}
+	return last, nil
+}
@@ -8,6 +8,7 @@ import (
 	"flagon/pkg/database"
 	"fmt"
 	"log/slog"
+	"os"
 
 	"github.com/golang-migrate/migrate/v4"
 	migrateDb "github.com/golang-migrate/migrate/v4/database"
@@ -26,6 +27,7 @@ var sqliteFs embed.FS
 
 type Migrator struct {
 	*migrate.Migrate
+	source source.Driver
 }
 
 func NewMigrations(db *database.DB) (*Migrator, error) {
@@ -49,6 +51,7 @@ func NewMigrations(db *database.DB) (*Migrator, error) {
 	m.Log = &logger{}
 	return &Migrator{
 		Migrate: m,
+		source:  srcDriver,
 	}, nil
 }
 
@@ -66,6 +69,115 @@ func (m *Migrator) Up() error {
 	return nil
 }
 
+// Down reverts the last n migrations, or all of them when fewer were applied.
+func (m *Migrator) Down(n int) error {
+	if n < 1 {
+		return fmt.Errorf("invalid number of migrations: %d", n)
+	}
+	err := m.Migrate.Steps(-n)
+	var shortLimit migrate.ErrShortLimit
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		slog.Info("No migrations to revert")
+		return nil
+	case errors.As(err, &shortLimit):
+		slog.Info("Reverted all migrations")
+		return nil
+	case err != nil:
+		return fmt.Errorf("error reverting migrations: %w", err)
+	}
+	slog.Info("Migrations reverted successfully")
+	return nil
+}
+
+// Goto migrates up or down to the version.
+func (m *Migrator) Goto(version uint) error {
+	if err := m.exists(version); err != nil {
+		return err
+	}
+	err := m.Migrate.Migrate(version)
+	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("error migrating to version %d: %w", version, err)
+	}
+	slog.Info("Migrated to version", "version", version)
+	return nil
+}
+
+// Force sets the version without running migrations and clears the dirty flag, once a failed
+// migration was fixed by hand. Version -1 means no migration applied.
+func (m *Migrator) Force(version int) error {
+	if version < -1 {
+		return fmt.Errorf("invalid version: %d", version)
+	}
+	if version >= 0 {
+		if err := m.exists(uint(version)); err != nil {
+			return err
+		}
+	}
+	if err := m.Migrate.Force(version); err != nil {
+		return fmt.Errorf("error forcing version %d: %w", version, err)
+	}
+	slog.Info("Forced version", "version", version)
+	return nil
+}
+
+func (m *Migrator) exists(version uint) error {
+	r, _, err := m.source.ReadUp(version)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("migration %d does not exist", version)
+	}
+	if err != nil {
+		return err
+	}
+	return r.Close()
+}
+
+// Migration is a migration of the embedded sources.
+type Migration struct {
+	Version uint
+	Name    string
+}
+
+// Status is the state of the database.
+type Status struct {
+	// Version is the last migration applied, or nil when there is none.
+	Version *uint
+	// Dirty is set when the last migration failed halfway, and must be fixed with Force.
+	Dirty   bool
+	Pending []Migration
+}
+
+func (m *Migrator) Status() (*Status, error) {
+	status := &Status{}
+	version, dirty, err := m.Migrate.Version()
+	switch {
+	case errors.Is(err, migrate.ErrNilVersion):
+	case err != nil:
+		return nil, fmt.Errorf("error reading version: %w", err)
+	default:
+		status.Version = &version
+		status.Dirty = dirty
+	}
+
+	next, err := m.source.First()
+	if status.Version != nil {
+		next, err = m.source.Next(*status.Version)
+	}
+	for err == nil {
+		r, name, readErr := m.source.ReadUp(next)
+		if readErr != nil {
+			return nil, fmt.Errorf("error reading migration %d: %w", next, readErr)
+		}
+		r.Close()
+		status.Pending = append(status.Pending, Migration{Version: next, Name: name})
+		next, err = m.source.Next(next)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("error listing migrations: %w", err)
+	}
+	return status, nil
+}
+
 type logger struct {
 }
 
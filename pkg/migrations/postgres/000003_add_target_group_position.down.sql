@@ -0,0 +1 @@
+ALTER TABLE project_target_groups DROP COLUMN position;
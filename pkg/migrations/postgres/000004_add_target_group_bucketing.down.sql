@@ -0,0 +1,3 @@
+ALTER TABLE project_target_groups DROP COLUMN bucket_by;
+ALTER TABLE project_target_groups DROP COLUMN rollout_salt;
+ALTER TABLE project_target_groups ALTER COLUMN rollout_percentage TYPE INTEGER USING ROUND(rollout_percentage);
@@ -0,0 +1,2 @@
+DROP INDEX user_sso_user_id_provider_idx;
+DROP INDEX user_sso_provider_id_idx;
@@ -0,0 +1,3 @@
+-- A provider account links to a single user, and a user has at most one account per provider.
+CREATE UNIQUE INDEX user_sso_provider_id_idx ON user_sso (provider, provider_id);
+CREATE UNIQUE INDEX user_sso_user_id_provider_idx ON user_sso (user_id, provider);
@@ -0,0 +1,18 @@
+-- The TOTP authenticator of a user, pending until enabled_at is set by verifying a first code.
+CREATE TABLE user_mfa (
+    user_id UUID NOT NULL PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    secret TEXT NOT NULL,
+    enabled_at TIMESTAMP WITH TIME ZONE,
+    last_counter BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TABLE user_recovery_codes (
+    id UUID NOT NULL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    code_hash TEXT NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX user_recovery_codes_user_id_code_hash_idx ON user_recovery_codes (user_id, code_hash);
+ALTER TABLE project_groups ADD COLUMN require_mfa BOOLEAN NOT NULL DEFAULT FALSE;
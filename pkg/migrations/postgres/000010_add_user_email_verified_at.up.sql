@@ -0,0 +1,2 @@
+-- Set once the user proves the address is theirs; cleared when it changes.
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMP WITH TIME ZONE;
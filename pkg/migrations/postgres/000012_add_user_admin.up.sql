@@ -0,0 +1,4 @@
+-- Instance admins manage the users of Flagon.
+ALTER TABLE users ADD COLUMN is_admin BOOLEAN NOT NULL DEFAULT FALSE;
+-- Set while the user is disabled and cannot log in.
+ALTER TABLE users ADD COLUMN disabled_at TIMESTAMP WITH TIME ZONE;
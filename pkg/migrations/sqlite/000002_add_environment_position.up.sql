@@ -0,0 +1 @@
+ALTER TABLE project_environments ADD COLUMN position INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,7 @@
+ALTER TABLE project_target_groups ADD COLUMN position INTEGER NOT NULL DEFAULT 0;
+
+-- Target groups used to take precedence by name; keep that order.
+UPDATE project_target_groups SET position = (
+    SELECT COUNT(*) FROM project_target_groups AS other
+    WHERE other.project_id = project_target_groups.project_id AND other.name < project_target_groups.name
+);
@@ -0,0 +1,3 @@
+UPDATE project_target_groups SET rollout_percentage = CAST(ROUND(rollout_percentage) AS INTEGER);
+ALTER TABLE project_target_groups DROP COLUMN bucket_by;
+ALTER TABLE project_target_groups DROP COLUMN rollout_salt;
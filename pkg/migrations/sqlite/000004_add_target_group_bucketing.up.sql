@@ -0,0 +1,4 @@
+-- rollout_percentage keeps its INTEGER affinity: SQLite stores fractional percentages as REAL values.
+ALTER TABLE project_target_groups ADD COLUMN rollout_salt TEXT NOT NULL DEFAULT '';
+ALTER TABLE project_target_groups ADD COLUMN bucket_by TEXT NOT NULL DEFAULT '';
+UPDATE project_target_groups SET rollout_salt = id;
@@ -0,0 +1,3 @@
+ALTER TABLE project_environments DROP COLUMN protected;
+ALTER TABLE projects_users DROP COLUMN role;
+ALTER TABLE project_groups_users DROP COLUMN role;
@@ -0,0 +1,10 @@
+ALTER TABLE project_groups_users ADD COLUMN role TEXT NOT NULL DEFAULT 'viewer';
+ALTER TABLE projects_users ADD COLUMN role TEXT NOT NULL DEFAULT 'viewer';
+ALTER TABLE project_environments ADD COLUMN protected BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Members had full access before roles existed; keep them able to edit.
+UPDATE project_groups_users SET role = 'editor';
+UPDATE projects_users SET role = 'editor';
+-- Owners derive their role from owner_id; their membership row makes them admins should ownership move.
+UPDATE projects_users SET role = 'admin'
+WHERE user_id = (SELECT owner_id FROM projects WHERE projects.id = projects_users.project_id);
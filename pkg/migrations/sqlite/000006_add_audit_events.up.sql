@@ -0,0 +1,19 @@
+-- Audit events outlive what they describe, so they reference nothing.
+CREATE TABLE audit_events (
+    id TEXT NOT NULL PRIMARY KEY,
+    actor_id TEXT,
+    access_token_id TEXT,
+    request_id TEXT NOT NULL DEFAULT '',
+    action TEXT NOT NULL,
+    resource_type TEXT NOT NULL,
+    resource_id TEXT NOT NULL,
+    group_id TEXT,
+    project_id TEXT,
+    before TEXT,
+    after TEXT,
+    diff TEXT,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX audit_events_group_id_idx ON audit_events (group_id, created_at);
+CREATE INDEX audit_events_project_id_idx ON audit_events (project_id, created_at);
+CREATE INDEX audit_events_actor_id_idx ON audit_events (actor_id, created_at);
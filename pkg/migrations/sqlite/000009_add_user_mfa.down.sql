@@ -0,0 +1,3 @@
+ALTER TABLE project_groups DROP COLUMN require_mfa;
+DROP TABLE user_recovery_codes;
+DROP TABLE user_mfa;
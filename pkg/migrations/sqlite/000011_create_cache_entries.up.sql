@@ -0,0 +1,9 @@
+-- The cache of the database cache driver. expires_at is in Unix milliseconds, NULL for entries that
+-- do not expire.
+CREATE TABLE cache_entries (
+    key TEXT NOT NULL PRIMARY KEY,
+    value BLOB NOT NULL,
+    expires_at INTEGER,
+    version INTEGER NOT NULL DEFAULT 1
+);
+CREATE INDEX cache_entries_expires_at_idx ON cache_entries (expires_at);
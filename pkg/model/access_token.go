@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AccessTokenType tells what an access token is used for.
+type AccessTokenType string
+
+const (
+	// AccessTokenTypeSDK tokens are used by SDKs to evaluate the flags of one environment.
+	AccessTokenTypeSDK AccessTokenType = "sdk"
+	// AccessTokenTypePersonal tokens act on the management API on behalf of their user.
+	AccessTokenTypePersonal AccessTokenType = "personal"
+	// AccessTokenTypeService tokens act on the management API for automation such as CI pipelines.
+	AccessTokenTypeService AccessTokenType = "service"
+)
+
+// AccessToken is a long-lived credential. Token holds a hash of the secret, which is never stored.
+// The optional group, project and environment IDs restrict what the token can reach, within what its
+// user can currently reach.
+type AccessToken struct {
+	ID            uuid.UUID       `json:"id"`
+	UserID        uuid.UUID       `json:"userId"`
+	Name          string          `json:"name"`
+	Token         string          `json:"-"`
+	TokenType     AccessTokenType `json:"tokenType"`
+	GroupID       *uuid.UUID      `json:"groupId"`
+	ProjectID     *uuid.UUID      `json:"projectId"`
+	EnvironmentID *uuid.UUID      `json:"environmentId"`
+	CreatedAt     time.Time       `json:"createdAt"`
+	UpdatedAt     time.Time       `json:"updatedAt"`
+}
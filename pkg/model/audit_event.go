@@ -0,0 +1,56 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AuditAction string
+
+const (
+	AuditActionCreate AuditAction = "create"
+	AuditActionUpdate AuditAction = "update"
+	AuditActionDelete AuditAction = "delete"
+)
+
+// AuditResourceType names the kinds of resources whose changes are audited.
+type AuditResourceType string
+
+const (
+	AuditResourceProjectGroup       AuditResourceType = "project_group"
+	AuditResourceProjectGroupMember AuditResourceType = "project_group_member"
+	AuditResourceProject            AuditResourceType = "project"
+	AuditResourceProjectMember      AuditResourceType = "project_member"
+	AuditResourceEnvironment        AuditResourceType = "environment"
+	AuditResourceCategory           AuditResourceType = "category"
+	AuditResourceFeature            AuditResourceType = "feature"
+	AuditResourceFlag               AuditResourceType = "flag"
+	AuditResourceTargetGroup        AuditResourceType = "target_group"
+	AuditResourceAccessToken        AuditResourceType = "access_token"
+	// AuditResourceUserSessions records an instance admin logging a user out everywhere.
+	AuditResourceUserSessions AuditResourceType = "user_sessions"
+	// AuditResourceUser records instance admins managing users.
+	AuditResourceUser AuditResourceType = "user"
+	// AuditResourceUserPassword records an instance admin forcing a user to reset their password.
+	AuditResourceUserPassword AuditResourceType = "user_password"
+)
+
+// AuditEvent records a change made through the API. Before and After hold the resource as returned
+// by the API, and Diff the top-level fields an update changed, as {"field": {"before": x, "after": y}}.
+// GroupID and ProjectID locate the resource, so events of a group or project can be listed.
+type AuditEvent struct {
+	ID            uuid.UUID         `json:"id"`
+	ActorID       *uuid.UUID        `json:"actorId"`
+	AccessTokenID *uuid.UUID        `json:"accessTokenId"`
+	RequestID     string            `json:"requestId"`
+	Action        AuditAction       `json:"action"`
+	ResourceType  AuditResourceType `json:"resourceType"`
+	ResourceID    string            `json:"resourceId"`
+	GroupID       *uuid.UUID        `json:"groupId"`
+	ProjectID     *uuid.UUID        `json:"projectId"`
+	Before        JSONText          `json:"before" swaggertype:"object"`
+	After         JSONText          `json:"after" swaggertype:"object"`
+	Diff          JSONText          `json:"diff" swaggertype:"object"`
+	CreatedAt     time.Time         `json:"createdAt"`
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Category struct {
+	ID          uuid.UUID `json:"id"`
+	ProjectID   uuid.UUID `json:"projectId"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+func (Category) TableName() string {
+	return "project_categories"
+}
+
+// Feature is a flag definition; Key is what SDKs pass to look the flag up.
+type Feature struct {
+	ID           uuid.UUID `json:"id"`
+	ProjectID    uuid.UUID `json:"projectId"`
+	Key          string    `json:"key" gorm:"column:name"`
+	CategoryID   uuid.UUID `json:"categoryId"`
+	Description  string    `json:"description"`
+	DefaultValue bool      `json:"defaultValue"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+func (Feature) TableName() string {
+	return "project_features"
+}
@@ -0,0 +1,57 @@
+package model
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type FlagEventType string
+
+const (
+	// FlagEventTypeSnapshot carries the complete configuration of an environment.
+	FlagEventTypeSnapshot FlagEventType = "snapshot"
+	// FlagEventTypePatch carries the flags and target groups that changed.
+	FlagEventTypePatch FlagEventType = "patch"
+)
+
+// FlagEvent is a change to the flag configuration of an environment, streamed to SDKs.
+// ID orders the events of an environment and lets a client resume after it.
+type FlagEvent struct {
+	ID            string          `json:"id"`
+	EnvironmentID uuid.UUID       `json:"environmentId"`
+	Type          FlagEventType   `json:"type"`
+	Data          json.RawMessage `json:"data"`
+}
+
+// ParseFlagEventID splits an event ID of the form "<milliseconds>-<sequence>".
+func ParseFlagEventID(id string) (ms, seq uint64, ok bool) {
+	msText, seqText, found := strings.Cut(id, "-")
+	if !found {
+		return 0, 0, false
+	}
+	ms, err := strconv.ParseUint(msText, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	seq, err = strconv.ParseUint(seqText, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return ms, seq, true
+}
+
+// After reports whether the event comes after the event with the given ID.
+func (e *FlagEvent) After(id string) bool {
+	ms, seq, ok := ParseFlagEventID(e.ID)
+	if !ok {
+		return false
+	}
+	lastMs, lastSeq, ok := ParseFlagEventID(id)
+	if !ok {
+		return true
+	}
+	return ms > lastMs || ms == lastMs && seq > lastSeq
+}
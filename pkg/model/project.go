@@ -9,7 +9,7 @@ import (
 type Project struct {
 	ID          uuid.UUID     `json:"id"`
 	Slug        string        `json:"slug"`
-	GroupID     uuid.NullUUID `json:"groupId"`
+	GroupID     uuid.NullUUID `json:"groupId" swaggertype:"string"`
 	OwnerID     uuid.UUID     `json:"ownerId"`
 	Name        string        `json:"name"`
 	Description string        `json:"description"`
@@ -22,14 +22,35 @@ type ProjectEnvironment struct {
 	ProjectID   uuid.UUID `json:"projectId"`
 	Name        string    `json:"name"`
 	Description string    `json:"description"`
-	CreatedAt   time.Time `json:"createdAt"`
-	UpdatedAt   time.Time `json:"updatedAt"`
-	Project     Project   `json:"project"`
+	Position    int       `json:"position"`
+	// Protected environments only let admins change flag states.
+	Protected bool      `json:"protected"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	Project   *Project  `json:"project,omitempty"`
 }
 
 type ProjectUser struct {
 	UserID    uuid.UUID `json:"userId"`
 	ProjectID uuid.UUID `json:"projectId"`
+	Role      Role      `json:"role"`
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
 }
+
+func (ProjectUser) TableName() string {
+	return "projects_users"
+}
+
+// ProjectMembership is a project a user owns or was added to, with their role in it.
+type ProjectMembership struct {
+	Project
+	Role Role `json:"role"`
+}
+
+// ProjectMember is a user with access to a project, as listed on the project's member page.
+type ProjectMember struct {
+	User
+	Role     Role      `json:"role"`
+	JoinedAt time.Time `json:"joinedAt"`
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ProjectFeatureFlag is the state of a feature in one environment, optionally narrowed to a target group.
+type ProjectFeatureFlag struct {
+	ProjectID     uuid.UUID  `json:"projectId"`
+	FeatureID     uuid.UUID  `json:"featureId"`
+	EnvironmentID *uuid.UUID `json:"environmentId"`
+	TargetGroupID *uuid.UUID `json:"targetGroupId"`
+	Enabled       bool       `json:"enabled"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+}
+
+// ProjectTargetGroupEnvironment links a target group to an environment it applies to.
+type ProjectTargetGroupEnvironment struct {
+	ProjectID     uuid.UUID `json:"projectId"`
+	TargetGroupID uuid.UUID `json:"targetGroupId"`
+	EnvironmentID uuid.UUID `json:"environmentId"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+func (ProjectTargetGroupEnvironment) TableName() string {
+	return "project_target_group_environment"
+}
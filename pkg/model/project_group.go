@@ -13,13 +13,33 @@ type ProjectGroup struct {
 	Name        string     `json:"name"`
 	Slug        string     `json:"slug"`
 	Description string     `json:"description"`
-	CreatedAt   time.Time  `json:"createdAt"`
-	UpdatedAt   time.Time  `json:"updatedAt"`
+	// RequireMFA denies the group, its sub-groups and their projects to members without two-factor authentication.
+	RequireMFA bool      `json:"requireMfa"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
 }
 
 type ProjectGroupUser struct {
 	UserID    uuid.UUID `json:"userId"`
 	GroupID   uuid.UUID `json:"groupId"`
+	Role      Role      `json:"role"`
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
 }
+
+func (ProjectGroupUser) TableName() string {
+	return "project_groups_users"
+}
+
+// ProjectGroupMembership is a project group a user owns or was added to, with their role in it.
+type ProjectGroupMembership struct {
+	ProjectGroup
+	Role Role `json:"role"`
+}
+
+// ProjectGroupMember is a user with a role in a project group.
+type ProjectGroupMember struct {
+	User
+	Role     Role      `json:"role"`
+	JoinedAt time.Time `json:"joinedAt"`
+}
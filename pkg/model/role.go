@@ -0,0 +1,55 @@
+package model
+
+// Role is the level of access a user has to a project group or project. Roles are ordered:
+// each one includes the permissions of the roles below it.
+type Role string
+
+const (
+	RoleViewer Role = "viewer"
+	RoleEditor Role = "editor"
+	RoleAdmin  Role = "admin"
+	// RoleOwner is held by the owner of a group or project and cannot be granted through membership.
+	RoleOwner Role = "owner"
+)
+
+var roleRanks = map[Role]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleAdmin:  3,
+	RoleOwner:  4,
+}
+
+// Valid reports whether r is a known role.
+func (r Role) Valid() bool {
+	return roleRanks[r] > 0
+}
+
+// Includes reports whether r grants at least the access of other. The empty role includes nothing.
+func (r Role) Includes(other Role) bool {
+	return roleRanks[r] > 0 && roleRanks[r] >= roleRanks[other]
+}
+
+// Max returns the higher of two roles.
+func (r Role) Max(other Role) Role {
+	if roleRanks[other] > roleRanks[r] {
+		return other
+	}
+	return r
+}
+
+// Permission is an action guarded by a minimum role.
+type Permission string
+
+const (
+	// PermissionView allows reading a group or project and everything in it.
+	PermissionView Permission = "view"
+	// PermissionEdit allows changing features, categories and target groups.
+	PermissionEdit Permission = "edit"
+	// PermissionToggle allows changing flag states and target groups of an environment.
+	// Protected environments require an admin.
+	PermissionToggle Permission = "toggle"
+	// PermissionManage allows changing settings, environments and members.
+	PermissionManage Permission = "manage"
+	// PermissionOwn allows deleting and transferring ownership.
+	PermissionOwn Permission = "own"
+)
@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// Session is a login of a user, lasting until its refresh token expires or it is revoked. Its ID is
+// the family of the tokens issued by the login and its refreshes.
+type Session struct {
+	ID         string    `json:"id"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"userAgent"`
+	IssuedAt   time.Time `json:"issuedAt"`
+	LastUsedAt time.Time `json:"lastUsedAt"`
+	// Current is set on the session of the request listing the sessions.
+	Current bool `json:"current"`
+}
@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// SigningKey signs the JWTs Flagon issues. The newest key signs new tokens; older keys keep
+// verifying the tokens they signed until those have expired. ID is the kid header of the tokens.
+type SigningKey struct {
+	ID        string `json:"id"`
+	Algorithm string `json:"algorithm"`
+	// PrivateKey is PEM-encoded in PKCS #8 form. It is encrypted with auth.signingKeyEncryptionKey
+	// when that is set; otherwise anyone reading the table can issue tokens for any user.
+	PrivateKey string    `json:"-"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
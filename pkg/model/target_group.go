@@ -0,0 +1,51 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TargetGroup is a reusable audience of a project: the contexts matching Rules, narrowed to RolloutPercentage of them.
+type TargetGroup struct {
+	ID          uuid.UUID `json:"id"`
+	ProjectID   uuid.UUID `json:"projectId"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	// Position orders the groups of the project by precedence: when a context is in several groups with a
+	// state for the same flag, the group with the lowest position wins.
+	Position int `json:"position"`
+	// RolloutPercentage is the share of the contexts matching Rules that belong to the group, from 0 to 100.
+	RolloutPercentage float64 `json:"rolloutPercentage"`
+	// RolloutSalt is mixed into rollout hashing; changing it reshuffles which contexts are included.
+	RolloutSalt string `json:"rolloutSalt"`
+	// BucketBy is the context attribute rollouts are bucketed by, e.g. accountId; empty uses the context key.
+	BucketBy string `json:"bucketBy"`
+	// Rules is the JSON rule tree described by evaluation.Rule; empty when the group includes everyone.
+	Rules     JSONText  `json:"rules" swaggertype:"object"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (TargetGroup) TableName() string {
+	return "project_target_groups"
+}
+
+// JSONText is JSON stored in a TEXT column. It is embedded verbatim when marshaled, and as null when empty.
+type JSONText string
+
+func (j JSONText) MarshalJSON() ([]byte, error) {
+	if j == "" {
+		return []byte("null"), nil
+	}
+	return []byte(j), nil
+}
+
+func (j *JSONText) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*j = ""
+		return nil
+	}
+	*j = JSONText(data)
+	return nil
+}
@@ -17,4 +17,26 @@ type User struct {
 	AvatarURL string    `json:"avatarUrl"`
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
+
+	// EmailVerifiedAt is set once the user proved the email address is theirs.
+	EmailVerifiedAt *time.Time `json:"emailVerifiedAt"`
+	// IsAdmin lets the user manage the users of the instance.
+	IsAdmin bool `json:"isAdmin"`
+	// DisabledAt is set while the user is disabled and cannot log in.
+	DisabledAt *time.Time `json:"disabledAt"`
+}
+
+// UserSSO links a user to an account at a single sign-on provider. ProviderID is the subject of the
+// account at the provider.
+type UserSSO struct {
+	ID         uuid.UUID `json:"id"`
+	UserID     uuid.UUID `json:"userId"`
+	Provider   string    `json:"provider"`
+	ProviderID string    `json:"providerId"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+func (UserSSO) TableName() string {
+	return "user_sso"
 }
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserMFA is the TOTP authenticator of a user. It is pending, and not asked for at login, until a
+// first code confirms the user set it up. LastCounter is the time step of the last code accepted,
+// so no code can be used twice.
+type UserMFA struct {
+	UserID      uuid.UUID  `json:"userId" gorm:"primaryKey"`
+	Secret      string     `json:"-"`
+	EnabledAt   *time.Time `json:"enabledAt"`
+	LastCounter int64      `json:"-"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
+
+func (UserMFA) TableName() string {
+	return "user_mfa"
+}
+
+// Enabled reports whether the authenticator has been confirmed.
+func (m *UserMFA) Enabled() bool {
+	return m != nil && m.EnabledAt != nil
+}
+
+// UserRecoveryCode is a single-use code logging a user in without their authenticator. Only the
+// SHA-256 hash of the code is stored.
+type UserRecoveryCode struct {
+	ID        uuid.UUID  `json:"id"`
+	UserID    uuid.UUID  `json:"userId"`
+	CodeHash  string     `json:"-"`
+	UsedAt    *time.Time `json:"usedAt"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
@@ -0,0 +1,260 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testClientID     = "flagon"
+	testClientSecret = "s3cret"
+	testCode         = "code-1"
+	testVerifier     = "verifier-1"
+	testNonce        = "nonce-1"
+)
+
+// mockProvider is an OpenID provider serving discovery, its key set and a token endpoint that issues
+// the ID token built by idToken for testCode.
+type mockProvider struct {
+	*httptest.Server
+
+	mu sync.Mutex
+	// keys are the published signing keys by ID; signingKey is the ID the token endpoint signs with.
+	keys        map[string]*ecdsa.PrivateKey
+	signingKey  string
+	keyRequests int
+	idToken     func(issuer string) jwt.MapClaims
+}
+
+func newMockProvider(t *testing.T) *mockProvider {
+	t.Helper()
+	p := &mockProvider{keys: map[string]*ecdsa.PrivateKey{}}
+	p.addKey(t, "key-1")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(Metadata{
+			Issuer:                p.URL,
+			AuthorizationEndpoint: p.URL + "/authorize",
+			TokenEndpoint:         p.URL + "/token",
+			JWKSURI:               p.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.keyRequests++
+		var set JSONWebKeySet
+		for kid, key := range p.keys {
+			jwk, err := NewJSONWebKey(kid, "ES256", &key.PublicKey)
+			if err != nil {
+				t.Error(err)
+			}
+			set.Keys = append(set.Keys, jwk)
+		}
+		json.NewEncoder(w).Encode(set)
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		clientID, secret, _ := r.BasicAuth()
+		if err := r.ParseForm(); err != nil || clientID != testClientID || secret != testClientSecret ||
+			r.PostForm.Get("grant_type") != "authorization_code" || r.PostForm.Get("code") != testCode ||
+			CodeChallenge(r.PostForm.Get("code_verifier")) != CodeChallenge(testVerifier) {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant", "error_description": "bad code"})
+			return
+		}
+		json.NewEncoder(w).Encode(TokenResponse{AccessToken: "access", TokenType: "Bearer", IDToken: p.sign(t, p.idToken(p.URL))})
+	})
+	p.Server = httptest.NewServer(mux)
+	t.Cleanup(p.Close)
+	return p
+}
+
+func (p *mockProvider) addKey(t *testing.T, kid string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.keys[kid] = key
+	p.signingKey = kid
+}
+
+func (p *mockProvider) keyFetches() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.keyRequests
+}
+
+func (p *mockProvider) sign(t *testing.T, claims jwt.MapClaims) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	token.Header["kid"] = p.signingKey
+	signed, err := token.SignedString(p.keys[p.signingKey])
+	if err != nil {
+		t.Error(err)
+	}
+	return signed
+}
+
+func (p *mockProvider) client() *Provider {
+	return NewProvider(Config{
+		Issuer:       p.URL,
+		ClientID:     testClientID,
+		ClientSecret: testClientSecret,
+		RedirectURL:  "http://flagon.test/api/v1/oidc/callback",
+		Scopes:       []string{"openid", "email"},
+	})
+}
+
+func validClaims(issuer string) jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":            issuer,
+		"sub":            "subject-1",
+		"aud":            testClientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(5 * time.Minute).Unix(),
+		"nonce":          testNonce,
+		"email":          "ana@example.com",
+		"email_verified": true,
+	}
+}
+
+// login exchanges testCode and verifies the ID token, as the callback of a login does.
+func login(ctx context.Context, provider *Provider, code, nonce string) (*Claims, error) {
+	token, err := provider.Exchange(ctx, code, testVerifier)
+	if err != nil {
+		return nil, err
+	}
+	return provider.Verify(ctx, token.IDToken, nonce)
+}
+
+func TestLogin(t *testing.T) {
+	tests := []struct {
+		name    string
+		claims  func(claims jwt.MapClaims)
+		code    string
+		nonce   string
+		wantErr string
+	}{
+		{name: "valid"},
+		{name: "wrong nonce", nonce: "nonce-2", wantErr: "nonce does not match"},
+		{name: "wrong audience", claims: func(c jwt.MapClaims) { c["aud"] = "other-client" }, wantErr: "audience"},
+		{
+			name:    "several audiences without azp",
+			claims:  func(c jwt.MapClaims) { c["aud"] = []string{testClientID, "other-client"} },
+			wantErr: "issued to another client",
+		},
+		{
+			name:   "several audiences with azp",
+			claims: func(c jwt.MapClaims) { c["aud"] = []string{testClientID, "other-client"}; c["azp"] = testClientID },
+		},
+		{name: "wrong issuer", claims: func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }, wantErr: "issuer"},
+		{name: "expired", claims: func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-clockSkew - time.Minute).Unix() }, wantErr: "expired"},
+		{name: "expired within the clock skew", claims: func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-clockSkew / 2).Unix() }},
+		{name: "no expiry", claims: func(c jwt.MapClaims) { delete(c, "exp") }, wantErr: "exp claim is required"},
+		{name: "issued in the future", claims: func(c jwt.MapClaims) { c["iat"] = time.Now().Add(time.Hour).Unix() }, wantErr: "used before issued"},
+		{name: "no subject", claims: func(c jwt.MapClaims) { delete(c, "sub") }, wantErr: "no subject"},
+		{name: "unknown code", code: "code-2", wantErr: "invalid_grant"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := newMockProvider(t)
+			mock.idToken = func(issuer string) jwt.MapClaims {
+				claims := validClaims(issuer)
+				if tt.claims != nil {
+					tt.claims(claims)
+				}
+				return claims
+			}
+			code, nonce := testCode, testNonce
+			if tt.code != "" {
+				code = tt.code
+			}
+			if tt.nonce != "" {
+				nonce = tt.nonce
+			}
+
+			claims, err := login(context.Background(), mock.client(), code, nonce)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("login: %v, want an error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("login: %v", err)
+			}
+			if claims.Subject != "subject-1" || claims.Email != "ana@example.com" || !claims.EmailVerified {
+				t.Errorf("claims = %+v", claims)
+			}
+		})
+	}
+}
+
+func TestVerifyRefreshesKeys(t *testing.T) {
+	ctx := context.Background()
+	mock := newMockProvider(t)
+	provider := mock.client()
+	verify := func() error {
+		_, err := provider.Verify(ctx, mock.sign(t, validClaims(mock.URL)), testNonce)
+		return err
+	}
+
+	if err := verify(); err != nil {
+		t.Fatal(err)
+	}
+	if err := verify(); err != nil {
+		t.Fatal(err)
+	}
+	if mock.keyFetches() != 1 {
+		t.Errorf("keys fetched %d times for a known key, want once", mock.keyFetches())
+	}
+
+	// The provider rotates its key: the new key ID is unknown until the keys are fetched again, which
+	// happens at most once per keyRefreshInterval.
+	mock.addKey(t, "key-2")
+	if err := verify(); err == nil || !strings.Contains(err.Error(), `unknown signing key "key-2"`) {
+		t.Fatalf("verify right after a refresh: %v, want an unknown key", err)
+	}
+	if mock.keyFetches() != 1 {
+		t.Errorf("keys fetched again %d times within the refresh interval", mock.keyFetches()-1)
+	}
+
+	provider.mu.Lock()
+	provider.keysFetchedAt = provider.keysFetchedAt.Add(-keyRefreshInterval)
+	provider.mu.Unlock()
+	if err := verify(); err != nil {
+		t.Fatalf("verify after the refresh interval: %v", err)
+	}
+	if mock.keyFetches() != 2 {
+		t.Errorf("keys fetched %d times, want 2", mock.keyFetches())
+	}
+}
+
+func TestVerifyRejectsUnsignedTokens(t *testing.T) {
+	mock := newMockProvider(t)
+	token := jwt.NewWithClaims(jwt.SigningMethodNone, validClaims(mock.URL))
+	unsigned, err := token.SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mock.client().Verify(context.Background(), unsigned, testNonce); err == nil {
+		t.Error("unsigned token verified")
+	}
+}
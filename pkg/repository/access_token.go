@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"flagon/pkg/database"
+	"flagon/pkg/model"
+
+	"github.com/google/uuid"
+)
+
+type AccessTokenRepository interface {
+	Create(ctx context.Context, token *model.AccessToken) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	FindByID(ctx context.Context, userID, id uuid.UUID) (*model.AccessToken, error)
+	FindByUser(ctx context.Context, userID uuid.UUID) ([]*model.AccessToken, error)
+	FindByToken(ctx context.Context, tokenHash string) (*model.AccessToken, error)
+}
+
+type accessTokenRepository struct {
+	db *database.DB
+}
+
+func NewAccessTokenRepository(db *database.DB) AccessTokenRepository {
+	return &accessTokenRepository{db: db}
+}
+
+func (r *accessTokenRepository) Create(ctx context.Context, token *model.AccessToken) error {
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+func (r *accessTokenRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&model.AccessToken{}, "id = ?", id).Error
+}
+
+func (r *accessTokenRepository) FindByID(ctx context.Context, userID, id uuid.UUID) (*model.AccessToken, error) {
+	var token model.AccessToken
+	err := r.db.WithContext(ctx).First(&token, "user_id = ? AND id = ?", userID, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *accessTokenRepository) FindByUser(ctx context.Context, userID uuid.UUID) ([]*model.AccessToken, error) {
+	var tokens []*model.AccessToken
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("name").Find(&tokens).Error
+	if err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func (r *accessTokenRepository) FindByToken(ctx context.Context, tokenHash string) (*model.AccessToken, error) {
+	var token model.AccessToken
+	err := r.db.WithContext(ctx).First(&token, "token = ?", tokenHash).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"flagon/pkg/database"
+	"flagon/pkg/model"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditEventFilter selects audit events. Zero fields match everything.
+type AuditEventFilter struct {
+	GroupID      *uuid.UUID
+	ProjectID    *uuid.UUID
+	ActorID      *uuid.UUID
+	ResourceType model.AuditResourceType
+	ResourceID   string
+	Action       model.AuditAction
+	RequestID    string
+	Since        *time.Time
+	Until        *time.Time
+	Limit        int
+	Offset       int
+}
+
+type AuditEventRepository interface {
+	Create(ctx context.Context, event *model.AuditEvent) error
+	// Find returns the matching events, newest first.
+	Find(ctx context.Context, filter *AuditEventFilter) ([]*model.AuditEvent, error)
+}
+
+type auditEventRepository struct {
+	db *database.DB
+}
+
+func NewAuditEventRepository(db *database.DB) AuditEventRepository {
+	return &auditEventRepository{db: db}
+}
+
+func (r *auditEventRepository) Create(ctx context.Context, event *model.AuditEvent) error {
+	return r.db.WithContext(ctx).Create(event).Error
+}
+
+func (r *auditEventRepository) Find(ctx context.Context, filter *AuditEventFilter) ([]*model.AuditEvent, error) {
+	query := r.db.WithContext(ctx).Model(&model.AuditEvent{})
+	if filter.GroupID != nil {
+		query = query.Where("group_id = ?", *filter.GroupID)
+	}
+	if filter.ProjectID != nil {
+		query = query.Where("project_id = ?", *filter.ProjectID)
+	}
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.ResourceType != "" {
+		query = query.Where("resource_type = ?", filter.ResourceType)
+	}
+	if filter.ResourceID != "" {
+		query = query.Where("resource_id = ?", filter.ResourceID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.RequestID != "" {
+		query = query.Where("request_id = ?", filter.RequestID)
+	}
+	if filter.Since != nil {
+		query = query.Where("created_at >= ?", *filter.Since)
+	}
+	if filter.Until != nil {
+		query = query.Where("created_at < ?", *filter.Until)
+	}
+
+	var events []*model.AuditEvent
+	err := query.
+		Order("created_at DESC, id").
+		Limit(filter.Limit).
+		Offset(filter.Offset).
+		Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
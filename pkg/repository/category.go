@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"flagon/pkg/database"
+	"flagon/pkg/model"
+
+	"github.com/google/uuid"
+)
+
+type CategoryRepository interface {
+	Create(ctx context.Context, category *model.Category) error
+	Update(ctx context.Context, category *model.Category) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	FindByID(ctx context.Context, projectID, id uuid.UUID) (*model.Category, error)
+	FindByProject(ctx context.Context, projectID uuid.UUID) ([]*model.Category, error)
+}
+
+type categoryRepository struct {
+	db *database.DB
+}
+
+func NewCategoryRepository(db *database.DB) CategoryRepository {
+	return &categoryRepository{db: db}
+}
+
+func (r *categoryRepository) Create(ctx context.Context, category *model.Category) error {
+	return r.db.WithContext(ctx).Create(category).Error
+}
+
+func (r *categoryRepository) Update(ctx context.Context, category *model.Category) error {
+	return r.db.WithContext(ctx).Save(category).Error
+}
+
+func (r *categoryRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&model.Category{}, "id = ?", id).Error
+}
+
+func (r *categoryRepository) FindByID(ctx context.Context, projectID, id uuid.UUID) (*model.Category, error) {
+	var category model.Category
+	err := r.db.WithContext(ctx).First(&category, "project_id = ? AND id = ?", projectID, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (r *categoryRepository) FindByProject(ctx context.Context, projectID uuid.UUID) ([]*model.Category, error) {
+	var categories []*model.Category
+	err := r.db.WithContext(ctx).Where("project_id = ?", projectID).Order("name").Find(&categories).Error
+	if err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"flagon/pkg/database"
+	"flagon/pkg/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type EnvironmentRepository interface {
+	Create(ctx context.Context, env *model.ProjectEnvironment) error
+	Update(ctx context.Context, env *model.ProjectEnvironment) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	FindByID(ctx context.Context, projectID, id uuid.UUID) (*model.ProjectEnvironment, error)
+	FindByProject(ctx context.Context, projectID uuid.UUID) ([]*model.ProjectEnvironment, error)
+	UpdatePositions(ctx context.Context, projectID uuid.UUID, orderedIDs []uuid.UUID) error
+	Clone(ctx context.Context, sourceID uuid.UUID, target *model.ProjectEnvironment) error
+}
+
+type environmentRepository struct {
+	db *database.DB
+}
+
+func NewEnvironmentRepository(db *database.DB) EnvironmentRepository {
+	return &environmentRepository{db: db}
+}
+
+func (r *environmentRepository) Create(ctx context.Context, env *model.ProjectEnvironment) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(env).Error
+}
+
+func (r *environmentRepository) Update(ctx context.Context, env *model.ProjectEnvironment) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(env).Error
+}
+
+func (r *environmentRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&model.ProjectEnvironment{}, "id = ?", id).Error
+}
+
+func (r *environmentRepository) FindByID(ctx context.Context, projectID, id uuid.UUID) (*model.ProjectEnvironment, error) {
+	var env model.ProjectEnvironment
+	err := r.db.WithContext(ctx).First(&env, "project_id = ? AND id = ?", projectID, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &env, nil
+}
+
+func (r *environmentRepository) FindByProject(ctx context.Context, projectID uuid.UUID) ([]*model.ProjectEnvironment, error) {
+	var envs []*model.ProjectEnvironment
+	err := r.db.WithContext(ctx).
+		Where("project_id = ?", projectID).
+		Order("position, name").
+		Find(&envs).Error
+	if err != nil {
+		return nil, err
+	}
+	return envs, nil
+}
+
+// UpdatePositions stores the index of every environment in orderedIDs as its position.
+func (r *environmentRepository) UpdatePositions(ctx context.Context, projectID uuid.UUID, orderedIDs []uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for position, id := range orderedIDs {
+			err := tx.Model(&model.ProjectEnvironment{}).
+				Where("project_id = ? AND id = ?", projectID, id).
+				Updates(map[string]any{"position": position, "updated_at": time.Now()}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Clone creates target and copies every feature flag and target group link of the source environment into it.
+func (r *environmentRepository) Clone(ctx context.Context, sourceID uuid.UUID, target *model.ProjectEnvironment) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(target).Error; err != nil {
+			return err
+		}
+
+		var flags []*model.ProjectFeatureFlag
+		if err := tx.Where("environment_id = ?", sourceID).Find(&flags).Error; err != nil {
+			return err
+		}
+		for _, flag := range flags {
+			flag.EnvironmentID = &target.ID
+			flag.CreatedAt, flag.UpdatedAt = time.Time{}, time.Time{}
+		}
+		if len(flags) > 0 {
+			if err := tx.Create(&flags).Error; err != nil {
+				return err
+			}
+		}
+
+		var links []*model.ProjectTargetGroupEnvironment
+		if err := tx.Where("environment_id = ?", sourceID).Find(&links).Error; err != nil {
+			return err
+		}
+		for _, link := range links {
+			link.EnvironmentID = target.ID
+			link.CreatedAt, link.UpdatedAt = time.Time{}, time.Time{}
+		}
+		if len(links) > 0 {
+			if err := tx.Create(&links).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
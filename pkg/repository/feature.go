@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"flagon/pkg/database"
+	"flagon/pkg/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type FeatureRepository interface {
+	Create(ctx context.Context, feature *model.Feature) error
+	Update(ctx context.Context, feature *model.Feature) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	FindByKey(ctx context.Context, projectID uuid.UUID, key string) (*model.Feature, error)
+	FindByProject(ctx context.Context, projectID uuid.UUID, categoryID *uuid.UUID) ([]*model.Feature, error)
+	FindFlags(ctx context.Context, featureID uuid.UUID) ([]*model.ProjectFeatureFlag, error)
+	FindFlag(ctx context.Context, featureID, environmentID uuid.UUID, targetGroupID *uuid.UUID) (*model.ProjectFeatureFlag, error)
+	SaveFlag(ctx context.Context, flag *model.ProjectFeatureFlag) error
+	DeleteFlag(ctx context.Context, featureID, environmentID uuid.UUID, targetGroupID *uuid.UUID) error
+}
+
+type featureRepository struct {
+	db *database.DB
+}
+
+func NewFeatureRepository(db *database.DB) FeatureRepository {
+	return &featureRepository{db: db}
+}
+
+func (r *featureRepository) Create(ctx context.Context, feature *model.Feature) error {
+	return r.db.WithContext(ctx).Create(feature).Error
+}
+
+func (r *featureRepository) Update(ctx context.Context, feature *model.Feature) error {
+	return r.db.WithContext(ctx).Save(feature).Error
+}
+
+func (r *featureRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&model.Feature{}, "id = ?", id).Error
+}
+
+func (r *featureRepository) FindByKey(ctx context.Context, projectID uuid.UUID, key string) (*model.Feature, error) {
+	var feature model.Feature
+	err := r.db.WithContext(ctx).Where("project_id = ? AND name = ?", projectID, key).First(&feature).Error
+	if err != nil {
+		return nil, err
+	}
+	return &feature, nil
+}
+
+func (r *featureRepository) FindByProject(ctx context.Context, projectID uuid.UUID, categoryID *uuid.UUID) ([]*model.Feature, error) {
+	var features []*model.Feature
+	query := r.db.WithContext(ctx).Where("project_id = ?", projectID)
+	if categoryID != nil {
+		query = query.Where("category_id = ?", *categoryID)
+	}
+	err := query.Order("name").Find(&features).Error
+	if err != nil {
+		return nil, err
+	}
+	return features, nil
+}
+
+func (r *featureRepository) FindFlags(ctx context.Context, featureID uuid.UUID) ([]*model.ProjectFeatureFlag, error) {
+	var flags []*model.ProjectFeatureFlag
+	err := r.db.WithContext(ctx).Where("feature_id = ?", featureID).Find(&flags).Error
+	if err != nil {
+		return nil, err
+	}
+	return flags, nil
+}
+
+func (r *featureRepository) FindFlag(ctx context.Context, featureID, environmentID uuid.UUID, targetGroupID *uuid.UUID) (*model.ProjectFeatureFlag, error) {
+	var flag model.ProjectFeatureFlag
+	err := flagQuery(r.db.WithContext(ctx), featureID, environmentID, targetGroupID).Take(&flag).Error
+	if err != nil {
+		return nil, err
+	}
+	return &flag, nil
+}
+
+// SaveFlag inserts or updates the flag state for its (feature, environment, target group) combination.
+// The target group column is nullable, so the row is matched explicitly instead of relying on an upsert.
+func (r *featureRepository) SaveFlag(ctx context.Context, flag *model.ProjectFeatureFlag) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := flagQuery(tx.Model(&model.ProjectFeatureFlag{}), flag.FeatureID, *flag.EnvironmentID, flag.TargetGroupID).
+			Updates(map[string]any{"enabled": flag.Enabled, "updated_at": tx.NowFunc()})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return tx.Create(flag).Error
+		}
+		return flagQuery(tx, flag.FeatureID, *flag.EnvironmentID, flag.TargetGroupID).First(flag).Error
+	})
+}
+
+func (r *featureRepository) DeleteFlag(ctx context.Context, featureID, environmentID uuid.UUID, targetGroupID *uuid.UUID) error {
+	return flagQuery(r.db.WithContext(ctx), featureID, environmentID, targetGroupID).
+		Delete(&model.ProjectFeatureFlag{}).Error
+}
+
+func flagQuery(db *gorm.DB, featureID, environmentID uuid.UUID, targetGroupID *uuid.UUID) *gorm.DB {
+	query := db.Where("feature_id = ? AND environment_id = ?", featureID, environmentID)
+	if targetGroupID == nil {
+		return query.Where("target_group_id IS NULL")
+	}
+	return query.Where("target_group_id = ?", *targetGroupID)
+}
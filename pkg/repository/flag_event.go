@@ -0,0 +1,259 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"flagon/pkg/cache"
+	"flagon/pkg/model"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	flagEventChannel = "sdk:flag-events"
+	// flagEventRetention is roughly how many events of an environment can be replayed to a resuming client.
+	flagEventRetention = 1000
+	// flagEventTTL drops the event log of environments that have not changed for a while.
+	flagEventTTL = 7 * 24 * time.Hour
+	// flagEventListenerBuffer is how many events a listener of the in-memory log can fall behind.
+	flagEventListenerBuffer = 100
+)
+
+// FlagEventRepository keeps a short log of flag events per environment and broadcasts new events to
+// every server instance.
+type FlagEventRepository interface {
+	// Append stores the event, setting its ID, and broadcasts it.
+	Append(ctx context.Context, event *model.FlagEvent) error
+	// Latest returns the ID of the last event of the environment, or "0-0" when it has none.
+	Latest(ctx context.Context, envID uuid.UUID) (string, error)
+	// Since returns the events that followed the one with the given ID. It reports false when that
+	// event is no longer in the log, in which case the caller has to start over from a snapshot.
+	Since(ctx context.Context, envID uuid.UUID, lastID string) ([]*model.FlagEvent, bool, error)
+	// Listen receives the events broadcast by every instance until the context is done.
+	Listen(ctx context.Context) (<-chan *model.FlagEvent, error)
+}
+
+// redisFlagEventRepo keeps the log in Redis streams and broadcasts through Redis pub/sub.
+type redisFlagEventRepo struct {
+	client *redis.Client
+}
+
+// NewFlagEventRepository shares the events through Redis when it is the cache. Other caches cannot
+// broadcast, so the events then stay in the memory of the instance.
+func NewFlagEventRepository(c cache.Cache) FlagEventRepository {
+	if redisCache, ok := c.(*cache.RedisCache); ok {
+		return &redisFlagEventRepo{
+			client: redisCache.Client,
+		}
+	}
+	return newMemoryFlagEventRepo()
+}
+
+func (r *redisFlagEventRepo) Append(ctx context.Context, event *model.FlagEvent) error {
+	key := flagEventKey(event.EnvironmentID)
+
+	pipe := r.client.TxPipeline()
+	add := pipe.XAdd(ctx, &redis.XAddArgs{
+		Stream: key,
+		MaxLen: flagEventRetention,
+		Approx: true,
+		Values: map[string]any{"type": string(event.Type), "data": string(event.Data)},
+	})
+	pipe.Expire(ctx, key, flagEventTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+	event.ID = add.Val()
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return r.client.Publish(ctx, flagEventChannel, payload).Err()
+}
+
+func (r *redisFlagEventRepo) Latest(ctx context.Context, envID uuid.UUID) (string, error) {
+	messages, err := r.client.XRevRangeN(ctx, flagEventKey(envID), "+", "-", 1).Result()
+	if err != nil {
+		return "", err
+	}
+	if len(messages) == 0 {
+		return "0-0", nil
+	}
+	return messages[0].ID, nil
+}
+
+func (r *redisFlagEventRepo) Since(ctx context.Context, envID uuid.UUID, lastID string) ([]*model.FlagEvent, bool, error) {
+	if _, _, ok := model.ParseFlagEventID(lastID); !ok {
+		return nil, false, nil
+	}
+	// The range includes lastID itself, which proves the log still reaches back to it.
+	messages, err := r.client.XRange(ctx, flagEventKey(envID), lastID, "+").Result()
+	if err != nil {
+		return nil, false, err
+	}
+	if len(messages) == 0 || messages[0].ID != lastID {
+		return nil, false, nil
+	}
+
+	events := make([]*model.FlagEvent, 0, len(messages)-1)
+	for _, message := range messages[1:] {
+		eventType, _ := message.Values["type"].(string)
+		data, _ := message.Values["data"].(string)
+		events = append(events, &model.FlagEvent{
+			ID:            message.ID,
+			EnvironmentID: envID,
+			Type:          model.FlagEventType(eventType),
+			Data:          json.RawMessage(data),
+		})
+	}
+	return events, true, nil
+}
+
+func (r *redisFlagEventRepo) Listen(ctx context.Context) (<-chan *model.FlagEvent, error) {
+	pubsub := r.client.Subscribe(ctx, flagEventChannel)
+	// Wait for the subscription to be confirmed so no event published afterwards is missed.
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		return nil, err
+	}
+
+	events := make(chan *model.FlagEvent)
+	go func() {
+		defer close(events)
+		defer pubsub.Close()
+
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case message, ok := <-messages:
+				if !ok {
+					return
+				}
+				var event model.FlagEvent
+				if err := json.Unmarshal([]byte(message.Payload), &event); err != nil {
+					slog.Warn("Discarding malformed flag event", "error", err)
+					continue
+				}
+				select {
+				case events <- &event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return events, nil
+}
+
+func flagEventKey(envID uuid.UUID) string {
+	return fmt.Sprintf("sdk:environment:%s:flag-events", envID.String())
+}
+
+// memoryFlagEventRepo keeps the log in memory and broadcasts to the listeners of the instance.
+type memoryFlagEventRepo struct {
+	mu   sync.Mutex
+	logs map[uuid.UUID][]*model.FlagEvent
+	// lastMs and seq make IDs like those of Redis streams: "<milliseconds>-<sequence>", increasing.
+	lastMs    int64
+	seq       uint64
+	listeners map[chan *model.FlagEvent]struct{}
+}
+
+func newMemoryFlagEventRepo() *memoryFlagEventRepo {
+	return &memoryFlagEventRepo{
+		logs:      make(map[uuid.UUID][]*model.FlagEvent),
+		listeners: make(map[chan *model.FlagEvent]struct{}),
+	}
+}
+
+func (r *memoryFlagEventRepo) Append(_ context.Context, event *model.FlagEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ms := time.Now().UnixMilli()
+	if ms > r.lastMs {
+		r.lastMs, r.seq = ms, 0
+	} else {
+		r.seq++
+	}
+	event.ID = strconv.FormatInt(r.lastMs, 10) + "-" + strconv.FormatUint(r.seq, 10)
+
+	log := append(r.logs[event.EnvironmentID], event)
+	if len(log) > flagEventRetention {
+		log = slices.Clone(log[len(log)-flagEventRetention:])
+	}
+	r.logs[event.EnvironmentID] = log
+
+	for listener := range r.listeners {
+		select {
+		case listener <- event:
+		default:
+			// Like Redis pub/sub, a listener that falls too far behind misses events.
+			slog.Warn("Dropping flag event for a slow listener", "event_id", event.ID)
+		}
+	}
+	return nil
+}
+
+func (r *memoryFlagEventRepo) Latest(_ context.Context, envID uuid.UUID) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	log := r.logs[envID]
+	if len(log) == 0 {
+		return "0-0", nil
+	}
+	return log[len(log)-1].ID, nil
+}
+
+func (r *memoryFlagEventRepo) Since(_ context.Context, envID uuid.UUID, lastID string) ([]*model.FlagEvent, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	log := r.logs[envID]
+	i := slices.IndexFunc(log, func(event *model.FlagEvent) bool {
+		return event.ID == lastID
+	})
+	if i < 0 {
+		return nil, false, nil
+	}
+	return slices.Clone(log[i+1:]), true, nil
+}
+
+func (r *memoryFlagEventRepo) Listen(ctx context.Context) (<-chan *model.FlagEvent, error) {
+	listener := make(chan *model.FlagEvent, flagEventListenerBuffer)
+	r.mu.Lock()
+	r.listeners[listener] = struct{}{}
+	r.mu.Unlock()
+
+	events := make(chan *model.FlagEvent)
+	go func() {
+		defer close(events)
+		defer func() {
+			r.mu.Lock()
+			delete(r.listeners, listener)
+			r.mu.Unlock()
+		}()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event := <-listener:
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return events, nil
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"flagon/pkg/cache"
+	"strconv"
+	"time"
+)
+
+// LoginAttemptRepository counts the failed password logins of usernames, to lock accounts out after
+// too many of them.
+type LoginAttemptRepository interface {
+	// Fail counts a failed login, returning the number of failures in a row. The count is forgotten
+	// after the expiration without failures.
+	Fail(ctx context.Context, username string, expiration time.Duration) (int64, error)
+	Lock(ctx context.Context, username string, duration time.Duration) error
+	// LockedFor returns how long the username stays locked, or 0 when it is not locked.
+	LockedFor(ctx context.Context, username string) (time.Duration, error)
+	// Reset forgets the failures after a successful login.
+	Reset(ctx context.Context, username string) error
+}
+
+type loginAttemptRepo struct {
+	cache cache.Cache
+}
+
+func NewLoginAttemptRepository(cache cache.Cache) LoginAttemptRepository {
+	return &loginAttemptRepo{cache: cache}
+}
+
+func loginFailuresKey(username string) string {
+	return "login:failures:" + username
+}
+
+func loginLockKey(username string) string {
+	return "login:lock:" + username
+}
+
+func (r *loginAttemptRepo) Fail(ctx context.Context, username string, expiration time.Duration) (int64, error) {
+	var failures int64
+	err := r.cache.Update(ctx, loginFailuresKey(username), func(value []byte, _ time.Duration) ([]byte, time.Duration, error) {
+		// Each failure extends the expiration, unlike cache.Increment.
+		failures, _ = strconv.ParseInt(string(value), 10, 64)
+		failures++
+		return strconv.AppendInt(nil, failures, 10), expiration, nil
+	})
+	return failures, err
+}
+
+func (r *loginAttemptRepo) Lock(ctx context.Context, username string, duration time.Duration) error {
+	return r.cache.Set(ctx, loginLockKey(username), []byte("1"), duration)
+}
+
+func (r *loginAttemptRepo) LockedFor(ctx context.Context, username string) (time.Duration, error) {
+	ttl, err := r.cache.TTL(ctx, loginLockKey(username))
+	if errors.Is(err, cache.ErrMiss) {
+		return 0, nil
+	}
+	return ttl, err
+}
+
+func (r *loginAttemptRepo) Reset(ctx context.Context, username string) error {
+	_, err := r.cache.Delete(ctx, loginFailuresKey(username), loginLockKey(username))
+	return err
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flagon/pkg/cache"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MFAChallengeRepository keeps the logins waiting for a second factor, between the password step
+// and the code step.
+type MFAChallengeRepository interface {
+	Save(ctx context.Context, token string, userID uuid.UUID, expiration time.Duration) error
+	// Find returns the user of the challenge, or nil for unknown or expired challenges.
+	Find(ctx context.Context, token string) (*uuid.UUID, error)
+	// Fail counts a wrong code, returning the number of wrong codes so far, or 0 when the challenge
+	// has expired.
+	Fail(ctx context.Context, token string) (int64, error)
+	// Delete ends the challenge, reporting whether it still existed, so it can only be completed once.
+	Delete(ctx context.Context, token string) (bool, error)
+}
+
+type mfaChallenge struct {
+	UserID   uuid.UUID `json:"userId"`
+	Failures int64     `json:"failures"`
+}
+
+type mfaChallengeRepo struct {
+	cache cache.Cache
+}
+
+func NewMFAChallengeRepository(cache cache.Cache) MFAChallengeRepository {
+	return &mfaChallengeRepo{cache: cache}
+}
+
+func mfaChallengeKey(token string) string {
+	return "mfa:challenges:" + token
+}
+
+func (r *mfaChallengeRepo) Save(ctx context.Context, token string, userID uuid.UUID, expiration time.Duration) error {
+	data, err := json.Marshal(&mfaChallenge{UserID: userID})
+	if err != nil {
+		return err
+	}
+	return r.cache.Set(ctx, mfaChallengeKey(token), data, expiration)
+}
+
+func (r *mfaChallengeRepo) Find(ctx context.Context, token string) (*uuid.UUID, error) {
+	data, err := r.cache.Get(ctx, mfaChallengeKey(token))
+	if errors.Is(err, cache.ErrMiss) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var challenge mfaChallenge
+	if err := json.Unmarshal(data, &challenge); err != nil {
+		return nil, err
+	}
+	return &challenge.UserID, nil
+}
+
+func (r *mfaChallengeRepo) Fail(ctx context.Context, token string) (int64, error) {
+	var failures int64
+	err := r.cache.Update(ctx, mfaChallengeKey(token), func(data []byte, ttl time.Duration) ([]byte, time.Duration, error) {
+		// An expired challenge is not recreated.
+		if data == nil {
+			return nil, 0, nil
+		}
+		var challenge mfaChallenge
+		if err := json.Unmarshal(data, &challenge); err != nil {
+			return nil, 0, err
+		}
+		challenge.Failures++
+		failures = challenge.Failures
+		data, err := json.Marshal(&challenge)
+		return data, ttl, err
+	})
+	return failures, err
+}
+
+func (r *mfaChallengeRepo) Delete(ctx context.Context, token string) (bool, error) {
+	deleted, err := r.cache.Delete(ctx, mfaChallengeKey(token))
+	return deleted > 0, err
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flagon/pkg/cache"
+	"time"
+)
+
+// OIDCLoginState is what Flagon keeps between sending a user to the identity provider and the
+// provider sending them back.
+type OIDCLoginState struct {
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"codeVerifier"`
+}
+
+type OIDCStateRepository interface {
+	Save(ctx context.Context, state string, login *OIDCLoginState, expiration time.Duration) error
+	// Take returns and deletes the login state, so it can only be used once. It returns nil for
+	// unknown or expired states.
+	Take(ctx context.Context, state string) (*OIDCLoginState, error)
+}
+
+type oidcStateRepo struct {
+	cache cache.Cache
+}
+
+func NewOIDCStateRepository(cache cache.Cache) OIDCStateRepository {
+	return &oidcStateRepo{cache: cache}
+}
+
+func (r *oidcStateRepo) Save(ctx context.Context, state string, login *OIDCLoginState, expiration time.Duration) error {
+	data, err := json.Marshal(login)
+	if err != nil {
+		return err
+	}
+	return r.cache.Set(ctx, "oidc:state:"+state, data, expiration)
+}
+
+func (r *oidcStateRepo) Take(ctx context.Context, state string) (*OIDCLoginState, error) {
+	data, err := r.cache.Take(ctx, "oidc:state:"+state)
+	if errors.Is(err, cache.ErrMiss) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var login OIDCLoginState
+	if err := json.Unmarshal(data, &login); err != nil {
+		return nil, err
+	}
+	return &login, nil
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"flagon/pkg/cache"
+	"time"
+)
+
+// OneTimeTokenRepository makes signed tokens single-use, such as the links of password reset emails.
+type OneTimeTokenRepository interface {
+	Add(ctx context.Context, jti string, expiration time.Duration) error
+	// Use consumes the token, reporting false when it was used already or has expired.
+	Use(ctx context.Context, jti string) (bool, error)
+}
+
+type oneTimeTokenRepo struct {
+	cache cache.Cache
+}
+
+func NewOneTimeTokenRepository(cache cache.Cache) OneTimeTokenRepository {
+	return &oneTimeTokenRepo{cache: cache}
+}
+
+func oneTimeTokenKey(jti string) string {
+	return "one-time-token:" + jti
+}
+
+func (r *oneTimeTokenRepo) Add(ctx context.Context, jti string, expiration time.Duration) error {
+	return r.cache.Set(ctx, oneTimeTokenKey(jti), []byte("1"), expiration)
+}
+
+func (r *oneTimeTokenRepo) Use(ctx context.Context, jti string) (bool, error) {
+	deleted, err := r.cache.Delete(ctx, oneTimeTokenKey(jti))
+	return deleted > 0, err
+}
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"flagon/pkg/database"
+	"flagon/pkg/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ProjectRepository interface {
+	Create(ctx context.Context, project *model.Project) error
+	Update(ctx context.Context, project *model.Project) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	FindByID(ctx context.Context, id uuid.UUID) (*model.Project, error)
+	FindBySlug(ctx context.Context, slug string) (*model.Project, error)
+	FindVisible(ctx context.Context, userID uuid.UUID, groupIDs []uuid.UUID) ([]*model.Project, error)
+	AddMember(ctx context.Context, member *model.ProjectUser) error
+	RemoveMember(ctx context.Context, projectID, userID uuid.UUID) error
+	UpdateMemberRole(ctx context.Context, projectID, userID uuid.UUID, role model.Role) error
+	IsMember(ctx context.Context, projectID, userID uuid.UUID) (bool, error)
+	FindMemberRole(ctx context.Context, projectID, userID uuid.UUID) (model.Role, error)
+	FindMembers(ctx context.Context, projectID uuid.UUID) ([]*model.ProjectMember, error)
+	// FindMemberships returns the projects the user owns or has been added to directly, with the
+	// role of their membership.
+	FindMemberships(ctx context.Context, userID uuid.UUID) ([]*model.ProjectMembership, error)
+}
+
+type projectRepository struct {
+	db *database.DB
+}
+
+func NewProjectRepository(db *database.DB) ProjectRepository {
+	return &projectRepository{db: db}
+}
+
+// Create inserts the project and registers its owner as the first member, with the admin role
+// they keep should ownership be transferred.
+func (r *projectRepository) Create(ctx context.Context, project *model.Project) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(project).Error; err != nil {
+			return err
+		}
+		return tx.Create(&model.ProjectUser{UserID: project.OwnerID, ProjectID: project.ID, Role: model.RoleAdmin}).Error
+	})
+}
+
+func (r *projectRepository) Update(ctx context.Context, project *model.Project) error {
+	return r.db.WithContext(ctx).Save(project).Error
+}
+
+func (r *projectRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&model.Project{}, "id = ?", id).Error
+}
+
+func (r *projectRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.Project, error) {
+	var project model.Project
+	err := r.db.WithContext(ctx).First(&project, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+func (r *projectRepository) FindBySlug(ctx context.Context, slug string) (*model.Project, error) {
+	var project model.Project
+	err := r.db.WithContext(ctx).Where("slug = ?", slug).First(&project).Error
+	if err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+// FindVisible returns the projects the user owns or is a member of, plus every project in the given groups.
+func (r *projectRepository) FindVisible(ctx context.Context, userID uuid.UUID, groupIDs []uuid.UUID) ([]*model.Project, error) {
+	var projects []*model.Project
+	members := r.db.Model(&model.ProjectUser{}).Select("project_id").Where("user_id = ?", userID)
+	query := r.db.WithContext(ctx).Where("owner_id = ? OR id IN (?)", userID, members)
+	if len(groupIDs) > 0 {
+		query = query.Or("group_id IN ?", groupIDs)
+	}
+	err := query.Order("name").Find(&projects).Error
+	if err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+func (r *projectRepository) AddMember(ctx context.Context, member *model.ProjectUser) error {
+	return r.db.WithContext(ctx).Create(member).Error
+}
+
+func (r *projectRepository) RemoveMember(ctx context.Context, projectID, userID uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Delete(&model.ProjectUser{}, "project_id = ? AND user_id = ?", projectID, userID).Error
+}
+
+func (r *projectRepository) UpdateMemberRole(ctx context.Context, projectID, userID uuid.UUID, role model.Role) error {
+	result := r.db.WithContext(ctx).Model(&model.ProjectUser{}).
+		Where("project_id = ? AND user_id = ?", projectID, userID).
+		Updates(map[string]any{"role": role, "updated_at": r.db.NowFunc()})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *projectRepository) IsMember(ctx context.Context, projectID, userID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.ProjectUser{}).
+		Where("project_id = ? AND user_id = ?", projectID, userID).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// FindMemberRole returns the role of the user's membership, or the empty role when they are not a member.
+func (r *projectRepository) FindMemberRole(ctx context.Context, projectID, userID uuid.UUID) (model.Role, error) {
+	var roles []model.Role
+	err := r.db.WithContext(ctx).Model(&model.ProjectUser{}).
+		Where("project_id = ? AND user_id = ?", projectID, userID).
+		Limit(1).
+		Pluck("role", &roles).Error
+	if err != nil || len(roles) == 0 {
+		return "", err
+	}
+	return roles[0], nil
+}
+
+func (r *projectRepository) FindMembers(ctx context.Context, projectID uuid.UUID) ([]*model.ProjectMember, error) {
+	var members []*model.ProjectMember
+	err := r.db.WithContext(ctx).
+		Table("users").
+		Select("users.*, projects_users.role, projects_users.created_at AS joined_at").
+		Joins("JOIN projects_users ON projects_users.user_id = users.id").
+		Where("projects_users.project_id = ?", projectID).
+		Order("users.username").
+		Scan(&members).Error
+	if err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+func (r *projectRepository) FindMemberships(ctx context.Context, userID uuid.UUID) ([]*model.ProjectMembership, error) {
+	var memberships []*model.ProjectMembership
+	err := r.db.WithContext(ctx).
+		Table("projects").
+		Select("projects.*, COALESCE(projects_users.role, '') AS role").
+		Joins("LEFT JOIN projects_users ON projects_users.project_id = projects.id AND projects_users.user_id = ?", userID).
+		Where("projects.owner_id = ? OR projects_users.user_id IS NOT NULL", userID).
+		Order("projects.name").
+		Find(&memberships).Error
+	if err != nil {
+		return nil, err
+	}
+	return memberships, nil
+}
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"flagon/pkg/database"
+	"flagon/pkg/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ProjectGroupRepository interface {
+	Create(ctx context.Context, group *model.ProjectGroup) error
+	Update(ctx context.Context, group *model.ProjectGroup) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	FindByID(ctx context.Context, id uuid.UUID) (*model.ProjectGroup, error)
+	FindBySlug(ctx context.Context, slug string) (*model.ProjectGroup, error)
+	FindAll(ctx context.Context) ([]*model.ProjectGroup, error)
+	FindByMember(ctx context.Context, userID uuid.UUID) ([]*model.ProjectGroup, error)
+	AddMember(ctx context.Context, member *model.ProjectGroupUser) error
+	UpdateMemberRole(ctx context.Context, groupID, userID uuid.UUID, role model.Role) error
+	RemoveMember(ctx context.Context, groupID, userID uuid.UUID) error
+	FindMemberRole(ctx context.Context, groupID, userID uuid.UUID) (model.Role, error)
+	FindMembers(ctx context.Context, groupID uuid.UUID) ([]*model.ProjectGroupMember, error)
+	// FindMemberships returns the groups the user owns or has been added to directly, with the role
+	// of their membership. Owners without a membership have the empty role.
+	FindMemberships(ctx context.Context, userID uuid.UUID) ([]*model.ProjectGroupMembership, error)
+}
+
+type projectGroupRepository struct {
+	db *database.DB
+}
+
+func NewProjectGroupRepository(db *database.DB) ProjectGroupRepository {
+	return &projectGroupRepository{db: db}
+}
+
+func (r *projectGroupRepository) Create(ctx context.Context, group *model.ProjectGroup) error {
+	return r.db.WithContext(ctx).Create(group).Error
+}
+
+func (r *projectGroupRepository) Update(ctx context.Context, group *model.ProjectGroup) error {
+	return r.db.WithContext(ctx).Save(group).Error
+}
+
+func (r *projectGroupRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&model.ProjectGroup{}, "id = ?", id).Error
+}
+
+func (r *projectGroupRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.ProjectGroup, error) {
+	var group model.ProjectGroup
+	err := r.db.WithContext(ctx).First(&group, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+func (r *projectGroupRepository) FindBySlug(ctx context.Context, slug string) (*model.ProjectGroup, error) {
+	var group model.ProjectGroup
+	err := r.db.WithContext(ctx).Where("slug = ?", slug).First(&group).Error
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+func (r *projectGroupRepository) FindAll(ctx context.Context) ([]*model.ProjectGroup, error) {
+	var groups []*model.ProjectGroup
+	err := r.db.WithContext(ctx).Order("name").Find(&groups).Error
+	if err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// FindByMember returns the groups the user owns or has been added to directly.
+func (r *projectGroupRepository) FindByMember(ctx context.Context, userID uuid.UUID) ([]*model.ProjectGroup, error) {
+	var groups []*model.ProjectGroup
+	members := r.db.Model(&model.ProjectGroupUser{}).Select("group_id").Where("user_id = ?", userID)
+	err := r.db.WithContext(ctx).
+		Where("owner_id = ? OR id IN (?)", userID, members).
+		Order("name").
+		Find(&groups).Error
+	if err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+func (r *projectGroupRepository) AddMember(ctx context.Context, member *model.ProjectGroupUser) error {
+	return r.db.WithContext(ctx).Create(member).Error
+}
+
+func (r *projectGroupRepository) UpdateMemberRole(ctx context.Context, groupID, userID uuid.UUID, role model.Role) error {
+	result := r.db.WithContext(ctx).Model(&model.ProjectGroupUser{}).
+		Where("group_id = ? AND user_id = ?", groupID, userID).
+		Updates(map[string]any{"role": role, "updated_at": r.db.NowFunc()})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *projectGroupRepository) RemoveMember(ctx context.Context, groupID, userID uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Delete(&model.ProjectGroupUser{}, "group_id = ? AND user_id = ?", groupID, userID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// FindMemberRole returns the role of the user's direct membership, or the empty role when they are not a member.
+func (r *projectGroupRepository) FindMemberRole(ctx context.Context, groupID, userID uuid.UUID) (model.Role, error) {
+	var roles []model.Role
+	err := r.db.WithContext(ctx).Model(&model.ProjectGroupUser{}).
+		Where("group_id = ? AND user_id = ?", groupID, userID).
+		Limit(1).
+		Pluck("role", &roles).Error
+	if err != nil || len(roles) == 0 {
+		return "", err
+	}
+	return roles[0], nil
+}
+
+func (r *projectGroupRepository) FindMembers(ctx context.Context, groupID uuid.UUID) ([]*model.ProjectGroupMember, error) {
+	var members []*model.ProjectGroupMember
+	err := r.db.WithContext(ctx).
+		Table("users").
+		Select("users.*, project_groups_users.role, project_groups_users.created_at AS joined_at").
+		Joins("JOIN project_groups_users ON project_groups_users.user_id = users.id").
+		Where("project_groups_users.group_id = ?", groupID).
+		Order("users.username").
+		Scan(&members).Error
+	if err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+func (r *projectGroupRepository) FindMemberships(ctx context.Context, userID uuid.UUID) ([]*model.ProjectGroupMembership, error) {
+	var memberships []*model.ProjectGroupMembership
+	err := r.db.WithContext(ctx).
+		Table("project_groups").
+		Select("project_groups.*, COALESCE(project_groups_users.role, '') AS role").
+		Joins("LEFT JOIN project_groups_users ON project_groups_users.group_id = project_groups.id AND project_groups_users.user_id = ?", userID).
+		Where("project_groups.owner_id = ? OR project_groups_users.user_id IS NOT NULL", userID).
+		Order("project_groups.name").
+		Find(&memberships).Error
+	if err != nil {
+		return nil, err
+	}
+	return memberships, nil
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+	"flagon/pkg/database"
+	"flagon/pkg/model"
+)
+
+type SigningKeyRepository interface {
+	Create(ctx context.Context, key *model.SigningKey) error
+	// FindAll returns every key, newest first.
+	FindAll(ctx context.Context) ([]*model.SigningKey, error)
+	Delete(ctx context.Context, ids []string) error
+}
+
+type signingKeyRepository struct {
+	db *database.DB
+}
+
+func NewSigningKeyRepository(db *database.DB) SigningKeyRepository {
+	return &signingKeyRepository{db: db}
+}
+
+func (r *signingKeyRepository) Create(ctx context.Context, key *model.SigningKey) error {
+	return r.db.WithContext(ctx).Create(key).Error
+}
+
+func (r *signingKeyRepository) FindAll(ctx context.Context) ([]*model.SigningKey, error) {
+	var keys []*model.SigningKey
+	if err := r.db.WithContext(ctx).Order("created_at DESC, id").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (r *signingKeyRepository) Delete(ctx context.Context, ids []string) error {
+	return r.db.WithContext(ctx).Where("id IN ?", ids).Delete(&model.SigningKey{}).Error
+}
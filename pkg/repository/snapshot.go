@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"flagon/pkg/database"
+	"flagon/pkg/evaluation"
+	"flagon/pkg/model"
+
+	"github.com/google/uuid"
+)
+
+// SnapshotRepository assembles evaluation snapshots from the feature, flag and target group tables.
+type SnapshotRepository interface {
+	Snapshot(ctx context.Context, projectID, environmentID uuid.UUID) (*evaluation.Snapshot, error)
+}
+
+type snapshotRepository struct {
+	db *database.DB
+}
+
+func NewSnapshotRepository(db *database.DB) SnapshotRepository {
+	return &snapshotRepository{db: db}
+}
+
+// Snapshot returns the compiled configuration of the environment. Only target groups linked to the
+// environment are included, ordered by position, which is the order they take precedence in.
+func (r *snapshotRepository) Snapshot(ctx context.Context, projectID, environmentID uuid.UUID) (*evaluation.Snapshot, error) {
+	db := r.db.WithContext(ctx)
+
+	var env model.ProjectEnvironment
+	if err := db.First(&env, "project_id = ? AND id = ?", projectID, environmentID).Error; err != nil {
+		return nil, err
+	}
+
+	var features []*model.Feature
+	if err := db.Where("project_id = ?", projectID).Order("name").Find(&features).Error; err != nil {
+		return nil, err
+	}
+
+	var flags []*model.ProjectFeatureFlag
+	if err := db.Where("project_id = ? AND environment_id = ?", projectID, environmentID).Find(&flags).Error; err != nil {
+		return nil, err
+	}
+
+	var groups []*model.TargetGroup
+	err := db.
+		Joins("JOIN project_target_group_environment ON project_target_group_environment.target_group_id = project_target_groups.id").
+		Where("project_target_group_environment.environment_id = ?", environmentID).
+		Order("project_target_groups.position, project_target_groups.name").
+		Find(&groups).Error
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &evaluation.Snapshot{
+		ProjectID:     projectID,
+		EnvironmentID: environmentID,
+		Flags:         make([]*evaluation.Flag, 0, len(features)),
+		TargetGroups:  make([]*evaluation.TargetGroup, 0, len(groups)),
+	}
+
+	byFeature := make(map[uuid.UUID]*evaluation.Flag, len(features))
+	for _, feature := range features {
+		flag := &evaluation.Flag{Key: feature.Key, Default: feature.DefaultValue}
+		byFeature[feature.ID] = flag
+		snapshot.Flags = append(snapshot.Flags, flag)
+	}
+	for _, state := range flags {
+		flag, ok := byFeature[state.FeatureID]
+		if !ok {
+			continue
+		}
+		if state.TargetGroupID == nil {
+			enabled := state.Enabled
+			flag.Enabled = &enabled
+			continue
+		}
+		flag.Targets = append(flag.Targets, &evaluation.Target{TargetGroupID: *state.TargetGroupID, Enabled: state.Enabled})
+	}
+
+	for _, group := range groups {
+		snapshot.TargetGroups = append(snapshot.TargetGroups, &evaluation.TargetGroup{
+			ID:                group.ID,
+			Name:              group.Name,
+			Position:          group.Position,
+			RolloutPercentage: group.RolloutPercentage,
+			RolloutSalt:       group.RolloutSalt,
+			BucketBy:          group.BucketBy,
+			Rules:             json.RawMessage(group.Rules),
+		})
+	}
+
+	snapshot.Compile()
+	return snapshot, nil
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"flagon/pkg/database"
+	"flagon/pkg/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TargetGroupRepository interface {
+	Create(ctx context.Context, group *model.TargetGroup) error
+	Update(ctx context.Context, group *model.TargetGroup) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	FindByID(ctx context.Context, projectID, id uuid.UUID) (*model.TargetGroup, error)
+	FindByProject(ctx context.Context, projectID uuid.UUID) ([]*model.TargetGroup, error)
+	UpdatePositions(ctx context.Context, projectID uuid.UUID, orderedIDs []uuid.UUID) error
+	FindEnvironments(ctx context.Context, id uuid.UUID) ([]*model.ProjectEnvironment, error)
+	AddEnvironment(ctx context.Context, link *model.ProjectTargetGroupEnvironment) error
+	RemoveEnvironment(ctx context.Context, id, environmentID uuid.UUID) error
+}
+
+type targetGroupRepository struct {
+	db *database.DB
+}
+
+func NewTargetGroupRepository(db *database.DB) TargetGroupRepository {
+	return &targetGroupRepository{db: db}
+}
+
+func (r *targetGroupRepository) Create(ctx context.Context, group *model.TargetGroup) error {
+	return r.db.WithContext(ctx).Create(group).Error
+}
+
+func (r *targetGroupRepository) Update(ctx context.Context, group *model.TargetGroup) error {
+	return r.db.WithContext(ctx).Save(group).Error
+}
+
+func (r *targetGroupRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&model.TargetGroup{}, "id = ?", id).Error
+}
+
+func (r *targetGroupRepository) FindByID(ctx context.Context, projectID, id uuid.UUID) (*model.TargetGroup, error) {
+	var group model.TargetGroup
+	err := r.db.WithContext(ctx).First(&group, "project_id = ? AND id = ?", projectID, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+func (r *targetGroupRepository) FindByProject(ctx context.Context, projectID uuid.UUID) ([]*model.TargetGroup, error) {
+	var groups []*model.TargetGroup
+	err := r.db.WithContext(ctx).Where("project_id = ?", projectID).Order("position, name").Find(&groups).Error
+	if err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// UpdatePositions stores the index of every target group in orderedIDs as its position.
+func (r *targetGroupRepository) UpdatePositions(ctx context.Context, projectID uuid.UUID, orderedIDs []uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for position, id := range orderedIDs {
+			err := tx.Model(&model.TargetGroup{}).
+				Where("project_id = ? AND id = ?", projectID, id).
+				Updates(map[string]any{"position": position, "updated_at": time.Now()}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// FindEnvironments returns the environments the target group is enabled in.
+func (r *targetGroupRepository) FindEnvironments(ctx context.Context, id uuid.UUID) ([]*model.ProjectEnvironment, error) {
+	var envs []*model.ProjectEnvironment
+	err := r.db.WithContext(ctx).
+		Joins("JOIN project_target_group_environment ON project_target_group_environment.environment_id = project_environments.id").
+		Where("project_target_group_environment.target_group_id = ?", id).
+		Order("project_environments.position, project_environments.name").
+		Find(&envs).Error
+	if err != nil {
+		return nil, err
+	}
+	return envs, nil
+}
+
+// AddEnvironment enables the target group in an environment. Enabling it twice is a no-op.
+func (r *targetGroupRepository) AddEnvironment(ctx context.Context, link *model.ProjectTargetGroupEnvironment) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(link).Error
+}
+
+// RemoveEnvironment disables the target group in an environment. Its flag states there are kept
+// and take effect again if the group is re-enabled.
+func (r *targetGroupRepository) RemoveEnvironment(ctx context.Context, id, environmentID uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Where("target_group_id = ? AND environment_id = ?", id, environmentID).
+		Delete(&model.ProjectTargetGroupEnvironment{}).Error
+}
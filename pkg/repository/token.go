@@ -2,47 +2,299 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"flagon/pkg/cache"
+	"flagon/pkg/model"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// TokenRepository tracks the JWTs that have not been revoked. Tokens belong to a family: the tokens
+// descending from one login through refreshes. Each family is a session of the user.
 type TokenRepository interface {
-	AddJwtToken(ctx context.Context, userID uuid.UUID, jti string, expiration time.Duration) error
+	AddJwtToken(ctx context.Context, userID uuid.UUID, family, jti string, expiration time.Duration) error
 	RemoveJwtToken(ctx context.Context, userID uuid.UUID, jti string) error
 	IsJwtValid(ctx context.Context, userID uuid.UUID, jti string) (bool, error)
+	// UseRefreshToken revokes a refresh token and returns its family. A refresh token that was
+	// already used is reported as reused, with its family; an unknown one returns an empty family.
+	UseRefreshToken(ctx context.Context, userID uuid.UUID, jti string, expiration time.Duration) (family string, reused bool, err error)
+	// RevokeFamily revokes every token of the family and deletes its session.
+	RevokeFamily(ctx context.Context, userID uuid.UUID, family string) error
+
+	// CreateSession stores the session of a new family. AddJwtToken extends it with the tokens of the family.
+	CreateSession(ctx context.Context, userID uuid.UUID, session *model.Session, expiration time.Duration) error
+	// TouchSession records that the session was used, unless it has ended.
+	TouchSession(ctx context.Context, userID uuid.UUID, id string, usedAt time.Time) error
+	// FindSession returns nil when the session does not exist or has ended.
+	FindSession(ctx context.Context, userID uuid.UUID, id string) (*model.Session, error)
+	// FindSessions returns the sessions of the user, most recently used first.
+	FindSessions(ctx context.Context, userID uuid.UUID) ([]*model.Session, error)
+}
+
+type tokenRepo struct {
+	cache cache.Cache
+}
+
+func NewTokenRepository(cache cache.Cache) TokenRepository {
+	return &tokenRepo{
+		cache: cache,
+	}
+}
+
+func jwtTokenKey(userID uuid.UUID, jti string) string {
+	return fmt.Sprintf("user:%s:jwt-tokens:%s", userID.String(), jti)
+}
+
+func usedJwtTokenKey(userID uuid.UUID, jti string) string {
+	return fmt.Sprintf("user:%s:jwt-used-tokens:%s", userID.String(), jti)
+}
+
+// jwtFamilyKey holds the IDs of the tokens of a family.
+func jwtFamilyKey(userID uuid.UUID, family string) string {
+	return fmt.Sprintf("user:%s:jwt-family:%s", userID.String(), family)
+}
+
+func sessionKey(userID uuid.UUID, id string) string {
+	return fmt.Sprintf("user:%s:session:%s", userID.String(), id)
+}
+
+// sessionIndexKey holds the IDs of the sessions of a user, which may have ended since.
+func sessionIndexKey(userID uuid.UUID) string {
+	return fmt.Sprintf("user:%s:session-ids", userID.String())
+}
+
+// storedSession is a session as kept in the cache.
+type storedSession struct {
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"userAgent"`
+	IssuedAt   time.Time `json:"issuedAt"`
+	LastUsedAt time.Time `json:"lastUsedAt"`
+}
+
+// outlive returns the expiration of a key that has to live at least as long as the expiration,
+// given its TTL. Zero means forever.
+func outlive(ttl, expiration time.Duration) time.Duration {
+	if expiration <= 0 {
+		return 0
+	}
+	return max(ttl, expiration)
+}
+
+// addMember adds the member to the list of the key, which lives at least as long as the expiration.
+func (r *tokenRepo) addMember(ctx context.Context, key, member string, expiration time.Duration) error {
+	return r.cache.Update(ctx, key, func(data []byte, ttl time.Duration) ([]byte, time.Duration, error) {
+		var members []string
+		if data != nil {
+			if err := json.Unmarshal(data, &members); err != nil {
+				return nil, 0, err
+			}
+		}
+		if !slices.Contains(members, member) {
+			members = append(members, member)
+		}
+		data, err := json.Marshal(members)
+		return data, outlive(ttl, expiration), err
+	})
+}
+
+// removeMembers removes the members from the list of the key, deleting it once empty.
+func (r *tokenRepo) removeMembers(ctx context.Context, key string, removed ...string) error {
+	return r.cache.Update(ctx, key, func(data []byte, ttl time.Duration) ([]byte, time.Duration, error) {
+		if data == nil {
+			return nil, 0, nil
+		}
+		var members []string
+		if err := json.Unmarshal(data, &members); err != nil {
+			return nil, 0, err
+		}
+		members = slices.DeleteFunc(members, func(member string) bool {
+			return slices.Contains(removed, member)
+		})
+		if len(members) == 0 {
+			return nil, 0, nil
+		}
+		data, err := json.Marshal(members)
+		return data, ttl, err
+	})
+}
+
+func (r *tokenRepo) members(ctx context.Context, key string) ([]string, error) {
+	data, err := r.cache.Get(ctx, key)
+	if errors.Is(err, cache.ErrMiss) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var members []string
+	return members, json.Unmarshal(data, &members)
+}
+
+// updateSession changes the session with fn, unless it has ended.
+func (r *tokenRepo) updateSession(ctx context.Context, userID uuid.UUID, id string, fn func(session *storedSession, ttl time.Duration) time.Duration) error {
+	return r.cache.Update(ctx, sessionKey(userID, id), func(data []byte, ttl time.Duration) ([]byte, time.Duration, error) {
+		if data == nil {
+			return nil, 0, nil
+		}
+		var session storedSession
+		if err := json.Unmarshal(data, &session); err != nil {
+			return nil, 0, err
+		}
+		ttl = fn(&session, ttl)
+		data, err := json.Marshal(&session)
+		return data, ttl, err
+	})
+}
+
+func (r *tokenRepo) AddJwtToken(ctx context.Context, userID uuid.UUID, family, jti string, expiration time.Duration) error {
+	if err := r.cache.Set(ctx, jwtTokenKey(userID, jti), []byte(family), expiration); err != nil {
+		return err
+	}
+	// The family and its session live as long as its longest-lived token.
+	if err := r.addMember(ctx, jwtFamilyKey(userID, family), jti, expiration); err != nil {
+		return err
+	}
+	err := r.updateSession(ctx, userID, family, func(_ *storedSession, ttl time.Duration) time.Duration {
+		if ttl > 0 && expiration > 0 {
+			return max(ttl, expiration)
+		}
+		return ttl
+	})
+	if err != nil {
+		return err
+	}
+	return r.addMember(ctx, sessionIndexKey(userID), family, expiration)
 }
 
-type redisTokenRepo struct {
-	client *cache.RedisCache
+func (r *tokenRepo) RemoveJwtToken(ctx context.Context, userID uuid.UUID, jti string) error {
+	_, err := r.cache.Delete(ctx, jwtTokenKey(userID, jti))
+	return err
 }
 
-func NewTokenRepository(client *cache.RedisCache) TokenRepository {
-	return &redisTokenRepo{
-		client: client,
+func (r *tokenRepo) IsJwtValid(ctx context.Context, userID uuid.UUID, jti string) (bool, error) {
+	_, err := r.cache.Get(ctx, jwtTokenKey(userID, jti))
+	if errors.Is(err, cache.ErrMiss) {
+		return false, nil
 	}
+	return err == nil, err
 }
 
-func (r *redisTokenRepo) AddJwtToken(ctx context.Context, userID uuid.UUID, jti string, expiration time.Duration) error {
-	key := fmt.Sprintf("user:%s:jwt-tokens:%s", userID.String(), jti)
-	return r.client.Set(ctx, key, jti, expiration).Err()
+func (r *tokenRepo) UseRefreshToken(ctx context.Context, userID uuid.UUID, jti string, expiration time.Duration) (string, bool, error) {
+	// Taking the token makes sure only one request can use it.
+	family, err := r.cache.Take(ctx, jwtTokenKey(userID, jti))
+	if err == nil {
+		// Remember the token until it would have expired, to detect it being replayed.
+		return string(family), false, r.cache.Set(ctx, usedJwtTokenKey(userID, jti), family, expiration)
+	}
+	if !errors.Is(err, cache.ErrMiss) {
+		return "", false, err
+	}
+
+	family, err = r.cache.Get(ctx, usedJwtTokenKey(userID, jti))
+	if errors.Is(err, cache.ErrMiss) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return string(family), true, nil
 }
 
-func (r *redisTokenRepo) RemoveJwtToken(ctx context.Context, userID uuid.UUID, jti string) error {
-	key := fmt.Sprintf("user:%s:jwt-tokens:%s", userID.String(), jti)
+func (r *tokenRepo) RevokeFamily(ctx context.Context, userID uuid.UUID, family string) error {
+	familyKey := jwtFamilyKey(userID, family)
+	jtis, err := r.members(ctx, familyKey)
+	if err != nil {
+		return err
+	}
+	keys := []string{familyKey, sessionKey(userID, family)}
+	for _, jti := range jtis {
+		keys = append(keys, jwtTokenKey(userID, jti))
+	}
+	if _, err := r.cache.Delete(ctx, keys...); err != nil {
+		return err
+	}
+	return r.removeMembers(ctx, sessionIndexKey(userID), family)
+}
 
-	return r.client.Del(ctx, key).Err()
+func (r *tokenRepo) CreateSession(ctx context.Context, userID uuid.UUID, session *model.Session, expiration time.Duration) error {
+	data, err := json.Marshal(&storedSession{
+		IP:         session.IP,
+		UserAgent:  session.UserAgent,
+		IssuedAt:   session.IssuedAt,
+		LastUsedAt: session.LastUsedAt,
+	})
+	if err != nil {
+		return err
+	}
+	if err := r.cache.Set(ctx, sessionKey(userID, session.ID), data, expiration); err != nil {
+		return err
+	}
+	return r.addMember(ctx, sessionIndexKey(userID), session.ID, expiration)
 }
 
-func (r *redisTokenRepo) IsJwtValid(ctx context.Context, userID uuid.UUID, jti string) (bool, error) {
-	key := fmt.Sprintf("user:%s:jwt-tokens:%s", userID.String(), jti)
+func (r *tokenRepo) TouchSession(ctx context.Context, userID uuid.UUID, id string, usedAt time.Time) error {
+	return r.updateSession(ctx, userID, id, func(session *storedSession, ttl time.Duration) time.Duration {
+		session.LastUsedAt = usedAt
+		return ttl
+	})
+}
 
-	exists, err := r.client.Exists(ctx, key).Result()
+func (r *tokenRepo) FindSession(ctx context.Context, userID uuid.UUID, id string) (*model.Session, error) {
+	data, err := r.cache.Get(ctx, sessionKey(userID, id))
+	if errors.Is(err, cache.ErrMiss) {
+		return nil, nil
+	}
 	if err != nil {
-		return false, err
+		return nil, err
 	}
+	return parseSession(id, data)
+}
 
-	return exists > 0, nil
+func (r *tokenRepo) FindSessions(ctx context.Context, userID uuid.UUID) ([]*model.Session, error) {
+	indexKey := sessionIndexKey(userID)
+	ids, err := r.members(ctx, indexKey)
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	sessions := make([]*model.Session, 0, len(ids))
+	var ended []string
+	for _, id := range ids {
+		session, err := r.FindSession(ctx, userID, id)
+		if err != nil {
+			return nil, err
+		}
+		if session == nil {
+			ended = append(ended, id)
+			continue
+		}
+		sessions = append(sessions, session)
+	}
+	if len(ended) > 0 {
+		if err := r.removeMembers(ctx, indexKey, ended...); err != nil {
+			return nil, err
+		}
+	}
+	slices.SortFunc(sessions, func(a, b *model.Session) int {
+		return b.LastUsedAt.Compare(a.LastUsedAt)
+	})
+	return sessions, nil
+}
+
+func parseSession(id string, data []byte) (*model.Session, error) {
+	var stored storedSession
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, err
+	}
+	return &model.Session{
+		ID:         id,
+		IP:         stored.IP,
+		UserAgent:  stored.UserAgent,
+		IssuedAt:   stored.IssuedAt,
+		LastUsedAt: stored.LastUsedAt,
+	}, nil
 }
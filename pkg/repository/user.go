@@ -2,18 +2,51 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"flagon/pkg/database"
 	"flagon/pkg/model"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// UserFilter selects a page of users. An empty search matches everyone.
+type UserFilter struct {
+	// Search matches part of the username, email address or name, ignoring case.
+	Search string
+	Limit  int
+	Offset int
+}
+
 type UserRepository interface {
 	Create(ctx context.Context, user *model.User) error
 	FindByUsername(ctx context.Context, username string) (*model.User, error)
 	FindByEmail(ctx context.Context, email string) (*model.User, error)
 	FindByUsernameOrEmail(ctx context.Context, username, email string) (*model.User, error)
 	FindByID(ctx context.Context, id uuid.UUID) (*model.User, error)
+	// FindBySSO returns the user linked to the account of a single sign-on provider.
+	FindBySSO(ctx context.Context, provider, providerID string) (*model.User, error)
+	AddSSO(ctx context.Context, sso *model.UserSSO) error
+	// CreateWithSSO creates a user linked to the account of a single sign-on provider.
+	CreateWithSSO(ctx context.Context, user *model.User, sso *model.UserSSO) error
+	UpdatePassword(ctx context.Context, id uuid.UUID, password string) error
+	// UpdateProfile saves the names, avatar and email address of the user, and whether the address is verified.
+	UpdateProfile(ctx context.Context, user *model.User) error
+	// MarkEmailVerified records that the user verified the email address, unless it has changed since.
+	MarkEmailVerified(ctx context.Context, id uuid.UUID, email string) error
+	// Find returns the matching users ordered by username, and how many match in all.
+	Find(ctx context.Context, filter *UserFilter) ([]*model.User, int64, error)
+	UpdateAdmin(ctx context.Context, id uuid.UUID, isAdmin bool) error
+	// UpdateDisabledAt disables the user, or enables them again when disabledAt is nil.
+	UpdateDisabledAt(ctx context.Context, id uuid.UUID, disabledAt *time.Time) error
+	// CountOwned returns how many project groups and projects the user owns.
+	CountOwned(ctx context.Context, id uuid.UUID) (int64, error)
+	// Delete deletes the user and their memberships, handing the project groups and projects they own
+	// to the heir. The heir becomes an admin member of the projects, as owners are.
+	Delete(ctx context.Context, id, heirID uuid.UUID) error
 }
 
 type userRepository struct {
@@ -63,3 +96,122 @@ func (r *userRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.Use
 	}
 	return &user, nil
 }
+
+func (r *userRepository) FindBySSO(ctx context.Context, provider, providerID string) (*model.User, error) {
+	var user model.User
+	err := r.db.WithContext(ctx).
+		Joins("JOIN user_sso ON user_sso.user_id = users.id").
+		Where("user_sso.provider = ? AND user_sso.provider_id = ?", provider, providerID).
+		Take(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *userRepository) AddSSO(ctx context.Context, sso *model.UserSSO) error {
+	return r.db.WithContext(ctx).Create(sso).Error
+}
+
+func (r *userRepository) CreateWithSSO(ctx context.Context, user *model.User, sso *model.UserSSO) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		return tx.Create(sso).Error
+	})
+}
+
+func (r *userRepository) UpdatePassword(ctx context.Context, id uuid.UUID, password string) error {
+	return r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).Update("password", password).Error
+}
+
+func (r *userRepository) UpdateProfile(ctx context.Context, user *model.User) error {
+	return r.db.WithContext(ctx).Model(user).
+		Select("first_name", "last_name", "avatar_url", "email", "email_verified_at", "updated_at").
+		Updates(user).Error
+}
+
+func (r *userRepository) MarkEmailVerified(ctx context.Context, id uuid.UUID, email string) error {
+	return r.db.WithContext(ctx).Model(&model.User{}).
+		Where("id = ? AND email = ? AND email_verified_at IS NULL", id, email).
+		Update("email_verified_at", time.Now()).Error
+}
+
+func (r *userRepository) Find(ctx context.Context, filter *UserFilter) ([]*model.User, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.User{})
+	if filter.Search != "" {
+		pattern := "%" + escapeLike(strings.ToLower(filter.Search)) + "%"
+		query = query.Where(
+			"LOWER(username) LIKE @pattern ESCAPE '\\' OR LOWER(email) LIKE @pattern ESCAPE '\\' OR "+
+				"LOWER(first_name) LIKE @pattern ESCAPE '\\' OR LOWER(last_name) LIKE @pattern ESCAPE '\\'",
+			sql.Named("pattern", pattern))
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var users []*model.User
+	err := query.Order("username").Limit(filter.Limit).Offset(filter.Offset).Find(&users).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+// escapeLike escapes the wildcards of LIKE patterns, with backslash as the escape character.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+func (r *userRepository) UpdateAdmin(ctx context.Context, id uuid.UUID, isAdmin bool) error {
+	return r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).Update("is_admin", isAdmin).Error
+}
+
+func (r *userRepository) UpdateDisabledAt(ctx context.Context, id uuid.UUID, disabledAt *time.Time) error {
+	return r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).Update("disabled_at", disabledAt).Error
+}
+
+func (r *userRepository) CountOwned(ctx context.Context, id uuid.UUID) (int64, error) {
+	var groups, projects int64
+	if err := r.db.WithContext(ctx).Model(&model.ProjectGroup{}).Where("owner_id = ?", id).Count(&groups).Error; err != nil {
+		return 0, err
+	}
+	if err := r.db.WithContext(ctx).Model(&model.Project{}).Where("owner_id = ?", id).Count(&projects).Error; err != nil {
+		return 0, err
+	}
+	return groups + projects, nil
+}
+
+func (r *userRepository) Delete(ctx context.Context, id, heirID uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Groups and projects the user still owns would be deleted with them; without an heir, the
+		// foreign key of their new owner fails the transaction instead.
+		if err := tx.Model(&model.ProjectGroup{}).Where("owner_id = ?", id).Update("owner_id", heirID).Error; err != nil {
+			return err
+		}
+		var projectIDs []uuid.UUID
+		if err := tx.Model(&model.Project{}).Where("owner_id = ?", id).Pluck("id", &projectIDs).Error; err != nil {
+			return err
+		}
+		if len(projectIDs) > 0 {
+			if err := tx.Model(&model.Project{}).Where("id IN ?", projectIDs).Update("owner_id", heirID).Error; err != nil {
+				return err
+			}
+			members := make([]*model.ProjectUser, 0, len(projectIDs))
+			for _, projectID := range projectIDs {
+				members = append(members, &model.ProjectUser{UserID: heirID, ProjectID: projectID, Role: model.RoleAdmin})
+			}
+			err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "user_id"}, {Name: "project_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{"role", "updated_at"}),
+			}).Create(&members).Error
+			if err != nil {
+				return err
+			}
+		}
+		// Memberships, access tokens and second factors go with the user.
+		return tx.Delete(&model.User{}, "id = ?", id).Error
+	})
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"flagon/pkg/database"
+	"flagon/pkg/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type UserMFARepository interface {
+	// Find returns gorm.ErrRecordNotFound when the user has no authenticator, pending or enabled.
+	Find(ctx context.Context, userID uuid.UUID) (*model.UserMFA, error)
+	// Save replaces the authenticator of the user.
+	Save(ctx context.Context, mfa *model.UserMFA) error
+	// Enable confirms the authenticator, recording the time step of the code confirming it, and
+	// replaces the recovery codes of the user.
+	Enable(ctx context.Context, userID uuid.UUID, counter int64, codes []*model.UserRecoveryCode) error
+	// Delete removes the authenticator and the recovery codes of the user.
+	Delete(ctx context.Context, userID uuid.UUID) error
+	// UseCounter records the time step of an accepted code. It reports false when a code of that
+	// step or a later one was accepted already.
+	UseCounter(ctx context.Context, userID uuid.UUID, counter int64) (bool, error)
+
+	ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codes []*model.UserRecoveryCode) error
+	// UseRecoveryCode marks an unused recovery code as used, reporting whether there was one.
+	UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error)
+	CountRecoveryCodes(ctx context.Context, userID uuid.UUID) (int64, error)
+}
+
+type userMFARepository struct {
+	db *database.DB
+}
+
+func NewUserMFARepository(db *database.DB) UserMFARepository {
+	return &userMFARepository{db: db}
+}
+
+func (r *userMFARepository) Find(ctx context.Context, userID uuid.UUID) (*model.UserMFA, error) {
+	var mfa model.UserMFA
+	err := r.db.WithContext(ctx).First(&mfa, "user_id = ?", userID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &mfa, nil
+}
+
+func (r *userMFARepository) Save(ctx context.Context, mfa *model.UserMFA) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&model.UserMFA{}, "user_id = ?", mfa.UserID).Error; err != nil {
+			return err
+		}
+		return tx.Create(mfa).Error
+	})
+}
+
+func (r *userMFARepository) Enable(ctx context.Context, userID uuid.UUID, counter int64, codes []*model.UserRecoveryCode) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.UserMFA{}).Where("user_id = ?", userID).
+			Updates(map[string]any{"enabled_at": time.Now(), "last_counter": counter}).Error
+		if err != nil {
+			return err
+		}
+		return replaceRecoveryCodes(tx, userID, codes)
+	})
+}
+
+func (r *userMFARepository) Delete(ctx context.Context, userID uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&model.UserRecoveryCode{}, "user_id = ?", userID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.UserMFA{}, "user_id = ?", userID).Error
+	})
+}
+
+func (r *userMFARepository) UseCounter(ctx context.Context, userID uuid.UUID, counter int64) (bool, error) {
+	// The condition makes concurrent logins with the same code accept only one of them.
+	result := r.db.WithContext(ctx).Model(&model.UserMFA{}).
+		Where("user_id = ? AND last_counter < ?", userID, counter).
+		Update("last_counter", counter)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *userMFARepository) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codes []*model.UserRecoveryCode) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, userID, codes)
+	})
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userID uuid.UUID, codes []*model.UserRecoveryCode) error {
+	if err := tx.Delete(&model.UserRecoveryCode{}, "user_id = ?", userID).Error; err != nil {
+		return err
+	}
+	return tx.Create(codes).Error
+}
+
+func (r *userMFARepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.UserRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *userMFARepository) CountRecoveryCodes(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.UserRecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
@@ -1,8 +1,29 @@
 package repository
 
-import "github.com/google/wire"
+import (
+	"flagon/pkg/evaluation"
+
+	"github.com/google/wire"
+)
 
 var WireSet = wire.NewSet(
 	NewTokenRepository,
 	NewUserRepository,
+	NewProjectGroupRepository,
+	NewProjectRepository,
+	NewEnvironmentRepository,
+	NewCategoryRepository,
+	NewFeatureRepository,
+	NewTargetGroupRepository,
+	NewAccessTokenRepository,
+	NewSnapshotRepository,
+	NewFlagEventRepository,
+	NewOIDCStateRepository,
+	NewSigningKeyRepository,
+	NewAuditEventRepository,
+	NewUserMFARepository,
+	NewMFAChallengeRepository,
+	NewOneTimeTokenRepository,
+	NewLoginAttemptRepository,
+	wire.Bind(new(evaluation.Store), new(SnapshotRepository)),
 )
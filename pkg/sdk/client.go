@@ -0,0 +1,227 @@
+// Package sdk is the Go client of Flagon.
+//
+// A Client downloads the flag configuration of the environment its SDK token belongs to and keeps it
+// up to date in the background, either from the server's event stream or by polling. Flags are
+// evaluated locally with the server's own evaluation code, so a flag check never makes a network call.
+// Until the configuration is available, and for flags it does not contain, evaluations return the
+// default value given by the caller. When the server becomes unreachable, the last configuration
+// received keeps being used.
+//
+//	client, err := sdk.New(sdk.Config{BaseURL: "https://flagon.example.com", Token: os.Getenv("FLAGON_SDK_TOKEN")})
+//	if err != nil {
+//		return err
+//	}
+//	defer client.Close()
+//
+//	if client.BoolVariation(ctx, "new-checkout", &evaluation.Context{Key: userID}, false) {
+//		// ...
+//	}
+package sdk
+
+import (
+	"context"
+	"errors"
+	"flagon/pkg/evaluation"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DefaultPollInterval = 30 * time.Second
+	// minPollInterval protects the server from clients configured to poll continuously.
+	minPollInterval = time.Second
+)
+
+var (
+	// ErrNotInitialized is returned by evaluations made before the configuration was first received.
+	ErrNotInitialized = errors.New("flag configuration not received yet")
+	// ErrFlagNotFound is returned for flags missing from the configuration.
+	ErrFlagNotFound = errors.New("flag not found")
+)
+
+// Config configures a Client.
+type Config struct {
+	// BaseURL is the address of the Flagon server, such as https://flagon.example.com.
+	BaseURL string
+	// Token is an SDK access token, which selects the project and environment.
+	Token string
+	// DisableStreaming polls the configuration every PollInterval instead of streaming changes.
+	DisableStreaming bool
+	// PollInterval defaults to DefaultPollInterval.
+	PollInterval time.Duration
+	// HTTPClient defaults to a client without timeout, as the event stream stays open.
+	HTTPClient *http.Client
+	// Logger defaults to slog.Default().
+	Logger *slog.Logger
+}
+
+// EventType is the kind of an Event.
+type EventType int
+
+const (
+	// EventReady is emitted once, when the configuration is first received.
+	EventReady EventType = iota
+	// EventChanged is emitted when the configuration changes afterwards.
+	EventChanged
+	// EventError is emitted when the configuration cannot be updated. Evaluations keep using the
+	// last configuration received.
+	EventError
+	// EventRecovered is emitted when the configuration can be updated again after an EventError.
+	EventRecovered
+)
+
+// Event reports a change of the client state to listeners.
+type Event struct {
+	Type EventType
+	// Err is set for EventError.
+	Err error
+}
+
+// Client evaluates flags against a local copy of an environment's configuration.
+type Client struct {
+	cfg      Config
+	snapshot atomic.Pointer[evaluation.Snapshot]
+	ready    chan struct{}
+	cancel   context.CancelFunc
+	done     chan struct{}
+	failing  atomic.Bool
+
+	mu        sync.Mutex
+	listeners []func(Event)
+}
+
+// New creates a client and starts receiving the configuration in the background.
+func New(cfg Config) (*Client, error) {
+	if cfg.BaseURL == "" {
+		return nil, errors.New("sdk: BaseURL is required")
+	}
+	if cfg.Token == "" {
+		return nil, errors.New("sdk: Token is required")
+	}
+	cfg.BaseURL = strings.TrimSuffix(cfg.BaseURL, "/")
+	if cfg.PollInterval == 0 {
+		cfg.PollInterval = DefaultPollInterval
+	}
+	cfg.PollInterval = max(cfg.PollInterval, minPollInterval)
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{}
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &Client{
+		cfg:    cfg,
+		ready:  make(chan struct{}),
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	go c.run(ctx)
+	return c, nil
+}
+
+// WaitForInitialization blocks until the configuration is first received or the context is done.
+func (c *Client) WaitForInitialization(ctx context.Context) error {
+	select {
+	case <-c.ready:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Initialized reports whether the configuration was received.
+func (c *Client) Initialized() bool {
+	return c.snapshot.Load() != nil
+}
+
+// AddListener registers a function called on every Event. Listeners run synchronously in the
+// goroutine updating the configuration, so they must return quickly.
+func (c *Client) AddListener(listener func(Event)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.listeners = append(c.listeners, listener)
+}
+
+// BoolVariation returns the value of the flag for the evaluation context, or defaultValue when the
+// flag cannot be evaluated.
+func (c *Client) BoolVariation(ctx context.Context, flagKey string, evalCtx *evaluation.Context, defaultValue bool) bool {
+	result, _ := c.BoolVariationDetail(ctx, flagKey, evalCtx, defaultValue)
+	return result.Value
+}
+
+// BoolVariationDetail is BoolVariation with the reason of the value. When the flag cannot be
+// evaluated, the result holds defaultValue without a reason and the error says why.
+func (c *Client) BoolVariationDetail(_ context.Context, flagKey string, evalCtx *evaluation.Context, defaultValue bool) (evaluation.Result, error) {
+	snapshot := c.snapshot.Load()
+	if snapshot == nil {
+		return evaluation.Result{Key: flagKey, Value: defaultValue}, ErrNotInitialized
+	}
+	result, ok := snapshot.EvaluateKey(flagKey, evalCtx)
+	if !ok {
+		return evaluation.Result{Key: flagKey, Value: defaultValue}, ErrFlagNotFound
+	}
+	return result, nil
+}
+
+// AllFlags evaluates every flag for the evaluation context. It returns nil before the configuration
+// is received.
+func (c *Client) AllFlags(evalCtx *evaluation.Context) []evaluation.Result {
+	snapshot := c.snapshot.Load()
+	if snapshot == nil {
+		return nil
+	}
+	return snapshot.EvaluateAll(evalCtx)
+}
+
+// Close stops updating the configuration. Evaluations keep working with the last configuration received.
+func (c *Client) Close() {
+	c.cancel()
+	<-c.done
+}
+
+func (c *Client) run(ctx context.Context) {
+	defer close(c.done)
+	if c.cfg.DisableStreaming {
+		c.poll(ctx)
+	} else {
+		c.stream(ctx)
+	}
+}
+
+// update replaces the configuration and notifies listeners.
+func (c *Client) update(snapshot *evaluation.Snapshot) {
+	if c.snapshot.Swap(snapshot) == nil {
+		close(c.ready)
+		c.emit(Event{Type: EventReady})
+		return
+	}
+	c.emit(Event{Type: EventChanged})
+}
+
+func (c *Client) fail(err error) {
+	c.cfg.Logger.Warn("Failed to update flag configuration", "error", err)
+	c.failing.Store(true)
+	c.emit(Event{Type: EventError, Err: err})
+}
+
+// connected is called whenever the server answers, to report recovering from a failure.
+func (c *Client) connected() {
+	if c.failing.Swap(false) {
+		c.emit(Event{Type: EventRecovered})
+	}
+}
+
+func (c *Client) emit(event Event) {
+	c.mu.Lock()
+	listeners := c.listeners
+	c.mu.Unlock()
+	for _, listener := range listeners {
+		listener(event)
+	}
+}
@@ -0,0 +1,17 @@
+module flagon/pkg/sdk/ofprovider
+
+go 1.24.2
+
+require (
+	flagon v0.0.0-00010101000000-000000000000
+	github.com/open-feature/go-sdk v1.14.0
+)
+
+require (
+	github.com/go-logr/logr v1.4.2 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/google/wire v0.6.0 // indirect
+	golang.org/x/exp v0.0.0-20240506185415-9bf2ced13842 // indirect
+)
+
+replace flagon => ../../..
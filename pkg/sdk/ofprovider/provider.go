@@ -0,0 +1,232 @@
+// Package ofprovider is the OpenFeature provider of Flagon, backed by the local evaluation of the
+// Flagon Go SDK.
+//
+// It is a separate module, so only applications using OpenFeature depend on the OpenFeature SDK.
+//
+//	client, err := sdk.New(sdk.Config{BaseURL: "https://flagon.example.com", Token: os.Getenv("FLAGON_SDK_TOKEN")})
+//	if err != nil {
+//		return err
+//	}
+//	if err := openfeature.SetProviderAndWait(ofprovider.New(client)); err != nil {
+//		return err
+//	}
+//	defer openfeature.Shutdown()
+//
+// The provider owns the client: shutting the provider down closes it, which stops updating the flag
+// configuration.
+//
+// The targeting key of the evaluation context is the Flagon context key; the other attributes are
+// available to target group rules under their names.
+//
+// # Flag types
+//
+// Flagon flags are on/off switches. The server stores whether a flag is enabled per environment and
+// target group, and has no string, number or object values, so the provider resolves boolean flags
+// only. Resolving a flag as another type returns the default value with the TYPE_MISMATCH error
+// code, or FLAG_NOT_FOUND and PROVIDER_NOT_READY as for booleans. Call sites written for providers
+// with typed flags keep working, and fall back to their defaults.
+package ofprovider
+
+import (
+	"context"
+	"errors"
+	"flagon/pkg/evaluation"
+	"flagon/pkg/sdk"
+	"fmt"
+	"time"
+
+	"github.com/open-feature/go-sdk/openfeature"
+)
+
+// DefaultInitTimeout is how long Init waits for the flag configuration by default.
+const DefaultInitTimeout = 10 * time.Second
+
+// eventBuffer holds events while the OpenFeature SDK is busy. Further events are dropped.
+const eventBuffer = 16
+
+// Option configures a Provider.
+type Option func(*Provider)
+
+// WithInitTimeout sets how long Init waits for the flag configuration.
+func WithInitTimeout(timeout time.Duration) Option {
+	return func(p *Provider) {
+		p.initTimeout = timeout
+	}
+}
+
+// Provider implements openfeature.FeatureProvider, openfeature.StateHandler and openfeature.EventHandler.
+type Provider struct {
+	client      *sdk.Client
+	initTimeout time.Duration
+	events      chan openfeature.Event
+}
+
+var (
+	_ openfeature.FeatureProvider = (*Provider)(nil)
+	_ openfeature.StateHandler    = (*Provider)(nil)
+	_ openfeature.EventHandler    = (*Provider)(nil)
+)
+
+// New creates a provider evaluating flags with the client. The provider closes the client on Shutdown.
+func New(client *sdk.Client, opts ...Option) *Provider {
+	p := &Provider{
+		client:      client,
+		initTimeout: DefaultInitTimeout,
+		events:      make(chan openfeature.Event, eventBuffer),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	client.AddListener(p.handleEvent)
+	return p
+}
+
+func (p *Provider) Metadata() openfeature.Metadata {
+	return openfeature.Metadata{Name: "Flagon"}
+}
+
+func (p *Provider) Hooks() []openfeature.Hook {
+	return nil
+}
+
+// Init waits for the flag configuration. OpenFeature reports the provider as ready once it returns.
+func (p *Provider) Init(openfeature.EvaluationContext) error {
+	ctx, cancel := context.WithTimeout(context.Background(), p.initTimeout)
+	defer cancel()
+	if err := p.client.WaitForInitialization(ctx); err != nil {
+		return fmt.Errorf("flagon: flag configuration not received: %w", err)
+	}
+	return nil
+}
+
+// Shutdown closes the client, stopping its polling or event stream. Evaluations keep using the last
+// configuration received.
+func (p *Provider) Shutdown() {
+	p.client.Close()
+}
+
+func (p *Provider) EventChannel() <-chan openfeature.Event {
+	return p.events
+}
+
+func (p *Provider) BooleanEvaluation(ctx context.Context, flag string, defaultValue bool, evalCtx openfeature.FlattenedContext) openfeature.BoolResolutionDetail {
+	result, err := p.client.BoolVariationDetail(ctx, flag, toContext(evalCtx), defaultValue)
+	if err != nil {
+		return openfeature.BoolResolutionDetail{Value: defaultValue, ProviderResolutionDetail: resolutionError(err)}
+	}
+
+	detail := openfeature.ProviderResolutionDetail{
+		Reason:  reason(result.Reason),
+		Variant: variant(result.Value),
+	}
+	if result.TargetGroupID != nil {
+		detail.FlagMetadata = openfeature.FlagMetadata{"targetGroupId": result.TargetGroupID.String()}
+	}
+	return openfeature.BoolResolutionDetail{Value: result.Value, ProviderResolutionDetail: detail}
+}
+
+func (p *Provider) StringEvaluation(ctx context.Context, flag string, defaultValue string, _ openfeature.FlattenedContext) openfeature.StringResolutionDetail {
+	return openfeature.StringResolutionDetail{Value: defaultValue, ProviderResolutionDetail: p.typeMismatch(ctx, flag, "string")}
+}
+
+func (p *Provider) FloatEvaluation(ctx context.Context, flag string, defaultValue float64, _ openfeature.FlattenedContext) openfeature.FloatResolutionDetail {
+	return openfeature.FloatResolutionDetail{Value: defaultValue, ProviderResolutionDetail: p.typeMismatch(ctx, flag, "float")}
+}
+
+func (p *Provider) IntEvaluation(ctx context.Context, flag string, defaultValue int64, _ openfeature.FlattenedContext) openfeature.IntResolutionDetail {
+	return openfeature.IntResolutionDetail{Value: defaultValue, ProviderResolutionDetail: p.typeMismatch(ctx, flag, "integer")}
+}
+
+func (p *Provider) ObjectEvaluation(ctx context.Context, flag string, defaultValue any, _ openfeature.FlattenedContext) openfeature.InterfaceResolutionDetail {
+	return openfeature.InterfaceResolutionDetail{Value: defaultValue, ProviderResolutionDetail: p.typeMismatch(ctx, flag, "object")}
+}
+
+// typeMismatch reports why a flag cannot be resolved as a type other than boolean: it does not
+// exist, the configuration is not available yet, or it is a boolean.
+func (p *Provider) typeMismatch(ctx context.Context, flag, flagType string) openfeature.ProviderResolutionDetail {
+	if _, err := p.client.BoolVariationDetail(ctx, flag, nil, false); err != nil {
+		return resolutionError(err)
+	}
+	return openfeature.ProviderResolutionDetail{
+		ResolutionError: openfeature.NewTypeMismatchResolutionError(fmt.Sprintf("flag %q is a boolean, not a %s", flag, flagType)),
+		Reason:          openfeature.ErrorReason,
+	}
+}
+
+// handleEvent translates the events of the Flagon client. After a failed update the provider is
+// stale, as it keeps serving the last configuration, until the client reaches the server again.
+func (p *Provider) handleEvent(event sdk.Event) {
+	var eventType openfeature.EventType
+	var message string
+	switch event.Type {
+	case sdk.EventReady, sdk.EventRecovered:
+		eventType = openfeature.ProviderReady
+	case sdk.EventChanged:
+		eventType = openfeature.ProviderConfigChange
+		message = "flag configuration changed"
+	case sdk.EventError:
+		eventType = openfeature.ProviderError
+		if p.client.Initialized() {
+			eventType = openfeature.ProviderStale
+		}
+		message = event.Err.Error()
+	default:
+		return
+	}
+
+	select {
+	case p.events <- openfeature.Event{
+		ProviderName:         p.Metadata().Name,
+		EventType:            eventType,
+		ProviderEventDetails: openfeature.ProviderEventDetails{Message: message},
+	}:
+	default:
+	}
+}
+
+// toContext converts an OpenFeature evaluation context to a Flagon one.
+func toContext(evalCtx openfeature.FlattenedContext) *evaluation.Context {
+	result := &evaluation.Context{Attributes: make(map[string]any, len(evalCtx))}
+	for name, value := range evalCtx {
+		if name == openfeature.TargetingKey {
+			result.Key, _ = value.(string)
+			continue
+		}
+		result.Attributes[name] = value
+	}
+	return result
+}
+
+func resolutionError(err error) openfeature.ProviderResolutionDetail {
+	detail := openfeature.ProviderResolutionDetail{Reason: openfeature.ErrorReason}
+	switch {
+	case errors.Is(err, sdk.ErrFlagNotFound):
+		detail.ResolutionError = openfeature.NewFlagNotFoundResolutionError(err.Error())
+	case errors.Is(err, sdk.ErrNotInitialized):
+		detail.ResolutionError = openfeature.NewProviderNotReadyResolutionError(err.Error())
+	default:
+		detail.ResolutionError = openfeature.NewGeneralResolutionError(err.Error())
+	}
+	return detail
+}
+
+func reason(reason evaluation.Reason) openfeature.Reason {
+	switch reason {
+	case evaluation.ReasonTargetMatch:
+		return openfeature.TargetingMatchReason
+	case evaluation.ReasonRollout:
+		return openfeature.SplitReason
+	case evaluation.ReasonEnvironment:
+		return openfeature.StaticReason
+	case evaluation.ReasonDefault:
+		return openfeature.DefaultReason
+	}
+	return openfeature.UnknownReason
+}
+
+func variant(value bool) string {
+	if value {
+		return "on"
+	}
+	return "off"
+}
@@ -0,0 +1,204 @@
+package sdk
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flagon/pkg/evaluation"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// streamIdleTimeout drops a stream silent for longer than a few server heartbeats.
+	streamIdleTimeout = 45 * time.Second
+	minRetryDelay     = time.Second
+	maxRetryDelay     = 30 * time.Second
+)
+
+// poll downloads the configuration now and then every poll interval, until the context is done.
+func (c *Client) poll(ctx context.Context) {
+	ticker := time.NewTicker(c.cfg.PollInterval)
+	defer ticker.Stop()
+
+	var last []byte
+	for {
+		data, err := c.fetchConfig(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		switch {
+		case err != nil:
+			c.fail(err)
+		case bytes.Equal(data, last):
+			c.connected()
+		default:
+			c.connected()
+			if err := c.apply("snapshot", data); err != nil {
+				c.fail(err)
+				break
+			}
+			last = data
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (c *Client) fetchConfig(ctx context.Context) (json.RawMessage, error) {
+	resp, err := c.request(ctx, "/api/v1/sdk/config", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decode flag configuration: %w", err)
+	}
+	return body.Data, nil
+}
+
+// stream follows the server's event stream, reconnecting with a growing delay, until the context is done.
+func (c *Client) stream(ctx context.Context) {
+	var lastEventID string
+	delay := minRetryDelay
+	for {
+		connected, err := c.follow(ctx, &lastEventID)
+		if ctx.Err() != nil {
+			return
+		}
+		c.fail(err)
+		if connected {
+			delay = minRetryDelay
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return
+		}
+		delay = min(delay*2, maxRetryDelay)
+	}
+}
+
+// follow reads one connection of the event stream until it fails, reporting whether it connected.
+// lastEventID is resumed from and kept up to date.
+func (c *Client) follow(ctx context.Context, lastEventID *string) (bool, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	header := http.Header{"Accept": {"text/event-stream"}}
+	if *lastEventID != "" {
+		header.Set("Last-Event-ID", *lastEventID)
+	}
+	resp, err := c.request(ctx, "/api/v1/sdk/stream", header)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	c.connected()
+
+	idle := time.AfterFunc(streamIdleTimeout, cancel)
+	defer idle.Stop()
+
+	reader := bufio.NewReader(resp.Body)
+	var id, event string
+	var data strings.Builder
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			if ctx.Err() != nil && errors.Is(err, context.Canceled) {
+				err = errors.New("event stream timed out")
+			}
+			return true, err
+		}
+		idle.Reset(streamIdleTimeout)
+
+		line = strings.TrimRight(line, "\r\n")
+		if line != "" {
+			field, value, _ := strings.Cut(line, ":")
+			value = strings.TrimPrefix(value, " ")
+			switch field {
+			case "id":
+				id = value
+			case "event":
+				event = value
+			case "data":
+				if data.Len() > 0 {
+					data.WriteByte('\n')
+				}
+				data.WriteString(value)
+			}
+			continue
+		}
+
+		if event != "" {
+			if err := c.apply(event, []byte(data.String())); err != nil {
+				// Start over from a snapshot rather than build on a configuration that may be wrong.
+				*lastEventID = ""
+				return true, err
+			}
+			*lastEventID = id
+		}
+		id, event = "", ""
+		data.Reset()
+	}
+}
+
+// apply updates the configuration with an event of the stream.
+func (c *Client) apply(event string, data []byte) error {
+	switch event {
+	case "snapshot":
+		var snapshot evaluation.Snapshot
+		if err := json.Unmarshal(data, &snapshot); err != nil {
+			return fmt.Errorf("decode snapshot: %w", err)
+		}
+		snapshot.Compile()
+		c.update(&snapshot)
+	case "patch":
+		current := c.snapshot.Load()
+		if current == nil {
+			return errors.New("patch received before a snapshot")
+		}
+		var patch evaluation.Patch
+		if err := json.Unmarshal(data, &patch); err != nil {
+			return fmt.Errorf("decode patch: %w", err)
+		}
+		c.update(current.Apply(&patch))
+	}
+	return nil
+}
+
+func (c *Client) request(ctx context.Context, path string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.cfg.BaseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Authorization", "Bearer "+c.cfg.Token)
+
+	resp, err := c.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(message))
+	}
+	return resp, nil
+}
@@ -25,14 +25,17 @@ type Controller interface {
 	Register(router gin.IRouter)
 }
 
-func NewHttpServer(v1Api v1.API) (*HttpServer, error) {
+func NewHttpServer(v1Api v1.API, limiter *RateLimiter) (*HttpServer, error) {
 	cfg := config.GetConfig().Server
 	server := &http.Server{
 		Addr: fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
 	}
 	gin.SetMode(gin.ReleaseMode)
 	router := gin.New()
-	router.Use(LogMiddleware, RecoveryMiddleware)
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		return nil, err
+	}
+	router.Use(RequestIDMiddleware, ClientMiddleware, LogMiddleware, RecoveryMiddleware, limiter.Middleware)
 	v1Api.Register(router)
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
 	ui.Register(router)
@@ -1,14 +1,41 @@
 package server
 
 import (
+	"flagon/pkg/service"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"log/slog"
 	"net/http"
 	"strings"
 	"time"
 )
 
+const (
+	requestIDHeader = "X-Request-ID"
+	// maxRequestIDLength bounds the request IDs logged from clients.
+	maxRequestIDLength = 128
+)
+
+// RequestIDMiddleware generates the ID of the request and returns it in the response. Changes made
+// by the request are recorded in the audit log with it. The ID is never taken from the client, who
+// could otherwise make their changes look like part of another request; an ID the client sent is
+// only logged, to correlate the request with its own logs.
+func RequestIDMiddleware(c *gin.Context) {
+	requestID := uuid.NewString()
+	c.Header(requestIDHeader, requestID)
+	c.Request = c.Request.WithContext(service.WithRequestID(c.Request.Context(), requestID))
+	c.Next()
+}
+
+// ClientMiddleware passes the IP address and user agent of the client to the services, which record
+// them in the sessions started by logins.
+func ClientMiddleware(c *gin.Context) {
+	client := service.Client{IP: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+	c.Request = c.Request.WithContext(service.WithClient(c.Request.Context(), client))
+	c.Next()
+}
+
 func LogMiddleware(c *gin.Context) {
 	start := time.Now()
 	path := c.Request.URL.Path
@@ -27,12 +54,15 @@ func LogMiddleware(c *gin.Context) {
 		slog.String("path", path),
 		slog.Int("status", status),
 		slog.Duration("latency", time.Since(start)),
-		slog.String("request_id", c.Request.Header.Get("X-Request-ID")),
+		slog.String("request_id", service.RequestIDFrom(c.Request.Context())),
 		slog.String("route", c.FullPath()),
 		slog.String("client_ip", c.ClientIP()),
 		slog.String("user_agent", c.Request.UserAgent()),
 		slog.Int("body_size", c.Writer.Size()),
 	}
+	if clientRequestID := c.GetHeader(requestIDHeader); clientRequestID != "" {
+		attrs = append(attrs, slog.String("client_request_id", clientRequestID[:min(len(clientRequestID), maxRequestIDLength)]))
+	}
 	var level slog.Level
 	switch {
 	case status >= http.StatusBadRequest && status < http.StatusInternalServerError:
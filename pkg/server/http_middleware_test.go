@@ -0,0 +1,71 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"flagon/pkg/service"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	var logs bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&logs, nil)))
+	t.Cleanup(func() { slog.SetDefault(defaultLogger) })
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestIDMiddleware, LogMiddleware)
+	var seen string
+	router.GET("/", func(c *gin.Context) {
+		seen = service.RequestIDFrom(c.Request.Context())
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name     string
+		clientID string
+	}{
+		{name: "without a client ID"},
+		{name: "with a client ID", clientID: "forged"},
+		{name: "with the ID of another request", clientID: uuid.NewString()},
+	}
+	previous := ""
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs.Reset()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.clientID != "" {
+				req.Header.Set(requestIDHeader, tt.clientID)
+			}
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			if _, err := uuid.Parse(seen); err != nil || seen == tt.clientID || seen == previous {
+				t.Errorf("request ID %q, want a new one generated by the server", seen)
+			}
+			previous = seen
+			if got := recorder.Header().Get(requestIDHeader); got != seen {
+				t.Errorf("%s response header %q, want the request ID %q", requestIDHeader, got, seen)
+			}
+
+			var entry struct {
+				RequestID       string `json:"request_id"`
+				ClientRequestID string `json:"client_request_id"`
+			}
+			if err := json.Unmarshal(logs.Bytes(), &entry); err != nil {
+				t.Fatal(err)
+			}
+			if entry.RequestID != seen || entry.ClientRequestID != tt.clientID {
+				t.Errorf("logged request ID %q and client request ID %q, want %q and %q",
+					entry.RequestID, entry.ClientRequestID, seen, tt.clientID)
+			}
+		})
+	}
+}
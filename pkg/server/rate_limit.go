@@ -0,0 +1,161 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flagon/pkg/api/v1/response"
+	"flagon/pkg/cache"
+	"flagon/pkg/config"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxRateLimitBodySize bounds the JSON bodies read for the username of requests.
+const maxRateLimitBodySize = 64 << 10
+
+// RateLimiter limits the requests to the routes with rules in config.RateLimit. Requests are counted
+// in fixed windows, in the cache so that all Flagon instances share the counts. While the cache
+// fails, each instance counts in memory instead.
+type RateLimiter struct {
+	enabled bool
+	// rules are grouped by route.
+	rules    map[string][]config.RateLimitRule
+	cache    cache.Cache
+	fallback *cache.MemoryCache
+	// lastWarning is when the fallback to memory was last logged, in Unix nanoseconds.
+	lastWarning atomic.Int64
+}
+
+func NewRateLimiter(c cache.Cache) *RateLimiter {
+	cfg := config.GetConfig().RateLimit
+	limiter := &RateLimiter{
+		enabled:  cfg.Enabled,
+		rules:    make(map[string][]config.RateLimitRule),
+		cache:    c,
+		fallback: cache.NewMemoryCache(),
+	}
+	for _, rule := range cfg.Rules {
+		limiter.rules[rule.Route] = append(limiter.rules[rule.Route], rule)
+	}
+	return limiter
+}
+
+// Middleware counts the request against each rule of its route. The rule with the fewest requests
+// left is reported in the RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers; once one
+// is exceeded, the request is rejected with a Retry-After header.
+func (l *RateLimiter) Middleware(c *gin.Context) {
+	rules := l.rules[c.Request.Method+" "+c.FullPath()]
+	if !l.enabled || len(rules) == 0 {
+		c.Next()
+		return
+	}
+
+	var (
+		reported  *config.RateLimitRule
+		remaining int
+		reset     time.Duration
+		exceeded  bool
+	)
+	for i, rule := range rules {
+		value := rateLimitKeyValue(c, rule.Key)
+		if value == "" {
+			// Requests without the key, such as logins without a username, fail validation anyway.
+			continue
+		}
+		// Hashing bounds the length of keys and keeps tokens out of Redis.
+		sum := sha256.Sum256([]byte(value))
+		key := fmt.Sprintf("rate-limit:%s:%d:%s", rule.Route, i, hex.EncodeToString(sum[:16]))
+		count, left := l.hit(c.Request.Context(), key, rule.Window)
+
+		if reported == nil || rule.Limit-int(count) < remaining {
+			reported, remaining, reset = &rules[i], rule.Limit-int(count), left
+		}
+		if count > int64(rule.Limit) {
+			exceeded = true
+			break
+		}
+	}
+	if reported == nil {
+		c.Next()
+		return
+	}
+
+	seconds := strconv.Itoa(int(math.Ceil(reset.Seconds())))
+	c.Header("RateLimit-Limit", strconv.Itoa(reported.Limit))
+	c.Header("RateLimit-Remaining", strconv.Itoa(max(remaining, 0)))
+	c.Header("RateLimit-Reset", seconds)
+	if exceeded {
+		c.Header("Retry-After", seconds)
+		response.SendTooManyRequests(c, response.ErrTooManyRequests, "Rate limit exceeded, try again in "+seconds+" seconds")
+		c.Abort()
+		return
+	}
+	c.Next()
+}
+
+// hit counts a request in the window of the key, returning the count and the time left in the window.
+func (l *RateLimiter) hit(ctx context.Context, key string, window time.Duration) (int64, time.Duration) {
+	count, left, err := l.cache.Increment(ctx, key, window)
+	if err == nil {
+		return count, left
+	}
+	// Logged once a minute, not for every request while the cache is down.
+	if last := l.lastWarning.Load(); time.Since(time.Unix(0, last)) > time.Minute &&
+		l.lastWarning.CompareAndSwap(last, time.Now().UnixNano()) {
+		slog.WarnContext(ctx, "Counting rate limits in memory, the cache failed", "error", err)
+	}
+	// The memory cache does not fail.
+	count, left, _ = l.fallback.Increment(ctx, key, window)
+	return count, left
+}
+
+// rateLimitKeyValue returns what the requests of a rule are counted by, or "" when the request has none.
+func rateLimitKeyValue(c *gin.Context, key string) string {
+	switch key {
+	case config.RateLimitKeyIP:
+		return c.ClientIP()
+	case config.RateLimitKeyUsername:
+		return requestUsername(c)
+	case config.RateLimitKeyToken:
+		scheme, token, found := strings.Cut(c.GetHeader("Authorization"), " ")
+		if found && strings.EqualFold(scheme, "Bearer") {
+			return token
+		}
+	}
+	return ""
+}
+
+// requestUsername reads the username field of a JSON body, leaving the body for the handler.
+func requestUsername(c *gin.Context) string {
+	if c.Request.Body == nil {
+		return ""
+	}
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxRateLimitBodySize+1))
+	// The handler reads what was read, followed by the rest, if any.
+	c.Request.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), c.Request.Body), c.Request.Body}
+	if err != nil || len(body) > maxRateLimitBodySize {
+		return ""
+	}
+
+	var fields struct {
+		Username string `json:"username"`
+	}
+	if json.Unmarshal(body, &fields) != nil {
+		return ""
+	}
+	return fields.Username
+}
@@ -0,0 +1,288 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"flagon/pkg/cache"
+	"flagon/pkg/config"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// failingCache fails every request, like a Redis server that is down.
+type failingCache struct {
+	cache.Cache
+}
+
+func (failingCache) Increment(context.Context, string, time.Duration) (int64, time.Duration, error) {
+	return 0, 0, errors.New("connection refused")
+}
+
+// newLimitedRouter serves POST /login and GET /stream behind a limiter with the rules. The handlers
+// answer with the body they read.
+func newLimitedRouter(t *testing.T, c cache.Cache, trustedProxies []string, rules ...config.RateLimitRule) *gin.Engine {
+	t.Helper()
+	limiter := &RateLimiter{
+		enabled:  true,
+		rules:    make(map[string][]config.RateLimitRule),
+		cache:    c,
+		fallback: cache.NewMemoryCache(),
+	}
+	for _, rule := range rules {
+		limiter.rules[rule.Route] = append(limiter.rules[rule.Route], rule)
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		t.Fatal(err)
+	}
+	router.Use(limiter.Middleware)
+	echo := func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, string(body))
+	}
+	router.POST("/login", echo)
+	router.GET("/stream", echo)
+	return router
+}
+
+// request describes a request of a rate limit test.
+type request struct {
+	method, path string
+	remoteAddr   string
+	header       http.Header
+	body         string
+}
+
+func (r request) serve(router *gin.Engine) *httptest.ResponseRecorder {
+	method, path := r.method, r.path
+	if method == "" {
+		method, path = http.MethodPost, "/login"
+	}
+	req := httptest.NewRequest(method, path, strings.NewReader(r.body))
+	if r.remoteAddr != "" {
+		req.RemoteAddr = r.remoteAddr
+	}
+	for name, values := range r.header {
+		req.Header[name] = values
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestRateLimitWindow(t *testing.T) {
+	router := newLimitedRouter(t, cache.NewMemoryCache(), nil,
+		config.RateLimitRule{Route: "POST /login", Key: config.RateLimitKeyIP, Limit: 3, Window: 200 * time.Millisecond})
+	client := request{remoteAddr: "192.0.2.1:1234"}
+
+	for want := 2; want >= 0; want-- {
+		recorder := client.serve(router)
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("status %d, want 200", recorder.Code)
+		}
+		if got := recorder.Header().Get("RateLimit-Remaining"); got != strconv.Itoa(want) {
+			t.Errorf("RateLimit-Remaining %s, want %d", got, want)
+		}
+		if got := recorder.Header().Get("RateLimit-Limit"); got != "3" {
+			t.Errorf("RateLimit-Limit %s, want 3", got)
+		}
+	}
+
+	recorder := client.serve(router)
+	if recorder.Code != http.StatusTooManyRequests {
+		t.Fatalf("status %d beyond the limit, want 429", recorder.Code)
+	}
+	if recorder.Header().Get("Retry-After") != "1" || recorder.Header().Get("RateLimit-Remaining") != "0" {
+		t.Errorf("Retry-After %q and RateLimit-Remaining %q, want 1 and 0",
+			recorder.Header().Get("Retry-After"), recorder.Header().Get("RateLimit-Remaining"))
+	}
+
+	// The count starts over in the next window.
+	time.Sleep(250 * time.Millisecond)
+	if recorder := client.serve(router); recorder.Code != http.StatusOK {
+		t.Errorf("status %d in the next window, want 200", recorder.Code)
+	}
+}
+
+func TestRateLimitKeys(t *testing.T) {
+	rules := []config.RateLimitRule{
+		{Route: "POST /login", Key: config.RateLimitKeyIP, Limit: 3, Window: time.Minute},
+		{Route: "POST /login", Key: config.RateLimitKeyUsername, Limit: 1, Window: time.Minute},
+		{Route: "GET /stream", Key: config.RateLimitKeyToken, Limit: 1, Window: time.Minute},
+	}
+	bearer := func(token string) http.Header {
+		return http.Header{"Authorization": {"Bearer " + token}}
+	}
+
+	tests := []struct {
+		name     string
+		requests []request
+		// want is the status of each request.
+		want []int
+	}{
+		{
+			name: "username",
+			requests: []request{
+				{remoteAddr: "192.0.2.1:1", body: `{"username":"ana"}`},
+				{remoteAddr: "192.0.2.2:1", body: `{"username":"ana"}`},
+				{remoteAddr: "192.0.2.3:1", body: `{"username":"bob"}`},
+			},
+			want: []int{http.StatusOK, http.StatusTooManyRequests, http.StatusOK},
+		},
+		{
+			name: "ip",
+			requests: []request{
+				{remoteAddr: "192.0.2.1:1", body: `{"username":"ana"}`},
+				{remoteAddr: "192.0.2.1:2", body: `{"username":"bob"}`},
+				{remoteAddr: "192.0.2.1:3", body: `{"username":"carol"}`},
+				{remoteAddr: "192.0.2.1:4", body: `{"username":"dave"}`},
+				{remoteAddr: "192.0.2.2:1", body: `{"username":"erin"}`},
+			},
+			want: []int{http.StatusOK, http.StatusOK, http.StatusOK, http.StatusTooManyRequests, http.StatusOK},
+		},
+		{
+			// Requests without a username are only counted by IP address.
+			name: "no username",
+			requests: []request{
+				{remoteAddr: "192.0.2.1:1", body: `{}`},
+				{remoteAddr: "192.0.2.1:1", body: `not json`},
+				{remoteAddr: "192.0.2.1:1"},
+			},
+			want: []int{http.StatusOK, http.StatusOK, http.StatusOK},
+		},
+		{
+			name: "token",
+			requests: []request{
+				{method: http.MethodGet, path: "/stream", header: bearer("sdk-1")},
+				{method: http.MethodGet, path: "/stream", header: bearer("sdk-1")},
+				{method: http.MethodGet, path: "/stream", header: bearer("sdk-2")},
+			},
+			want: []int{http.StatusOK, http.StatusTooManyRequests, http.StatusOK},
+		},
+		{
+			name: "no token",
+			requests: []request{
+				{method: http.MethodGet, path: "/stream"},
+				{method: http.MethodGet, path: "/stream"},
+				{method: http.MethodGet, path: "/stream", header: http.Header{"Authorization": {"Basic c2RrOjE="}}},
+			},
+			want: []int{http.StatusOK, http.StatusOK, http.StatusOK},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newLimitedRouter(t, cache.NewMemoryCache(), nil, rules...)
+			for i, req := range tt.requests {
+				recorder := req.serve(router)
+				if recorder.Code != tt.want[i] {
+					t.Errorf("request %d: status %d, want %d", i, recorder.Code, tt.want[i])
+				}
+				if recorder.Code == http.StatusOK && recorder.Body.String() != req.body {
+					t.Errorf("request %d: handler read %q, want the whole body %q", i, recorder.Body.String(), req.body)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimitTrustedProxies(t *testing.T) {
+	rule := config.RateLimitRule{Route: "POST /login", Key: config.RateLimitKeyIP, Limit: 1, Window: time.Minute}
+	forwarded := func(ip string) http.Header {
+		return http.Header{"X-Forwarded-For": {ip}}
+	}
+
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		requests       []request
+		want           []int
+	}{
+		{
+			name:           "clients behind a trusted proxy",
+			trustedProxies: []string{"10.0.0.0/8"},
+			requests: []request{
+				{remoteAddr: "10.0.0.1:1", header: forwarded("192.0.2.1")},
+				{remoteAddr: "10.0.0.1:1", header: forwarded("192.0.2.2")},
+				{remoteAddr: "10.0.0.2:1", header: forwarded("192.0.2.1")},
+			},
+			want: []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+		},
+		{
+			name:           "header of an untrusted client",
+			trustedProxies: []string{"10.0.0.0/8"},
+			requests: []request{
+				{remoteAddr: "192.0.2.1:1", header: forwarded("198.51.100.1")},
+				{remoteAddr: "192.0.2.1:1", header: forwarded("198.51.100.2")},
+			},
+			want: []int{http.StatusOK, http.StatusTooManyRequests},
+		},
+		{
+			name: "no trusted proxies",
+			requests: []request{
+				{remoteAddr: "10.0.0.1:1", header: forwarded("192.0.2.1")},
+				{remoteAddr: "10.0.0.1:1", header: forwarded("192.0.2.2")},
+			},
+			want: []int{http.StatusOK, http.StatusTooManyRequests},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newLimitedRouter(t, cache.NewMemoryCache(), tt.trustedProxies, rule)
+			for i, req := range tt.requests {
+				if recorder := req.serve(router); recorder.Code != tt.want[i] {
+					t.Errorf("request %d: status %d, want %d", i, recorder.Code, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimitFallsBackToMemory(t *testing.T) {
+	router := newLimitedRouter(t, failingCache{}, nil,
+		config.RateLimitRule{Route: "POST /login", Key: config.RateLimitKeyIP, Limit: 2, Window: time.Minute})
+	client := request{remoteAddr: "192.0.2.1:1234"}
+
+	want := []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests}
+	for i, status := range want {
+		if recorder := client.serve(router); recorder.Code != status {
+			t.Errorf("request %d: status %d, want %d", i, recorder.Code, status)
+		}
+	}
+}
+
+func TestRateLimitUnlimitedRoutes(t *testing.T) {
+	router := newLimitedRouter(t, cache.NewMemoryCache(), nil,
+		config.RateLimitRule{Route: "POST /login", Key: config.RateLimitKeyIP, Limit: 1, Window: time.Minute})
+
+	for range 3 {
+		recorder := request{method: http.MethodGet, path: "/stream"}.serve(router)
+		if recorder.Code != http.StatusOK || recorder.Header().Get("RateLimit-Limit") != "" {
+			t.Fatalf("status %d with RateLimit-Limit %q, want 200 without rate limit headers",
+				recorder.Code, recorder.Header().Get("RateLimit-Limit"))
+		}
+	}
+}
+
+func TestDefaultRateLimitRules(t *testing.T) {
+	if err := config.LoadConfig(""); err != nil {
+		t.Fatal(err)
+	}
+	limiter := NewRateLimiter(cache.NewMemoryCache())
+
+	keys := map[string]bool{}
+	for _, rule := range limiter.rules["GET /api/v1/sdk/stream"] {
+		keys[rule.Key] = true
+	}
+	if !keys[config.RateLimitKeyToken] || !keys[config.RateLimitKeyIP] {
+		t.Errorf("stream connections are limited by %v, want by token and IP address", keys)
+	}
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"flagon/pkg/model"
+	"flagon/pkg/repository"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AccessService decides what users may do with groups, projects and environments.
+//
+// A user's role in a project group is the highest of: owner when they own the group, their direct
+// membership role, and their role in the parent group. A project inherits the role its user has in
+// the project's group in the same way.
+//
+// Groups requiring two-factor authentication, and their sub-groups and projects, are denied to
+// users who have not enabled it, whatever their role.
+type AccessService interface {
+	GroupRole(ctx context.Context, userID, groupID uuid.UUID) (model.Role, error)
+	ProjectRole(ctx context.Context, userID uuid.UUID, project *model.Project) (model.Role, error)
+	AuthorizeGroup(ctx context.Context, userID, groupID uuid.UUID, permission model.Permission) error
+	AuthorizeProject(ctx context.Context, userID uuid.UUID, projectSlug string, permission model.Permission) error
+	AuthorizeEnvironment(ctx context.Context, userID uuid.UUID, projectSlug string, envID uuid.UUID, permission model.Permission) error
+}
+
+type accessService struct {
+	groupRepo   repository.ProjectGroupRepository
+	projectRepo repository.ProjectRepository
+	envRepo     repository.EnvironmentRepository
+	mfaRepo     repository.UserMFARepository
+}
+
+func NewAccessService(
+	groupRepo repository.ProjectGroupRepository,
+	projectRepo repository.ProjectRepository,
+	envRepo repository.EnvironmentRepository,
+	mfaRepo repository.UserMFARepository,
+) AccessService {
+	return &accessService{
+		groupRepo:   groupRepo,
+		projectRepo: projectRepo,
+		envRepo:     envRepo,
+		mfaRepo:     mfaRepo,
+	}
+}
+
+// permissionRoles maps each permission to the lowest role granting it.
+var permissionRoles = map[model.Permission]model.Role{
+	model.PermissionView:   model.RoleViewer,
+	model.PermissionEdit:   model.RoleEditor,
+	model.PermissionToggle: model.RoleEditor,
+	model.PermissionManage: model.RoleAdmin,
+	model.PermissionOwn:    model.RoleOwner,
+}
+
+// GroupRole returns the user's role in the group, or the empty role when they have no access.
+func (s *accessService) GroupRole(ctx context.Context, userID, groupID uuid.UUID) (model.Role, error) {
+	role, _, err := s.groupAccess(ctx, userID, groupID)
+	return role, err
+}
+
+// groupAccess returns the user's role in the group, and whether the group or one of its ancestors
+// requires two-factor authentication.
+func (s *accessService) groupAccess(ctx context.Context, userID, groupID uuid.UUID) (model.Role, bool, error) {
+	var role model.Role
+	var requireMFA bool
+	seen := make(map[uuid.UUID]bool)
+	for current := &groupID; current != nil && !seen[*current]; {
+		seen[*current] = true
+
+		group, err := s.groupRepo.FindByID(ctx, *current)
+		if err != nil {
+			return "", false, translateError(err, "project group")
+		}
+		requireMFA = requireMFA || group.RequireMFA
+		// Owners have every permission, but the requirements of the ancestors still apply to them.
+		if group.OwnerID == userID {
+			role = model.RoleOwner
+		} else if role != model.RoleOwner {
+			memberRole, err := s.groupRepo.FindMemberRole(ctx, group.ID, userID)
+			if err != nil {
+				return "", false, err
+			}
+			role = role.Max(memberRole)
+		}
+		current = group.ParentID
+	}
+	return role, requireMFA, nil
+}
+
+// ProjectRole returns the user's role in the project, or the empty role when they have no access.
+func (s *accessService) ProjectRole(ctx context.Context, userID uuid.UUID, project *model.Project) (model.Role, error) {
+	role, _, err := s.projectAccess(ctx, userID, project)
+	return role, err
+}
+
+// projectAccess returns the user's role in the project, and whether its group requires two-factor
+// authentication.
+func (s *accessService) projectAccess(ctx context.Context, userID uuid.UUID, project *model.Project) (model.Role, bool, error) {
+	var role model.Role
+	if project.OwnerID == userID {
+		role = model.RoleOwner
+	} else {
+		memberRole, err := s.projectRepo.FindMemberRole(ctx, project.ID, userID)
+		if err != nil {
+			return "", false, err
+		}
+		role = memberRole
+	}
+	if !project.GroupID.Valid {
+		return role, false, nil
+	}
+	groupRole, requireMFA, err := s.groupAccess(ctx, userID, project.GroupID.UUID)
+	if err != nil {
+		return "", false, err
+	}
+	return role.Max(groupRole), requireMFA, nil
+}
+
+func (s *accessService) AuthorizeGroup(ctx context.Context, userID, groupID uuid.UUID, permission model.Permission) error {
+	role, requireMFA, err := s.groupAccess(ctx, userID, groupID)
+	if err != nil {
+		return err
+	}
+	if err := checkRole(role, permissionRoles[permission], "project group"); err != nil {
+		return err
+	}
+	return s.checkMFA(ctx, userID, requireMFA, "project group")
+}
+
+func (s *accessService) AuthorizeProject(ctx context.Context, userID uuid.UUID, projectSlug string, permission model.Permission) error {
+	project, err := s.projectRepo.FindBySlug(ctx, projectSlug)
+	if err != nil {
+		return translateError(err, "project")
+	}
+	role, requireMFA, err := s.projectAccess(ctx, userID, project)
+	if err != nil {
+		return err
+	}
+	if err := checkRole(role, permissionRoles[permission], "project"); err != nil {
+		return err
+	}
+	return s.checkMFA(ctx, userID, requireMFA, "project")
+}
+
+// AuthorizeEnvironment checks a permission on a project, raising the toggle permission to admins
+// when the environment is protected.
+func (s *accessService) AuthorizeEnvironment(ctx context.Context, userID uuid.UUID, projectSlug string, envID uuid.UUID, permission model.Permission) error {
+	project, err := s.projectRepo.FindBySlug(ctx, projectSlug)
+	if err != nil {
+		return translateError(err, "project")
+	}
+	env, err := s.envRepo.FindByID(ctx, project.ID, envID)
+	if err != nil {
+		return translateError(err, "environment")
+	}
+	role, requireMFA, err := s.projectAccess(ctx, userID, project)
+	if err != nil {
+		return err
+	}
+
+	required := permissionRoles[permission]
+	if permission == model.PermissionToggle && env.Protected {
+		required = model.RoleAdmin
+	}
+	if err := checkRole(role, required, "project"); err != nil {
+		return err
+	}
+	return s.checkMFA(ctx, userID, requireMFA, "project")
+}
+
+// checkMFA denies users without two-factor authentication when it is required.
+func (s *accessService) checkMFA(ctx context.Context, userID uuid.UUID, required bool, resource string) error {
+	if !required {
+		return nil
+	}
+	mfa, err := s.mfaRepo.Find(ctx, userID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if !mfa.Enabled() {
+		return fmt.Errorf("%w: this %s requires two-factor authentication", ErrPermissionDenied, resource)
+	}
+	return nil
+}
+
+func checkRole(role, required model.Role, resource string) error {
+	if role == "" {
+		return fmt.Errorf("%w: you are not a member of this %s", ErrPermissionDenied, resource)
+	}
+	if !role.Includes(required) {
+		return fmt.Errorf("%w: requires the %s role", ErrPermissionDenied, required)
+	}
+	return nil
+}
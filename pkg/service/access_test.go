@@ -0,0 +1,321 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"flagon/pkg/model"
+	"flagon/pkg/repository"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// memoryEnvironmentRepository keeps environments in memory.
+type memoryEnvironmentRepository struct {
+	repository.EnvironmentRepository
+	envs []*model.ProjectEnvironment
+}
+
+func (r *memoryEnvironmentRepository) FindByID(_ context.Context, projectID, id uuid.UUID) (*model.ProjectEnvironment, error) {
+	for _, env := range r.envs {
+		if env.ProjectID == projectID && env.ID == id {
+			return env, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryEnvironmentRepository) FindByProject(_ context.Context, projectID uuid.UUID) ([]*model.ProjectEnvironment, error) {
+	var envs []*model.ProjectEnvironment
+	for _, env := range r.envs {
+		if env.ProjectID == projectID {
+			envs = append(envs, env)
+		}
+	}
+	slices.SortStableFunc(envs, func(a, b *model.ProjectEnvironment) int { return a.Position - b.Position })
+	return envs, nil
+}
+
+// accessFixture is a parent group with a sub-group holding a project, which has an open and a
+// protected environment. The owner owns all of them.
+type accessFixture struct {
+	service   AccessService
+	groups    *memoryGroupRepository
+	projects  *memoryProjectRepository
+	owner     uuid.UUID
+	parent    *model.ProjectGroup
+	group     *model.ProjectGroup
+	project   *model.Project
+	env       *model.ProjectEnvironment
+	protected *model.ProjectEnvironment
+}
+
+func newAccessFixture() *accessFixture {
+	owner := uuid.New()
+	parent := &model.ProjectGroup{ID: uuid.New(), OwnerID: owner, Slug: "acme"}
+	group := &model.ProjectGroup{ID: uuid.New(), OwnerID: owner, Slug: "web", ParentID: &parent.ID}
+	project := &model.Project{ID: uuid.New(), Slug: "shop", OwnerID: owner, GroupID: uuid.NullUUID{UUID: group.ID, Valid: true}}
+	env := &model.ProjectEnvironment{ID: uuid.New(), ProjectID: project.ID, Name: "Staging"}
+	protected := &model.ProjectEnvironment{ID: uuid.New(), ProjectID: project.ID, Name: "Production", Protected: true}
+
+	groups := &memoryGroupRepository{
+		groups: []*model.ProjectGroup{parent, group},
+		roles:  map[uuid.UUID]map[uuid.UUID]model.Role{},
+	}
+	projects := &memoryProjectRepository{
+		projects: []*model.Project{project},
+		roles:    map[uuid.UUID]map[uuid.UUID]model.Role{},
+	}
+	envs := &memoryEnvironmentRepository{envs: []*model.ProjectEnvironment{env, protected}}
+	return &accessFixture{
+		service:   NewAccessService(groups, projects, envs, nil),
+		groups:    groups,
+		projects:  projects,
+		owner:     owner,
+		parent:    parent,
+		group:     group,
+		project:   project,
+		env:       env,
+		protected: protected,
+	}
+}
+
+// addGroupMember gives the user a role in the group.
+func (f *accessFixture) addGroupMember(group *model.ProjectGroup, userID uuid.UUID, role model.Role) {
+	if f.groups.roles[group.ID] == nil {
+		f.groups.roles[group.ID] = map[uuid.UUID]model.Role{}
+	}
+	f.groups.roles[group.ID][userID] = role
+}
+
+func (f *accessFixture) addProjectMember(userID uuid.UUID, role model.Role) {
+	if f.projects.roles[f.project.ID] == nil {
+		f.projects.roles[f.project.ID] = map[uuid.UUID]model.Role{}
+	}
+	f.projects.roles[f.project.ID][userID] = role
+}
+
+// authorizeAll checks the permission on the group, the project and both environments, in that order.
+func (f *accessFixture) authorizeAll(ctx context.Context, userID uuid.UUID, permission model.Permission) [4]bool {
+	return [4]bool{
+		f.service.AuthorizeGroup(ctx, userID, f.group.ID, permission) == nil,
+		f.service.AuthorizeProject(ctx, userID, f.project.Slug, permission) == nil,
+		f.service.AuthorizeEnvironment(ctx, userID, f.project.Slug, f.env.ID, permission) == nil,
+		f.service.AuthorizeEnvironment(ctx, userID, f.project.Slug, f.protected.ID, permission) == nil,
+	}
+}
+
+func TestAuthorizeRoles(t *testing.T) {
+	ctx := context.Background()
+
+	// Each permission lists whether the role is granted it on the group, the project, the
+	// environment and the protected environment.
+	type grants map[model.Permission][4]bool
+	all := [4]bool{true, true, true, true}
+	none := [4]bool{}
+
+	tests := []struct {
+		role model.Role
+		want grants
+	}{
+		{
+			role: model.RoleViewer,
+			want: grants{
+				model.PermissionView:   all,
+				model.PermissionEdit:   none,
+				model.PermissionToggle: none,
+				model.PermissionManage: none,
+				model.PermissionOwn:    none,
+			},
+		},
+		{
+			role: model.RoleEditor,
+			want: grants{
+				model.PermissionView: all,
+				model.PermissionEdit: all,
+				// Protected environments raise toggling to admins.
+				model.PermissionToggle: {true, true, true, false},
+				model.PermissionManage: none,
+				model.PermissionOwn:    none,
+			},
+		},
+		{
+			role: model.RoleAdmin,
+			want: grants{
+				model.PermissionView:   all,
+				model.PermissionEdit:   all,
+				model.PermissionToggle: all,
+				model.PermissionManage: all,
+				model.PermissionOwn:    none,
+			},
+		},
+		{
+			role: model.RoleOwner,
+			want: grants{
+				model.PermissionView:   all,
+				model.PermissionEdit:   all,
+				model.PermissionToggle: all,
+				model.PermissionManage: all,
+				model.PermissionOwn:    all,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.role), func(t *testing.T) {
+			f := newAccessFixture()
+			userID := uuid.New()
+			if tt.role == model.RoleOwner {
+				userID = f.owner
+			} else {
+				f.addGroupMember(f.group, userID, tt.role)
+			}
+
+			for permission, want := range tt.want {
+				if got := f.authorizeAll(ctx, userID, permission); got != want {
+					t.Errorf("%s on group, project, environment, protected environment: %v, want %v", permission, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestAuthorizeInheritance(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		setup func(f *accessFixture, userID uuid.UUID)
+		// permission is checked on the group, the project and both environments.
+		permission model.Permission
+		want       [4]bool
+	}{
+		{
+			name:       "no membership",
+			setup:      func(*accessFixture, uuid.UUID) {},
+			permission: model.PermissionView,
+			want:       [4]bool{},
+		},
+		{
+			name: "role in the parent group",
+			setup: func(f *accessFixture, userID uuid.UUID) {
+				f.addGroupMember(f.parent, userID, model.RoleAdmin)
+			},
+			permission: model.PermissionManage,
+			want:       [4]bool{true, true, true, true},
+		},
+		{
+			name: "owner of the parent group",
+			setup: func(f *accessFixture, userID uuid.UUID) {
+				f.parent.OwnerID = userID
+			},
+			permission: model.PermissionOwn,
+			want:       [4]bool{true, true, true, true},
+		},
+		{
+			name: "higher role in the parent group wins",
+			setup: func(f *accessFixture, userID uuid.UUID) {
+				f.addGroupMember(f.parent, userID, model.RoleEditor)
+				f.addGroupMember(f.group, userID, model.RoleViewer)
+			},
+			permission: model.PermissionEdit,
+			want:       [4]bool{true, true, true, true},
+		},
+		{
+			name: "higher role in the sub-group wins",
+			setup: func(f *accessFixture, userID uuid.UUID) {
+				f.addGroupMember(f.parent, userID, model.RoleViewer)
+				f.addGroupMember(f.group, userID, model.RoleAdmin)
+			},
+			permission: model.PermissionToggle,
+			want:       [4]bool{true, true, true, true},
+		},
+		{
+			name: "project membership does not reach the group",
+			setup: func(f *accessFixture, userID uuid.UUID) {
+				f.addProjectMember(userID, model.RoleViewer)
+			},
+			permission: model.PermissionView,
+			want:       [4]bool{false, true, true, true},
+		},
+		{
+			name: "higher role in the project wins",
+			setup: func(f *accessFixture, userID uuid.UUID) {
+				f.addGroupMember(f.group, userID, model.RoleViewer)
+				f.addProjectMember(userID, model.RoleAdmin)
+			},
+			permission: model.PermissionToggle,
+			want:       [4]bool{false, true, true, true},
+		},
+		{
+			name: "higher role in the group wins over the project",
+			setup: func(f *accessFixture, userID uuid.UUID) {
+				f.addGroupMember(f.parent, userID, model.RoleEditor)
+				f.addProjectMember(userID, model.RoleViewer)
+			},
+			permission: model.PermissionEdit,
+			want:       [4]bool{true, true, true, true},
+		},
+		{
+			name: "project owner",
+			setup: func(f *accessFixture, userID uuid.UUID) {
+				f.project.OwnerID = userID
+			},
+			permission: model.PermissionOwn,
+			want:       [4]bool{false, true, true, true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAccessFixture()
+			userID := uuid.New()
+			tt.setup(f, userID)
+
+			if got := f.authorizeAll(ctx, userID, tt.permission); got != tt.want {
+				t.Errorf("%s on group, project, environment, protected environment: %v, want %v", tt.permission, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthorizeErrors(t *testing.T) {
+	ctx := context.Background()
+	f := newAccessFixture()
+	userID := uuid.New()
+	f.addGroupMember(f.group, userID, model.RoleEditor)
+
+	tests := []struct {
+		name    string
+		err     error
+		wantErr error
+	}{
+		{
+			name:    "missing role",
+			err:     f.service.AuthorizeProject(ctx, userID, f.project.Slug, model.PermissionManage),
+			wantErr: ErrPermissionDenied,
+		},
+		{
+			name:    "unknown group",
+			err:     f.service.AuthorizeGroup(ctx, userID, uuid.New(), model.PermissionView),
+			wantErr: ErrNotFound,
+		},
+		{
+			name:    "unknown project",
+			err:     f.service.AuthorizeProject(ctx, userID, "blog", model.PermissionView),
+			wantErr: ErrNotFound,
+		},
+		{
+			name:    "environment of another project",
+			err:     f.service.AuthorizeEnvironment(ctx, userID, f.project.Slug, uuid.New(), model.PermissionToggle),
+			wantErr: ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !errors.Is(tt.err, tt.wantErr) {
+				t.Errorf("got %v, want %v", tt.err, tt.wantErr)
+			}
+		})
+	}
+}
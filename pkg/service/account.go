@@ -0,0 +1,282 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"flagon/pkg/config"
+	"flagon/pkg/mailer"
+	"flagon/pkg/model"
+	"flagon/pkg/repository"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	passwordResetLifetime     = time.Hour
+	emailVerificationLifetime = 48 * time.Hour
+	// forcedPasswordResetLifetime leaves more time, as the user did not ask for the link.
+	forcedPasswordResetLifetime = 24 * time.Hour
+)
+
+// AccountService lets users recover their account and verify their email address, through links
+// sent by email. The links carry signed single-use tokens.
+type AccountService interface {
+	// RequestPasswordReset emails a password reset link. Unknown addresses are ignored without an
+	// error, so the endpoint does not tell which addresses have an account.
+	RequestPasswordReset(ctx context.Context, req *PasswordResetRequest) error
+	// ResetPassword sets a new password and logs the user out everywhere.
+	ResetPassword(ctx context.Context, req *ResetPasswordRequest) error
+	// ForcePasswordReset clears the password of the user, logs them out everywhere and emails them a
+	// password reset link, for admins to shut out whoever learned the password.
+	ForcePasswordReset(ctx context.Context, userID uuid.UUID) error
+	// SendEmailVerification emails a link verifying the email address of the user.
+	SendEmailVerification(ctx context.Context, userID uuid.UUID) error
+	VerifyEmail(ctx context.Context, req *VerifyEmailRequest) error
+}
+
+type accountService struct {
+	userRepo       repository.UserRepository
+	oneTimeRepo    repository.OneTimeTokenRepository
+	keyService     SigningKeyService
+	sessionService SessionService
+	mailer         mailer.Mailer
+	baseURL        string
+}
+
+func NewAccountService(
+	userRepo repository.UserRepository,
+	oneTimeRepo repository.OneTimeTokenRepository,
+	keyService SigningKeyService,
+	sessionService SessionService,
+	mailer mailer.Mailer,
+) AccountService {
+	return &accountService{
+		userRepo:       userRepo,
+		oneTimeRepo:    oneTimeRepo,
+		keyService:     keyService,
+		sessionService: sessionService,
+		mailer:         mailer,
+		baseURL:        strings.TrimSuffix(config.GetConfig().Mail.BaseURL, "/"),
+	}
+}
+
+type PasswordResetRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// oneTimeTokenClaim is the payload of the tokens in emailed links. Password reset tokens carry a
+// digest of the password they replace, so they stop working once it changes; verification tokens
+// carry the address they verify.
+type oneTimeTokenClaim struct {
+	jwt.RegisteredClaims
+	TokenType string `json:"typ"`
+	Email     string `json:"email,omitempty"`
+	Password  string `json:"pwd,omitempty"`
+}
+
+func (s *accountService) RequestPasswordReset(ctx context.Context, req *PasswordResetRequest) error {
+	user, err := s.userRepo.FindByEmail(ctx, req.Email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	token, err := s.issue(ctx, user, TokenTypePasswordReset, passwordResetLifetime)
+	if err != nil {
+		return err
+	}
+	return s.mailer.Send(ctx, &mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your Flagon password",
+		Text: fmt.Sprintf("Hi %s,\n\n"+
+			"Someone asked to reset the password of your Flagon account. To choose a new password, open:\n\n"+
+			"%s\n\n"+
+			"The link expires in 1 hour and works once. If you did not ask for it, ignore this email.\n",
+			user.Username, s.link("/reset-password", token)),
+	})
+}
+
+func (s *accountService) ResetPassword(ctx context.Context, req *ResetPasswordRequest) error {
+	user, claims, err := s.use(ctx, req.Token, TokenTypePasswordReset)
+	if err != nil {
+		return err
+	}
+	if claims.Password != passwordDigest(user) {
+		return invalidLink()
+	}
+	if ok, err := s.oneTimeRepo.Use(ctx, claims.ID); err != nil {
+		return err
+	} else if !ok {
+		return invalidLink()
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.UpdatePassword(ctx, user.ID, string(hashedPassword)); err != nil {
+		return err
+	}
+	// The link reached the user's mailbox, which verifies the address too.
+	if err := s.userRepo.MarkEmailVerified(ctx, user.ID, user.Email); err != nil {
+		return err
+	}
+	// Whoever knew the old password must not stay logged in.
+	return s.sessionService.RevokeAll(ctx, user.ID)
+}
+
+func (s *accountService) ForcePasswordReset(ctx context.Context, userID uuid.UUID) error {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return translateError(err, "user")
+	}
+	// No password matches an empty hash, so the old one stops working at once.
+	if err := s.userRepo.UpdatePassword(ctx, user.ID, ""); err != nil {
+		return err
+	}
+	user.Password = ""
+	if err := s.sessionService.RevokeAll(ctx, user.ID); err != nil {
+		return err
+	}
+
+	token, err := s.issue(ctx, user, TokenTypePasswordReset, forcedPasswordResetLifetime)
+	if err != nil {
+		return err
+	}
+	return s.mailer.Send(ctx, &mailer.Message{
+		To:      user.Email,
+		Subject: "Choose a new Flagon password",
+		Text: fmt.Sprintf("Hi %s,\n\n"+
+			"An administrator reset the password of your Flagon account and logged you out. To choose a new\n"+
+			"password, open:\n\n"+
+			"%s\n\n"+
+			"The link expires in 24 hours and works once. Afterwards, ask for a new link on the login page.\n",
+			user.Username, s.link("/reset-password", token)),
+	})
+}
+
+func (s *accountService) SendEmailVerification(ctx context.Context, userID uuid.UUID) error {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return translateError(err, "user")
+	}
+	if user.EmailVerifiedAt != nil {
+		return invalidArgument("%s is verified already", user.Email)
+	}
+
+	token, err := s.issue(ctx, user, TokenTypeEmailVerification, emailVerificationLifetime)
+	if err != nil {
+		return err
+	}
+	return s.mailer.Send(ctx, &mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email address for Flagon",
+		Text: fmt.Sprintf("Hi %s,\n\n"+
+			"To confirm that %s is the email address of your Flagon account, open:\n\n"+
+			"%s\n\n"+
+			"The link expires in 48 hours. If you did not create a Flagon account, ignore this email.\n",
+			user.Username, user.Email, s.link("/verify-email", token)),
+	})
+}
+
+func (s *accountService) VerifyEmail(ctx context.Context, req *VerifyEmailRequest) error {
+	user, claims, err := s.use(ctx, req.Token, TokenTypeEmailVerification)
+	if err != nil {
+		return err
+	}
+	if claims.Email != user.Email {
+		return invalidLink()
+	}
+	if ok, err := s.oneTimeRepo.Use(ctx, claims.ID); err != nil {
+		return err
+	} else if !ok {
+		return invalidLink()
+	}
+	return s.userRepo.MarkEmailVerified(ctx, user.ID, claims.Email)
+}
+
+// issue signs a single-use token of the type for the user.
+func (s *accountService) issue(ctx context.Context, user *model.User, tokenType string, lifetime time.Duration) (string, error) {
+	now := time.Now()
+	claims := oneTimeTokenClaim{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   user.ID.String(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(lifetime)),
+		},
+		TokenType: tokenType,
+	}
+	switch tokenType {
+	case TokenTypePasswordReset:
+		claims.Password = passwordDigest(user)
+	case TokenTypeEmailVerification:
+		claims.Email = user.Email
+	}
+
+	token, err := s.keyService.Sign(ctx, claims)
+	if err != nil {
+		return "", err
+	}
+	if err := s.oneTimeRepo.Add(ctx, claims.ID, lifetime); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// use verifies a token of the type, returning its user. The caller consumes it once its checks pass.
+func (s *accountService) use(ctx context.Context, tokenString, tokenType string) (*model.User, *oneTimeTokenClaim, error) {
+	claims := &oneTimeTokenClaim{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (any, error) {
+		return s.keyService.VerificationKey(ctx, token)
+	}, jwt.WithValidMethods(SigningAlgorithms), jwt.WithExpirationRequired())
+	if err != nil || claims.TokenType != tokenType {
+		return nil, nil, invalidLink()
+	}
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, nil, invalidLink()
+	}
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, invalidLink()
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, claims, nil
+}
+
+func (s *accountService) link(path, token string) string {
+	return s.baseURL + path + "?" + url.Values{"token": {token}}.Encode()
+}
+
+// passwordDigest identifies the current password of the user without revealing its hash.
+func passwordDigest(user *model.User) string {
+	sum := sha256.Sum256([]byte(user.Password))
+	return base64.RawURLEncoding.EncodeToString(sum[:12])
+}
+
+func invalidLink() error {
+	return invalidArgument("the link is invalid or has expired")
+}
@@ -0,0 +1,254 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flagon/pkg/cache"
+	"flagon/pkg/config"
+	"flagon/pkg/mailer"
+	"flagon/pkg/model"
+	"flagon/pkg/repository"
+	"io"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// smtpServer is an in-process SMTP server that hands the messages it receives to the test.
+type smtpServer struct {
+	listener net.Listener
+	messages chan *mail.Message
+}
+
+func newSMTPServer(t *testing.T) *smtpServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &smtpServer{listener: listener, messages: make(chan *mail.Message, 10)}
+	t.Cleanup(func() { listener.Close() })
+	go s.serve(t)
+	return s
+}
+
+func (s *smtpServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *smtpServer) serve(t *testing.T) {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(t, conn)
+	}
+}
+
+// handle speaks enough SMTP for net/smtp: no extensions are offered, so the client neither upgrades
+// to TLS nor authenticates.
+func (s *smtpServer) handle(t *testing.T, conn net.Conn) {
+	defer conn.Close()
+	text := textproto.NewConn(conn)
+	reply := func(line string) bool {
+		return text.PrintfLine("%s", line) == nil
+	}
+	if !reply("220 localhost ESMTP test") {
+		return
+	}
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		command, _, _ := strings.Cut(strings.ToUpper(line), " ")
+		switch command {
+		case "EHLO", "HELO":
+			reply("250 localhost")
+		case "MAIL", "RCPT", "RSET", "NOOP":
+			reply("250 OK")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			msg, err := mail.ReadMessage(bufio.NewReader(text.DotReader()))
+			if err != nil {
+				t.Errorf("read message: %v", err)
+				return
+			}
+			s.messages <- msg
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+var linkTokenPattern = regexp.MustCompile(`https://flagon\.test(/[a-z-]+)\?token=(\S+)`)
+
+// receive returns the next email, its link path and the token of the link.
+func (s *smtpServer) receive(t *testing.T, to string) (subject, path, token string) {
+	t.Helper()
+	var msg *mail.Message
+	select {
+	case msg = <-s.messages:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no email received")
+	}
+	if got := msg.Header.Get("To"); !strings.Contains(got, to) {
+		t.Errorf("email sent to %s, want %s", got, to)
+	}
+	body, err := io.ReadAll(quotedprintable.NewReader(msg.Body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	match := linkTokenPattern.FindStringSubmatch(string(body))
+	if match == nil {
+		t.Fatalf("no link in the email:\n%s", body)
+	}
+	token, err = url.QueryUnescape(match[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return msg.Header.Get("Subject"), match[1], token
+}
+
+func (s *smtpServer) expectNone(t *testing.T) {
+	t.Helper()
+	select {
+	case msg := <-s.messages:
+		t.Errorf("unexpected email with subject %q", msg.Header.Get("Subject"))
+	default:
+	}
+}
+
+func (r *memoryUserRepository) UpdatePassword(_ context.Context, id uuid.UUID, password string) error {
+	for _, user := range r.users {
+		if user.ID == id {
+			user.Password = password
+		}
+	}
+	return nil
+}
+
+// fakeSessionService records whose sessions were revoked.
+type fakeSessionService struct {
+	SessionService
+	revoked []uuid.UUID
+}
+
+func (s *fakeSessionService) RevokeAll(_ context.Context, userID uuid.UUID) error {
+	s.revoked = append(s.revoked, userID)
+	return nil
+}
+
+// newSMTPAccountService returns an account service mailing through the SMTP mailer to the server.
+func newSMTPAccountService(t *testing.T, server *smtpServer, users *memoryUserRepository, sessions SessionService) AccountService {
+	t.Helper()
+	t.Setenv("FLAGON_CACHE_DRIVER", "memory")
+	t.Setenv("FLAGON_MAIL_DRIVER", "smtp")
+	t.Setenv("FLAGON_MAIL_FROM", "Flagon <flagon@example.com>")
+	t.Setenv("FLAGON_MAIL_BASEURL", "https://flagon.test/")
+	t.Setenv("FLAGON_MAIL_SMTP_HOST", "127.0.0.1")
+	t.Setenv("FLAGON_MAIL_SMTP_PORT", strconv.Itoa(server.port()))
+	if err := config.LoadConfig(""); err != nil {
+		t.Fatal(err)
+	}
+	smtpMailer, err := mailer.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyService := newTestSigningKeyService(t, &memorySigningKeyRepository{}, "")
+	oneTimeRepo := repository.NewOneTimeTokenRepository(cache.NewMemoryCache())
+	return NewAccountService(users, oneTimeRepo, keyService, sessions, smtpMailer)
+}
+
+func TestAccountEmails(t *testing.T) {
+	ctx := context.Background()
+	server := newSMTPServer(t)
+	hash, err := bcrypt.GenerateFromPassword([]byte("old-password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := &model.User{ID: uuid.New(), Username: "bob", Email: "bob@example.com", Password: string(hash)}
+	users := &memoryUserRepository{users: []*model.User{user}}
+	sessions := &fakeSessionService{}
+	s := newSMTPAccountService(t, server, users, sessions)
+
+	t.Run("email verification", func(t *testing.T) {
+		if err := s.SendEmailVerification(ctx, user.ID); err != nil {
+			t.Fatal(err)
+		}
+		subject, path, token := server.receive(t, user.Email)
+		if subject != "Verify your email address for Flagon" || path != "/verify-email" {
+			t.Errorf("got %q linking to %s", subject, path)
+		}
+
+		if err := s.ResetPassword(ctx, &ResetPasswordRequest{Token: token, Password: "new-password"}); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("verification token reset the password: %v", err)
+		}
+		if err := s.VerifyEmail(ctx, &VerifyEmailRequest{Token: token}); err != nil {
+			t.Fatalf("verify: %v", err)
+		}
+		if user.EmailVerifiedAt == nil {
+			t.Error("email not verified")
+		}
+		if err := s.VerifyEmail(ctx, &VerifyEmailRequest{Token: token}); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("second use: %v, want an invalid link", err)
+		}
+		if err := s.SendEmailVerification(ctx, user.ID); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("verification of a verified address: %v", err)
+		}
+		server.expectNone(t)
+	})
+
+	t.Run("password reset", func(t *testing.T) {
+		if err := s.RequestPasswordReset(ctx, &PasswordResetRequest{Email: user.Email}); err != nil {
+			t.Fatal(err)
+		}
+		subject, path, token := server.receive(t, user.Email)
+		if subject != "Reset your Flagon password" || path != "/reset-password" {
+			t.Errorf("got %q linking to %s", subject, path)
+		}
+
+		if err := s.VerifyEmail(ctx, &VerifyEmailRequest{Token: token}); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("reset token verified the email: %v", err)
+		}
+		if err := s.ResetPassword(ctx, &ResetPasswordRequest{Token: token, Password: "new-password"}); err != nil {
+			t.Fatalf("reset: %v", err)
+		}
+		if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte("new-password")) != nil {
+			t.Error("password not changed")
+		}
+		if len(sessions.revoked) != 1 || sessions.revoked[0] != user.ID {
+			t.Errorf("revoked the sessions of %v, want the user's", sessions.revoked)
+		}
+		if err := s.ResetPassword(ctx, &ResetPasswordRequest{Token: token, Password: "other-password"}); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("second use: %v, want an invalid link", err)
+		}
+		if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte("new-password")) != nil {
+			t.Error("password changed by a used link")
+		}
+	})
+
+	t.Run("password reset of an unknown address", func(t *testing.T) {
+		if err := s.RequestPasswordReset(ctx, &PasswordResetRequest{Email: "nobody@example.com"}); err != nil {
+			t.Fatal(err)
+		}
+		server.expectNone(t)
+	})
+}
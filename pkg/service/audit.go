@@ -0,0 +1,255 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"flagon/pkg/model"
+	"flagon/pkg/repository"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultAuditLimit = 50
+	maxAuditLimit     = 500
+)
+
+// Actor is who makes the changes of a request.
+type Actor struct {
+	UserID uuid.UUID
+	// AccessTokenID is set when the request authenticated with an access token.
+	AccessTokenID *uuid.UUID
+}
+
+type actorKey struct{}
+
+type requestIDKey struct{}
+
+// WithActor returns a context whose changes are attributed to the actor in the audit log.
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFrom returns the actor set by WithActor.
+func ActorFrom(ctx context.Context) (Actor, bool) {
+	actor, ok := ctx.Value(actorKey{}).(Actor)
+	return actor, ok
+}
+
+// WithRequestID returns a context whose changes are recorded with the request ID.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFrom returns the request ID set by WithRequestID.
+func RequestIDFrom(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// AuditResource identifies an audited resource. Resources of a project set ProjectID; the project
+// group is then found from the project.
+type AuditResource struct {
+	Type      model.AuditResourceType
+	ID        string
+	GroupID   *uuid.UUID
+	ProjectID *uuid.UUID
+}
+
+// AuditService records who changed what, and lists those changes to the admins of the resources.
+type AuditService interface {
+	// Record stores an event for a change that was made. before is nil for creations and after is
+	// nil for deletions. The change is already saved, so failures are logged rather than returned.
+	Record(ctx context.Context, action model.AuditAction, resource AuditResource, before, after any)
+	List(ctx context.Context, userID uuid.UUID, req *ListAuditEventsRequest) ([]*model.AuditEvent, error)
+}
+
+type auditService struct {
+	auditRepo     repository.AuditEventRepository
+	projectRepo   repository.ProjectRepository
+	accessService AccessService
+}
+
+func NewAuditService(
+	auditRepo repository.AuditEventRepository,
+	projectRepo repository.ProjectRepository,
+	accessService AccessService,
+) AuditService {
+	return &auditService{
+		auditRepo:     auditRepo,
+		projectRepo:   projectRepo,
+		accessService: accessService,
+	}
+}
+
+// ListAuditEventsRequest filters audit events. Listing the events of a project or project group
+// requires the admin role in it; without either, only the caller's own changes are listed.
+type ListAuditEventsRequest struct {
+	GroupID      string    `form:"group_id" binding:"omitempty,uuid"`
+	Project      string    `form:"project"`
+	ActorID      string    `form:"actor_id" binding:"omitempty,uuid"`
+	ResourceType string    `form:"resource_type"`
+	ResourceID   string    `form:"resource_id"`
+	Action       string    `form:"action" binding:"omitempty,oneof=create update delete"`
+	RequestID    string    `form:"request_id"`
+	Since        time.Time `form:"since" time_format:"2006-01-02T15:04:05Z07:00"`
+	Until        time.Time `form:"until" time_format:"2006-01-02T15:04:05Z07:00"`
+	Limit        int       `form:"limit" binding:"omitempty,min=1,max=500"`
+	Offset       int       `form:"offset" binding:"omitempty,min=0"`
+}
+
+// groupAudit identifies a resource of a project group.
+func groupAudit(resourceType model.AuditResourceType, id string, groupID uuid.UUID) AuditResource {
+	return AuditResource{Type: resourceType, ID: id, GroupID: &groupID}
+}
+
+// projectAudit identifies a resource of a project.
+func projectAudit(resourceType model.AuditResourceType, id string, projectID uuid.UUID) AuditResource {
+	return AuditResource{Type: resourceType, ID: id, ProjectID: &projectID}
+}
+
+func (s *auditService) Record(ctx context.Context, action model.AuditAction, resource AuditResource, before, after any) {
+	if err := s.record(ctx, action, resource, before, after); err != nil {
+		slog.ErrorContext(ctx, "Failed to record audit event",
+			"action", action, "resource_type", resource.Type, "resource_id", resource.ID, "error", err)
+	}
+}
+
+func (s *auditService) record(ctx context.Context, action model.AuditAction, resource AuditResource, before, after any) error {
+	event := &model.AuditEvent{
+		ID:           uuid.New(),
+		RequestID:    RequestIDFrom(ctx),
+		Action:       action,
+		ResourceType: resource.Type,
+		ResourceID:   resource.ID,
+		GroupID:      resource.GroupID,
+		ProjectID:    resource.ProjectID,
+	}
+	if actor, ok := ActorFrom(ctx); ok {
+		event.ActorID = &actor.UserID
+		event.AccessTokenID = actor.AccessTokenID
+	}
+	if event.GroupID == nil && event.ProjectID != nil {
+		// The project is gone once deleted; its events keep only the project ID then.
+		if project, err := s.projectRepo.FindByID(ctx, *event.ProjectID); err == nil && project.GroupID.Valid {
+			event.GroupID = &project.GroupID.UUID
+		}
+	}
+
+	var err error
+	if event.Before, err = marshalAuditState(before); err != nil {
+		return err
+	}
+	if event.After, err = marshalAuditState(after); err != nil {
+		return err
+	}
+	if action == model.AuditActionUpdate {
+		if event.Diff, err = auditDiff(event.Before, event.After); err != nil {
+			return err
+		}
+	}
+	return s.auditRepo.Create(ctx, event)
+}
+
+func (s *auditService) List(ctx context.Context, userID uuid.UUID, req *ListAuditEventsRequest) ([]*model.AuditEvent, error) {
+	filter := &repository.AuditEventFilter{
+		ResourceType: model.AuditResourceType(req.ResourceType),
+		ResourceID:   req.ResourceID,
+		Action:       model.AuditAction(req.Action),
+		RequestID:    req.RequestID,
+		Limit:        defaultAuditLimit,
+		Offset:       req.Offset,
+	}
+	if req.Limit > 0 {
+		filter.Limit = min(req.Limit, maxAuditLimit)
+	}
+	if !req.Since.IsZero() {
+		filter.Since = &req.Since
+	}
+	if !req.Until.IsZero() {
+		filter.Until = &req.Until
+	}
+	if req.ActorID != "" {
+		actorID, err := uuid.Parse(req.ActorID)
+		if err != nil {
+			return nil, invalidArgument("invalid actor_id")
+		}
+		filter.ActorID = &actorID
+	}
+
+	switch {
+	case req.Project != "":
+		if err := s.accessService.AuthorizeProject(ctx, userID, req.Project, model.PermissionManage); err != nil {
+			return nil, err
+		}
+		project, err := s.projectRepo.FindBySlug(ctx, req.Project)
+		if err != nil {
+			return nil, translateError(err, "project")
+		}
+		filter.ProjectID = &project.ID
+	case req.GroupID != "":
+		groupID, err := uuid.Parse(req.GroupID)
+		if err != nil {
+			return nil, invalidArgument("invalid group_id")
+		}
+		if err := s.accessService.AuthorizeGroup(ctx, userID, groupID, model.PermissionManage); err != nil {
+			return nil, err
+		}
+		filter.GroupID = &groupID
+	case filter.ActorID != nil && *filter.ActorID != userID:
+		return nil, fmt.Errorf("%w: filter by project or project group to list changes made by others", ErrPermissionDenied)
+	default:
+		filter.ActorID = &userID
+	}
+
+	return s.auditRepo.Find(ctx, filter)
+}
+
+// marshalAuditState returns the JSON form of a resource state, or the empty text for none.
+func marshalAuditState(state any) (model.JSONText, error) {
+	if state == nil || reflect.ValueOf(state).Kind() == reflect.Pointer && reflect.ValueOf(state).IsNil() {
+		return "", nil
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return "", err
+	}
+	return model.JSONText(data), nil
+}
+
+// auditDiff returns the top-level fields that differ between two JSON objects, except updatedAt.
+func auditDiff(before, after model.JSONText) (model.JSONText, error) {
+	var beforeFields, afterFields map[string]any
+	if err := json.Unmarshal([]byte(before), &beforeFields); err != nil {
+		return "", err
+	}
+	if err := json.Unmarshal([]byte(after), &afterFields); err != nil {
+		return "", err
+	}
+
+	type change struct {
+		Before any `json:"before"`
+		After  any `json:"after"`
+	}
+	diff := make(map[string]change)
+	for name, value := range afterFields {
+		if name != "updatedAt" && !reflect.DeepEqual(beforeFields[name], value) {
+			diff[name] = change{Before: beforeFields[name], After: value}
+		}
+	}
+	for name, value := range beforeFields {
+		if _, ok := afterFields[name]; !ok {
+			diff[name] = change{Before: value}
+		}
+	}
+
+	data, err := json.Marshal(diff)
+	if err != nil {
+		return "", err
+	}
+	return model.JSONText(data), nil
+}
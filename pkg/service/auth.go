@@ -2,40 +2,97 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
 	"errors"
 	"flagon/pkg/config"
 	"flagon/pkg/model"
+	"flagon/pkg/oidc"
 	"flagon/pkg/repository"
+	"fmt"
+	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	// mfaChallengeLifetime is how long a user has to enter their code after their password.
+	mfaChallengeLifetime = 5 * time.Minute
+	// maxMFAChallengeFailures is how many wrong codes end a login.
+	maxMFAChallengeFailures = 5
+	// loginFailuresExpiration is how long failed logins count towards a lockout.
+	loginFailuresExpiration = 24 * time.Hour
+	// sessionTouchInterval is how stale the last use of a session gets before a request records it,
+	// so that requests do not write the session each.
+	sessionTouchInterval = time.Minute
 )
 
 type AuthService interface {
 	Register(ctx context.Context, req *RegisterRequest) (*model.User, error)
 	Login(ctx context.Context, req *LoginRequest) (*LoginResponse, error)
+	// LoginMFA completes a login of a user with two-factor authentication.
+	LoginMFA(ctx context.Context, req *MFALoginRequest) (*LoginResponse, error)
 	RefreshToken(ctx context.Context, req *RefreshTokenRequest) (*LoginResponse, error)
-	Logout(ctx context.Context, userID uuid.UUID, jti string) error
+	// Logout ends the session, revoking its access and refresh tokens.
+	Logout(ctx context.Context, userID uuid.UUID, sessionID string) error
 	VerifyJwtToken(ctx context.Context, tokenString string) (*jwt.Token, error)
+
+	// OIDCAuthorizationURL starts a single sign-on login, returning the login page of the identity provider.
+	OIDCAuthorizationURL(ctx context.Context) (string, error)
+	// OIDCLogin completes a single sign-on login when the identity provider redirects back.
+	OIDCLogin(ctx context.Context, req *OIDCCallbackRequest) (*LoginResponse, error)
 }
 
 type authService struct {
-	userRepo  repository.UserRepository
-	authCfg   config.Authentication
-	tokenRepo repository.TokenRepository
-	secretKey []byte
+	userRepo      repository.UserRepository
+	authCfg       config.Authentication
+	tokenRepo     repository.TokenRepository
+	oidcStateRepo repository.OIDCStateRepository
+	keyService    SigningKeyService
+	mfaService    MFAService
+	challengeRepo repository.MFAChallengeRepository
+	accounts      AccountService
+	attemptRepo   repository.LoginAttemptRepository
+	// oidcProvider is nil when single sign-on is disabled.
+	oidcProvider *oidc.Provider
 }
 
-func NewAuthService(userRepo repository.UserRepository, tokenRepo repository.TokenRepository) AuthService {
+func NewAuthService(
+	userRepo repository.UserRepository,
+	tokenRepo repository.TokenRepository,
+	oidcStateRepo repository.OIDCStateRepository,
+	keyService SigningKeyService,
+	mfaService MFAService,
+	challengeRepo repository.MFAChallengeRepository,
+	accounts AccountService,
+	attemptRepo repository.LoginAttemptRepository,
+) AuthService {
 	cfg := config.GetConfig()
-	return &authService{
-		userRepo:  userRepo,
-		authCfg:   cfg.Auth,
-		tokenRepo: tokenRepo,
-		secretKey: []byte(cfg.Auth.Secret),
+	s := &authService{
+		userRepo:      userRepo,
+		authCfg:       cfg.Auth,
+		tokenRepo:     tokenRepo,
+		oidcStateRepo: oidcStateRepo,
+		keyService:    keyService,
+		mfaService:    mfaService,
+		challengeRepo: challengeRepo,
+		accounts:      accounts,
+		attemptRepo:   attemptRepo,
+	}
+	if cfg.Auth.OIDC.Enabled {
+		s.oidcProvider = oidc.NewProvider(oidc.Config{
+			Issuer:       cfg.Auth.OIDC.Issuer,
+			ClientID:     cfg.Auth.OIDC.ClientID,
+			ClientSecret: cfg.Auth.OIDC.ClientSecret,
+			RedirectURL:  cfg.Auth.OIDC.RedirectURL,
+			Scopes:       cfg.Auth.OIDC.Scopes,
+		})
 	}
+	return s
 }
 
 type RegisterRequest struct {
@@ -48,6 +105,10 @@ type RegisterRequest struct {
 }
 
 func (s *authService) Register(ctx context.Context, req *RegisterRequest) (*model.User, error) {
+	if err := s.checkRegistration(req.Email); err != nil {
+		return nil, err
+	}
+
 	// Check if username or email already exists
 	existingUser, err := s.userRepo.FindByUsernameOrEmail(ctx, req.Username, req.Email)
 	if err == nil {
@@ -74,7 +135,33 @@ func (s *authService) Register(ctx context.Context, req *RegisterRequest) (*mode
 		AvatarURL: req.AvatarURL,
 	}
 
-	return user, s.userRepo.Create(ctx, user)
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return nil, err
+	}
+	// The user can ask for another email later, so a mail outage does not fail the registration.
+	if err := s.accounts.SendEmailVerification(ctx, user.ID); err != nil {
+		slog.ErrorContext(ctx, "Failed to send the email verification", "user_id", user.ID, "error", err)
+	}
+	return user, nil
+}
+
+// checkRegistration checks that the email address may register an account.
+func (s *authService) checkRegistration(email string) error {
+	registration := s.authCfg.Registration
+	if !registration.Enabled {
+		return fmt.Errorf("%w: registration is disabled", ErrPermissionDenied)
+	}
+	if len(registration.AllowedDomains) == 0 {
+		return nil
+	}
+	_, domain, _ := strings.Cut(email, "@")
+	for _, allowed := range registration.AllowedDomains {
+		if strings.EqualFold(domain, allowed) {
+			return nil
+		}
+	}
+	return invalidArgument("registration is restricted to email addresses at %s",
+		strings.Join(registration.AllowedDomains, ", "))
 }
 
 type LoginRequest struct {
@@ -83,133 +170,276 @@ type LoginRequest struct {
 }
 
 type LoginResponse struct {
-	AccessToken  string `json:"access_token"`
-	RefreshToken string `json:"refresh_token"`
+	AccessToken  string `json:"access_token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
 	User         *model.User
+	// MFAToken is set instead of the tokens when the user has two-factor authentication: the login
+	// completes by sending it with a code to /login/mfa.
+	MFAToken string `json:"mfa_token,omitempty"`
 }
 
+// Token types, told apart by the typ claim so a refresh token cannot be used as an access token.
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+	// Tokens of the links emailed by AccountService.
+	TokenTypePasswordReset     = "password_reset"
+	TokenTypeEmailVerification = "email_verification"
+)
+
 type JwtTokenClaim struct {
 	jwt.RegisteredClaims
+	TokenType string `json:"typ"`
+	// Family identifies the login the token descends from; refreshing keeps it.
+	Family string `json:"fam"`
 }
 
 func (s *authService) Login(ctx context.Context, req *LoginRequest) (*LoginResponse, error) {
+	// Locked accounts do not even check the password, so guessing makes no progress
+	lockedFor, err := s.attemptRepo.LockedFor(ctx, req.Username)
+	if err != nil {
+		return nil, err
+	}
+	if lockedFor > 0 {
+		return nil, &AccountLockedError{RetryAfter: lockedFor}
+	}
+
 	// Find user by username
 	user, err := s.userRepo.FindByUsername(ctx, req.Username)
-	if err != nil {
-		return nil, errors.New("invalid username or password")
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
 	}
 
 	// Verify password
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+	if err != nil || bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)) != nil {
+		// Unknown usernames count too, so lockouts do not tell which accounts exist
+		if err := s.loginFailed(ctx, req.Username); err != nil {
+			return nil, err
+		}
 		return nil, errors.New("invalid username or password")
 	}
 
-	accessJTI := uuid.New().String()
-	refreshJTI := uuid.New().String()
+	if err := s.attemptRepo.Reset(ctx, req.Username); err != nil {
+		return nil, err
+	}
+	return s.completeLogin(ctx, user)
+}
 
-	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, JwtTokenClaim{jwt.RegisteredClaims{
-		ID:        accessJTI,
-		Subject:   user.ID.String(),
-		ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.authCfg.RefreshTokenLifetime)), // Refresh token expires in 30 days
-	}})
+// loginFailed counts a failed password login, locking the account out once the failures in a row
+// reach the threshold. Each further failure doubles the lockout.
+func (s *authService) loginFailed(ctx context.Context, username string) error {
+	lockout := s.authCfg.Lockout
+	if lockout.Threshold <= 0 {
+		return nil
+	}
+	failures, err := s.attemptRepo.Fail(ctx, username, loginFailuresExpiration)
+	if err != nil || failures < int64(lockout.Threshold) {
+		return err
+	}
+	duration := lockout.Duration
+	for i := int64(lockout.Threshold); i < failures && duration < lockout.MaxDuration; i++ {
+		duration *= 2
+	}
+	duration = min(duration, lockout.MaxDuration)
+	slog.WarnContext(ctx, "Locked account out after failed logins",
+		"username", username, "failures", failures, "duration", duration)
+	return s.attemptRepo.Lock(ctx, username, duration)
+}
 
-	accessTokenString, err := accessToken.SignedString(s.secretKey)
+// completeLogin issues the tokens of a user who proved who they are, or a challenge for their
+// second factor when they have one.
+func (s *authService) completeLogin(ctx context.Context, user *model.User) (*LoginResponse, error) {
+	if err := checkEnabled(user); err != nil {
+		return nil, err
+	}
+	enabled, err := s.mfaService.IsEnabled(ctx, user.ID)
 	if err != nil {
 		return nil, err
 	}
+	if !enabled {
+		return s.issueTokens(ctx, user, "")
+	}
 
-	// Generate refresh token
-	refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, JwtTokenClaim{jwt.RegisteredClaims{
-		ID:        refreshJTI,
-		Subject:   user.ID.String(),
-		ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.authCfg.RefreshTokenLifetime)), // Refresh token expires in 30 days
-	}})
+	challenge := rand.Text()
+	if err := s.challengeRepo.Save(ctx, challenge, user.ID, mfaChallengeLifetime); err != nil {
+		return nil, err
+	}
+	return &LoginResponse{MFAToken: challenge}, nil
+}
+
+type MFALoginRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	// Code is a code of the user's authenticator, or one of their recovery codes.
+	Code string `json:"code" binding:"required"`
+}
 
-	refreshTokenString, err := refreshToken.SignedString(s.secretKey)
+func (s *authService) LoginMFA(ctx context.Context, req *MFALoginRequest) (*LoginResponse, error) {
+	userID, err := s.challengeRepo.Find(ctx, req.MFAToken)
 	if err != nil {
 		return nil, err
 	}
+	if userID == nil {
+		return nil, fmt.Errorf("%w: login expired, log in again", ErrAuthenticationFailed)
+	}
 
-	// Store tokens in cache
-	if err := s.tokenRepo.AddJwtToken(ctx, user.ID, accessJTI, s.authCfg.AccessTokenLifetime); err != nil {
+	if err := s.mfaService.Verify(ctx, *userID, req.Code); err != nil {
+		if !errors.Is(err, ErrAuthenticationFailed) {
+			return nil, err
+		}
+		// Codes are short: give up on the login after a few wrong ones rather than let them be guessed.
+		failures, failErr := s.challengeRepo.Fail(ctx, req.MFAToken)
+		if failErr != nil {
+			return nil, failErr
+		}
+		if failures >= maxMFAChallengeFailures {
+			if _, err := s.challengeRepo.Delete(ctx, req.MFAToken); err != nil {
+				return nil, err
+			}
+			return nil, fmt.Errorf("%w: too many wrong codes, log in again", ErrAuthenticationFailed)
+		}
 		return nil, err
 	}
-	if err := s.tokenRepo.AddJwtToken(ctx, user.ID, refreshJTI, s.authCfg.RefreshTokenLifetime); err != nil {
+
+	completed, err := s.challengeRepo.Delete(ctx, req.MFAToken)
+	if err != nil {
 		return nil, err
 	}
-
-	return &LoginResponse{
-		AccessToken:  accessTokenString,
-		RefreshToken: refreshTokenString,
-		User:         user,
-	}, nil
+	if !completed {
+		return nil, fmt.Errorf("%w: login expired, log in again", ErrAuthenticationFailed)
+	}
+	user, err := s.userRepo.FindByID(ctx, *userID)
+	if err != nil {
+		return nil, translateError(err, "user")
+	}
+	return s.issueTokens(ctx, user, "")
 }
 
-type RefreshTokenRequest struct {
-	RefreshToken string `json:"refresh_token" binding:"required"`
+// checkEnabled refuses disabled users. It runs once they proved who they are, so it does not tell
+// which accounts exist.
+func checkEnabled(user *model.User) error {
+	if user.DisabledAt != nil {
+		return fmt.Errorf("%w: the account is disabled", ErrAuthenticationFailed)
+	}
+	return nil
 }
 
-func (s *authService) RefreshToken(ctx context.Context, req *RefreshTokenRequest) (*LoginResponse, error) {
-	token, err := s.VerifyJwtToken(ctx, req.RefreshToken)
-	if err != nil {
+// issueTokens issues a token pair in the family, starting a new one, and its session, when family is
+// empty. Disabled users get none, however they logged in or refresh.
+func (s *authService) issueTokens(ctx context.Context, user *model.User, family string) (*LoginResponse, error) {
+	if err := checkEnabled(user); err != nil {
 		return nil, err
 	}
-	// Find user
-	userID, _ := token.Claims.GetSubject()
-	user, err := s.userRepo.FindByID(ctx, uuid.MustParse(userID))
-	if err != nil {
+	now := time.Now()
+	if family == "" {
+		family = uuid.NewString()
+		client := ClientFrom(ctx)
+		session := &model.Session{ID: family, IP: client.IP, UserAgent: client.UserAgent, IssuedAt: now, LastUsedAt: now}
+		if err := s.tokenRepo.CreateSession(ctx, user.ID, session, s.authCfg.RefreshTokenLifetime); err != nil {
+			return nil, err
+		}
+	} else if err := s.tokenRepo.TouchSession(ctx, user.ID, family, now); err != nil {
 		return nil, err
 	}
 
-	// Generate new access token
 	accessJTI := uuid.New().String()
-	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, JwtTokenClaim{jwt.RegisteredClaims{
-		ID:        accessJTI,
-		Subject:   user.ID.String(),
-		ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.authCfg.RefreshTokenLifetime)), // Refresh token expires in 30 days
-	}})
+	refreshJTI := uuid.New().String()
+	accessTokenString, err := s.keyService.Sign(ctx, JwtTokenClaim{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        accessJTI,
+			Subject:   user.ID.String(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.authCfg.AccessTokenLifetime)),
+		},
+		TokenType: TokenTypeAccess,
+		Family:    family,
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	accessTokenString, err := accessToken.SignedString(s.secretKey)
+	refreshTokenString, err := s.keyService.Sign(ctx, JwtTokenClaim{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        refreshJTI,
+			Subject:   user.ID.String(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.authCfg.RefreshTokenLifetime)),
+		},
+		TokenType: TokenTypeRefresh,
+		Family:    family,
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	// Store new access token in cache
-	if err := s.tokenRepo.AddJwtToken(ctx, user.ID, accessJTI, s.authCfg.AccessTokenLifetime); err != nil {
+	// Store tokens in cache
+	if err := s.tokenRepo.AddJwtToken(ctx, user.ID, family, accessJTI, s.authCfg.AccessTokenLifetime); err != nil {
+		return nil, err
+	}
+	if err := s.tokenRepo.AddJwtToken(ctx, user.ID, family, refreshJTI, s.authCfg.RefreshTokenLifetime); err != nil {
 		return nil, err
 	}
 
 	return &LoginResponse{
 		AccessToken:  accessTokenString,
-		RefreshToken: req.RefreshToken, // Return the same refresh token
+		RefreshToken: refreshTokenString,
 		User:         user,
 	}, nil
 }
 
-func (s *authService) Logout(ctx context.Context, userID uuid.UUID, jti string) error {
-	return s.tokenRepo.RemoveJwtToken(ctx, userID, jti)
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
 }
 
-func (s *authService) VerifyJwtToken(ctx context.Context, tokenString string) (*jwt.Token, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &JwtTokenClaim{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, jwt.ErrSignatureInvalid
-		}
-		return s.secretKey, nil
-	})
-
+// RefreshToken exchanges a refresh token for a new token pair and revokes it. Refresh tokens can
+// only be used once: replaying one means it leaked, so the whole family is revoked, logging out both
+// whoever replayed it and whoever refreshed with it first.
+func (s *authService) RefreshToken(ctx context.Context, req *RefreshTokenRequest) (*LoginResponse, error) {
+	_, claims, err := s.parseJwtToken(ctx, req.RefreshToken)
 	if err != nil {
 		return nil, errors.New("invalid refresh token")
 	}
+	if claims.TokenType != TokenTypeRefresh {
+		return nil, errors.New("token is not a refresh token")
+	}
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, errors.New("invalid user ID in token")
+	}
 
-	if !token.Valid {
-		return nil, errors.New("refresh token is invalid")
+	family, reused, err := s.tokenRepo.UseRefreshToken(ctx, userID, claims.ID, time.Until(claims.ExpiresAt.Time))
+	if err != nil {
+		return nil, err
+	}
+	if reused {
+		slog.WarnContext(ctx, "Refresh token reused, revoking its token family", "userID", userID, "family", family)
+		if err := s.tokenRepo.RevokeFamily(ctx, userID, family); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("refresh token has already been used")
+	}
+	if family == "" {
+		return nil, errors.New("refresh token has been revoked")
 	}
 
-	// Get claims
-	claims, ok := token.Claims.(*JwtTokenClaim)
-	if !ok {
-		return nil, errors.New("invalid token claims")
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.issueTokens(ctx, user, family)
+}
+
+func (s *authService) Logout(ctx context.Context, userID uuid.UUID, sessionID string) error {
+	return s.tokenRepo.RevokeFamily(ctx, userID, sessionID)
+}
+
+// VerifyJwtToken verifies an access token, which must not have been revoked.
+func (s *authService) VerifyJwtToken(ctx context.Context, tokenString string) (*jwt.Token, error) {
+	token, claims, err := s.parseJwtToken(ctx, tokenString)
+	if err != nil {
+		return nil, errors.New("invalid access token")
+	}
+	if claims.TokenType != TokenTypeAccess {
+		return nil, errors.New("token is not an access token")
 	}
 
 	userUUID, err := uuid.Parse(claims.Subject)
@@ -217,15 +447,44 @@ func (s *authService) VerifyJwtToken(ctx context.Context, tokenString string) (*
 		return nil, errors.New("invalid user ID in token")
 	}
 
-	// Check if refresh token is valid in repository
+	// Check if access token is valid in repository
 	isValid, err := s.tokenRepo.IsJwtValid(ctx, userUUID, claims.ID)
 	if err != nil {
 		return nil, err
 	}
 
 	if !isValid {
-		return nil, errors.New("refresh token has been revoked")
+		return nil, errors.New("access token has been revoked")
+	}
+
+	if err := s.touchSession(ctx, userUUID, claims.Family); err != nil {
+		return nil, err
 	}
 
 	return token, nil
 }
+
+// touchSession records that the session was used, once per sessionTouchInterval.
+func (s *authService) touchSession(ctx context.Context, userID uuid.UUID, family string) error {
+	session, err := s.tokenRepo.FindSession(ctx, userID, family)
+	if err != nil || session == nil {
+		return err
+	}
+	now := time.Now()
+	if now.Sub(session.LastUsedAt) < sessionTouchInterval {
+		return nil
+	}
+	return s.tokenRepo.TouchSession(ctx, userID, family, now)
+}
+
+// parseJwtToken checks the signature and expiry of a token issued by Flagon.
+func (s *authService) parseJwtToken(ctx context.Context, tokenString string) (*jwt.Token, *JwtTokenClaim, error) {
+	claims := &JwtTokenClaim{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (any, error) {
+		return s.keyService.VerificationKey(ctx, token)
+	}, jwt.WithValidMethods(SigningAlgorithms), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, nil, err
+	}
+	return token, claims, nil
+}
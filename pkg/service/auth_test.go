@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"flagon/pkg/cache"
+	"flagon/pkg/config"
+	"flagon/pkg/model"
+	"flagon/pkg/repository"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// countingTokenRepository counts the writes of session uses.
+type countingTokenRepository struct {
+	repository.TokenRepository
+	touches int
+}
+
+func (r *countingTokenRepository) TouchSession(ctx context.Context, userID uuid.UUID, id string, usedAt time.Time) error {
+	r.touches++
+	return r.TokenRepository.TouchSession(ctx, userID, id, usedAt)
+}
+
+func TestVerifyJwtTokenThrottlesSessionTouches(t *testing.T) {
+	ctx := context.Background()
+	user := &model.User{ID: uuid.New(), Username: "bob"}
+	tokens := &countingTokenRepository{TokenRepository: repository.NewTokenRepository(cache.NewMemoryCache())}
+	s := &authService{
+		userRepo:   &memoryUserRepository{users: []*model.User{user}},
+		tokenRepo:  tokens,
+		keyService: newTestSigningKeyService(t, &memorySigningKeyRepository{}, ""),
+		authCfg:    config.Authentication{AccessTokenLifetime: time.Hour, RefreshTokenLifetime: 24 * time.Hour},
+	}
+
+	login, err := s.issueTokens(ctx, user, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify := func() *model.Session {
+		t.Helper()
+		token, err := s.VerifyJwtToken(ctx, login.AccessToken)
+		if err != nil {
+			t.Fatal(err)
+		}
+		family := token.Claims.(*JwtTokenClaim).Family
+		session, err := tokens.FindSession(ctx, user.ID, family)
+		if err != nil || session == nil {
+			t.Fatalf("session %s: %v", family, err)
+		}
+		return session
+	}
+
+	for range 3 {
+		verify()
+	}
+	if tokens.touches != 0 {
+		t.Errorf("session written %d times by requests right after the login", tokens.touches)
+	}
+
+	session := verify()
+	stale := time.Now().Add(-sessionTouchInterval)
+	if err := tokens.TokenRepository.TouchSession(ctx, user.ID, session.ID, stale); err != nil {
+		t.Fatal(err)
+	}
+	if session := verify(); !session.LastUsedAt.After(stale) {
+		t.Errorf("last used at %v, want the request recorded", session.LastUsedAt)
+	}
+	verify()
+	if tokens.touches != 1 {
+		t.Errorf("session written %d times, want once per interval", tokens.touches)
+	}
+}
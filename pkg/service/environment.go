@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"flagon/pkg/model"
+	"flagon/pkg/repository"
+
+	"github.com/google/uuid"
+)
+
+type EnvironmentService interface {
+	List(ctx context.Context, projectSlug string) ([]*model.ProjectEnvironment, error)
+	Create(ctx context.Context, projectSlug string, req *CreateEnvironmentRequest) (*model.ProjectEnvironment, error)
+	Update(ctx context.Context, projectSlug string, id uuid.UUID, req *UpdateEnvironmentRequest) (*model.ProjectEnvironment, error)
+	Reorder(ctx context.Context, projectSlug string, req *ReorderEnvironmentsRequest) ([]*model.ProjectEnvironment, error)
+	Delete(ctx context.Context, projectSlug string, id uuid.UUID) error
+	Clone(ctx context.Context, projectSlug string, sourceID uuid.UUID, req *CreateEnvironmentRequest) (*model.ProjectEnvironment, error)
+}
+
+type environmentService struct {
+	envRepo        repository.EnvironmentRepository
+	projectService ProjectService
+	auditService   AuditService
+}
+
+func NewEnvironmentService(
+	envRepo repository.EnvironmentRepository,
+	projectService ProjectService,
+	auditService AuditService,
+) EnvironmentService {
+	return &environmentService{
+		envRepo:        envRepo,
+		projectService: projectService,
+		auditService:   auditService,
+	}
+}
+
+type CreateEnvironmentRequest struct {
+	Name        string `json:"name" binding:"required,max=64"`
+	Description string `json:"description"`
+	// Protected environments only let admins change flag states.
+	Protected bool `json:"protected"`
+}
+
+type UpdateEnvironmentRequest struct {
+	Name        *string `json:"name" binding:"omitempty,min=1,max=64"`
+	Description *string `json:"description"`
+	Protected   *bool   `json:"protected"`
+}
+
+type ReorderEnvironmentsRequest struct {
+	// EnvironmentIDs lists every environment of the project in the desired order.
+	EnvironmentIDs []uuid.UUID `json:"environment_ids" binding:"required"`
+}
+
+func (s *environmentService) List(ctx context.Context, projectSlug string) ([]*model.ProjectEnvironment, error) {
+	project, err := s.projectService.Get(ctx, projectSlug)
+	if err != nil {
+		return nil, err
+	}
+	return s.envRepo.FindByProject(ctx, project.ID)
+}
+
+func (s *environmentService) Create(ctx context.Context, projectSlug string, req *CreateEnvironmentRequest) (*model.ProjectEnvironment, error) {
+	project, err := s.projectService.Get(ctx, projectSlug)
+	if err != nil {
+		return nil, err
+	}
+	env, err := s.newEnvironment(ctx, project.ID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.envRepo.Create(ctx, env); err != nil {
+		return nil, translateError(err, "environment")
+	}
+	s.auditService.Record(ctx, model.AuditActionCreate, auditEnvironment(env), nil, env)
+	return env, nil
+}
+
+func (s *environmentService) Update(ctx context.Context, projectSlug string, id uuid.UUID, req *UpdateEnvironmentRequest) (*model.ProjectEnvironment, error) {
+	env, err := s.find(ctx, projectSlug, id)
+	if err != nil {
+		return nil, err
+	}
+	before := *env
+
+	if req.Name != nil {
+		env.Name = *req.Name
+	}
+	if req.Description != nil {
+		env.Description = *req.Description
+	}
+	if req.Protected != nil {
+		env.Protected = *req.Protected
+	}
+
+	if err := s.envRepo.Update(ctx, env); err != nil {
+		return nil, translateError(err, "environment")
+	}
+	s.auditService.Record(ctx, model.AuditActionUpdate, auditEnvironment(env), &before, env)
+	return env, nil
+}
+
+// Reorder sets the order of all environments of the project, auditing each one that moved.
+func (s *environmentService) Reorder(ctx context.Context, projectSlug string, req *ReorderEnvironmentsRequest) ([]*model.ProjectEnvironment, error) {
+	project, err := s.projectService.Get(ctx, projectSlug)
+	if err != nil {
+		return nil, err
+	}
+	envs, err := s.envRepo.FindByProject(ctx, project.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(req.EnvironmentIDs) != len(envs) {
+		return nil, invalidArgument("environment_ids must list all %d environments of the project", len(envs))
+	}
+	before := make(map[uuid.UUID]model.ProjectEnvironment, len(envs))
+	for _, env := range envs {
+		before[env.ID] = *env
+	}
+	seen := make(map[uuid.UUID]bool, len(envs))
+	for _, id := range req.EnvironmentIDs {
+		if _, ok := before[id]; !ok || seen[id] {
+			return nil, invalidArgument("environment %s is unknown or listed twice", id)
+		}
+		seen[id] = true
+	}
+
+	if err := s.envRepo.UpdatePositions(ctx, project.ID, req.EnvironmentIDs); err != nil {
+		return nil, err
+	}
+	reordered, err := s.envRepo.FindByProject(ctx, project.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, env := range reordered {
+		if old, ok := before[env.ID]; ok && old.Position != env.Position {
+			s.auditService.Record(ctx, model.AuditActionUpdate, auditEnvironment(env), &old, env)
+		}
+	}
+	return reordered, nil
+}
+
+func (s *environmentService) Delete(ctx context.Context, projectSlug string, id uuid.UUID) error {
+	env, err := s.find(ctx, projectSlug, id)
+	if err != nil {
+		return err
+	}
+	if err := s.envRepo.Delete(ctx, env.ID); err != nil {
+		return err
+	}
+	s.auditService.Record(ctx, model.AuditActionDelete, auditEnvironment(env), env, nil)
+	return nil
+}
+
+// Clone creates a new environment whose flag states and target group links are copied from the source environment.
+func (s *environmentService) Clone(ctx context.Context, projectSlug string, sourceID uuid.UUID, req *CreateEnvironmentRequest) (*model.ProjectEnvironment, error) {
+	source, err := s.find(ctx, projectSlug, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	env, err := s.newEnvironment(ctx, source.ProjectID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.envRepo.Clone(ctx, source.ID, env); err != nil {
+		return nil, translateError(err, "environment")
+	}
+	s.auditService.Record(ctx, model.AuditActionCreate, auditEnvironment(env), nil, env)
+	return env, nil
+}
+
+// newEnvironment builds an environment positioned after the existing ones of the project.
+func (s *environmentService) newEnvironment(ctx context.Context, projectID uuid.UUID, req *CreateEnvironmentRequest) (*model.ProjectEnvironment, error) {
+	existing, err := s.envRepo.FindByProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	position := 0
+	for _, env := range existing {
+		position = max(position, env.Position+1)
+	}
+
+	return &model.ProjectEnvironment{
+		ID:          uuid.New(),
+		ProjectID:   projectID,
+		Name:        req.Name,
+		Description: req.Description,
+		Position:    position,
+		Protected:   req.Protected,
+	}, nil
+}
+
+func (s *environmentService) find(ctx context.Context, projectSlug string, id uuid.UUID) (*model.ProjectEnvironment, error) {
+	project, err := s.projectService.Get(ctx, projectSlug)
+	if err != nil {
+		return nil, err
+	}
+	env, err := s.envRepo.FindByID(ctx, project.ID, id)
+	if err != nil {
+		return nil, translateError(err, "environment")
+	}
+	return env, nil
+}
+
+func auditEnvironment(env *model.ProjectEnvironment) AuditResource {
+	return projectAudit(model.AuditResourceEnvironment, env.ID.String(), env.ProjectID)
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"flagon/pkg/model"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func (r *memoryEnvironmentRepository) Create(_ context.Context, env *model.ProjectEnvironment) error {
+	r.envs = append(r.envs, env)
+	return nil
+}
+
+func (r *memoryEnvironmentRepository) Update(context.Context, *model.ProjectEnvironment) error {
+	return nil
+}
+
+func (r *memoryEnvironmentRepository) Delete(_ context.Context, id uuid.UUID) error {
+	r.envs = slices.DeleteFunc(r.envs, func(env *model.ProjectEnvironment) bool { return env.ID == id })
+	return nil
+}
+
+func (r *memoryEnvironmentRepository) UpdatePositions(_ context.Context, projectID uuid.UUID, orderedIDs []uuid.UUID) error {
+	for position, id := range orderedIDs {
+		for _, env := range r.envs {
+			if env.ProjectID == projectID && env.ID == id {
+				env.Position = position
+			}
+		}
+	}
+	return nil
+}
+
+// newEnvironmentFixture returns the environment service of a project with the staging and
+// production environments, in that order.
+func newEnvironmentFixture() (EnvironmentService, *memoryEnvironmentRepository, *recordingAuditService, *model.Project) {
+	owner := &model.User{ID: uuid.New(), Username: "owner"}
+	project := &model.Project{ID: uuid.New(), Slug: "shop", OwnerID: owner.ID}
+	envs := &memoryEnvironmentRepository{envs: []*model.ProjectEnvironment{
+		{ID: uuid.New(), ProjectID: project.ID, Name: "Staging", Position: 0},
+		{ID: uuid.New(), ProjectID: project.ID, Name: "Production", Position: 1},
+	}}
+	projects := &memoryProjectRepository{projects: []*model.Project{project}}
+	users := &memoryUserRepository{users: []*model.User{owner}}
+	projectService := NewProjectService(projects, users, nil, NewAccessService(nil, projects, envs, nil), nil)
+	audit := &recordingAuditService{}
+	return NewEnvironmentService(envs, projectService, audit), envs, audit, project
+}
+
+func TestReorderEnvironments(t *testing.T) {
+	ctx := context.Background()
+	s, envs, audit, project := newEnvironmentFixture()
+	staging, production := envs.envs[0], envs.envs[1]
+	development := &model.ProjectEnvironment{ID: uuid.New(), ProjectID: project.ID, Name: "Development", Position: 2}
+	envs.envs = append(envs.envs, development)
+
+	reordered, err := s.Reorder(ctx, project.Slug, &ReorderEnvironmentsRequest{
+		EnvironmentIDs: []uuid.UUID{development.ID, staging.ID, production.ID},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var order []string
+	for _, env := range reordered {
+		order = append(order, env.Name)
+	}
+	if !slices.Equal(order, []string{"Development", "Staging", "Production"}) {
+		t.Errorf("order %v, want Development, Staging, Production", order)
+	}
+
+	// Every environment moved, each one is audited with its position before and after.
+	want := map[uuid.UUID][2]int{development.ID: {2, 0}, staging.ID: {0, 1}, production.ID: {1, 2}}
+	if len(audit.records) != len(want) {
+		t.Fatalf("%d audit records, want %d", len(audit.records), len(want))
+	}
+	for _, record := range audit.records {
+		before, after := record.before.(*model.ProjectEnvironment), record.after.(*model.ProjectEnvironment)
+		positions, ok := want[after.ID]
+		if !ok || record.action != model.AuditActionUpdate || record.resource.Type != model.AuditResourceEnvironment ||
+			record.resource.ID != after.ID.String() || *record.resource.ProjectID != project.ID {
+			t.Errorf("audit record %s of %s %s, want an update of an environment of the project",
+				record.action, record.resource.Type, record.resource.ID)
+			continue
+		}
+		if before.ID != after.ID || before.Position != positions[0] || after.Position != positions[1] {
+			t.Errorf("%s moved from %d to %d, want from %d to %d", after.Name, before.Position, after.Position, positions[0], positions[1])
+		}
+	}
+
+	// Environments keeping their position are not audited.
+	audit.records = nil
+	if _, err := s.Reorder(ctx, project.Slug, &ReorderEnvironmentsRequest{
+		EnvironmentIDs: []uuid.UUID{development.ID, production.ID, staging.ID},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(audit.records) != 2 {
+		t.Errorf("%d audit records after swapping two environments, want 2", len(audit.records))
+	}
+}
+
+func TestReorderEnvironmentsValidation(t *testing.T) {
+	ctx := context.Background()
+	s, envs, audit, project := newEnvironmentFixture()
+	staging, production := envs.envs[0], envs.envs[1]
+
+	tests := []struct {
+		name string
+		ids  []uuid.UUID
+	}{
+		{name: "missing environment", ids: []uuid.UUID{production.ID}},
+		{name: "listed twice", ids: []uuid.UUID{production.ID, production.ID}},
+		{name: "unknown environment", ids: []uuid.UUID{production.ID, uuid.New()}},
+		{name: "too many", ids: []uuid.UUID{production.ID, staging.ID, uuid.New()}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.Reorder(ctx, project.Slug, &ReorderEnvironmentsRequest{EnvironmentIDs: tt.ids})
+			if !errors.Is(err, ErrInvalidArgument) {
+				t.Errorf("Reorder: %v, want ErrInvalidArgument", err)
+			}
+		})
+	}
+	if staging.Position != 0 || production.Position != 1 || len(audit.records) != 0 {
+		t.Errorf("rejected orders changed positions to %d, %d with %d audit records", staging.Position, production.Position, len(audit.records))
+	}
+}
+
+func TestEnvironmentAudit(t *testing.T) {
+	ctx := context.Background()
+	s, envs, audit, project := newEnvironmentFixture()
+	staging := envs.envs[0]
+	var created *model.ProjectEnvironment
+	name := "QA"
+
+	// Each step records one change, checked before the next step changes the environment again.
+	steps := []struct {
+		name       string
+		do         func() error
+		action     model.AuditAction
+		id         func() uuid.UUID
+		beforeName string
+		afterName  string
+	}{
+		{
+			name: "create",
+			do: func() (err error) {
+				created, err = s.Create(ctx, project.Slug, &CreateEnvironmentRequest{Name: "Development"})
+				return err
+			},
+			action:    model.AuditActionCreate,
+			id:        func() uuid.UUID { return created.ID },
+			afterName: "Development",
+		},
+		{
+			name: "update",
+			do: func() error {
+				_, err := s.Update(ctx, project.Slug, created.ID, &UpdateEnvironmentRequest{Name: &name})
+				return err
+			},
+			action:     model.AuditActionUpdate,
+			id:         func() uuid.UUID { return created.ID },
+			beforeName: "Development",
+			afterName:  "QA",
+		},
+		{
+			name:       "delete",
+			do:         func() error { return s.Delete(ctx, project.Slug, staging.ID) },
+			action:     model.AuditActionDelete,
+			id:         func() uuid.UUID { return staging.ID },
+			beforeName: "Staging",
+		},
+	}
+	for _, step := range steps {
+		audit.records = nil
+		if err := step.do(); err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if len(audit.records) != 1 {
+			t.Fatalf("%s: %d audit records, want 1", step.name, len(audit.records))
+		}
+		record := audit.records[0]
+		if record.action != step.action || record.resource.Type != model.AuditResourceEnvironment ||
+			record.resource.ID != step.id().String() || *record.resource.ProjectID != project.ID {
+			t.Errorf("%s: %s of %s %s, want %s of environment %s",
+				step.name, record.action, record.resource.Type, record.resource.ID, step.action, step.id())
+		}
+		if got := environmentName(record.before); got != step.beforeName {
+			t.Errorf("%s: before %q, want %q", step.name, got, step.beforeName)
+		}
+		if got := environmentName(record.after); got != step.afterName {
+			t.Errorf("%s: after %q, want %q", step.name, got, step.afterName)
+		}
+	}
+}
+
+// environmentName returns the name of an audited environment, or empty when there is none.
+func environmentName(state any) string {
+	env, _ := state.(*model.ProjectEnvironment)
+	if env == nil {
+		return ""
+	}
+	return env.Name
+}
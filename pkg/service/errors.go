@@ -0,0 +1,53 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrNotFound is returned when the requested resource does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrAlreadyExists is returned when a resource violates a uniqueness constraint.
+	ErrAlreadyExists = errors.New("already exists")
+	// ErrInvalidArgument is returned when a request is well-formed but semantically invalid.
+	ErrInvalidArgument = errors.New("invalid argument")
+	// ErrPermissionDenied is returned when the caller is not allowed to perform the operation.
+	ErrPermissionDenied = errors.New("permission denied")
+	// ErrInvalidToken is returned when an access token is unknown or cannot be used for the request.
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrAuthenticationFailed is returned when a login cannot be completed.
+	ErrAuthenticationFailed = errors.New("authentication failed")
+)
+
+// AccountLockedError is returned by password logins while the account is locked out after failed
+// logins in a row.
+type AccountLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *AccountLockedError) Error() string {
+	return fmt.Sprintf("too many failed logins, try again in %s", e.RetryAfter.Round(time.Second))
+}
+
+// translateError maps repository errors to service errors prefixed with the resource name,
+// so handlers can pick a status code with errors.Is.
+func translateError(err error, resource string) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return fmt.Errorf("%s %w", resource, ErrNotFound)
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return fmt.Errorf("%s %w", resource, ErrAlreadyExists)
+	default:
+		return err
+	}
+}
+
+func invalidArgument(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidArgument, fmt.Sprintf(format, args...))
+}
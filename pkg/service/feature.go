@@ -0,0 +1,394 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"flagon/pkg/model"
+	"flagon/pkg/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type FeatureService interface {
+	ListCategories(ctx context.Context, projectSlug string) ([]*model.Category, error)
+	CreateCategory(ctx context.Context, projectSlug string, req *CreateCategoryRequest) (*model.Category, error)
+	UpdateCategory(ctx context.Context, projectSlug string, id uuid.UUID, req *UpdateCategoryRequest) (*model.Category, error)
+	DeleteCategory(ctx context.Context, projectSlug string, id uuid.UUID) error
+
+	List(ctx context.Context, projectSlug string, categoryID *uuid.UUID) ([]*model.Feature, error)
+	Get(ctx context.Context, projectSlug, key string) (*model.Feature, error)
+	Create(ctx context.Context, projectSlug string, req *CreateFeatureRequest) (*model.Feature, error)
+	Update(ctx context.Context, projectSlug, key string, req *UpdateFeatureRequest) (*model.Feature, error)
+	Delete(ctx context.Context, projectSlug, key string) error
+
+	ListFlags(ctx context.Context, projectSlug, key string) ([]*model.ProjectFeatureFlag, error)
+	SetFlag(ctx context.Context, projectSlug, key string, envID uuid.UUID, req *SetFlagRequest) (*model.ProjectFeatureFlag, error)
+	ClearFlag(ctx context.Context, projectSlug, key string, envID uuid.UUID) error
+	SetTargetFlag(ctx context.Context, projectSlug, key string, envID, targetGroupID uuid.UUID, req *SetFlagRequest) (*model.ProjectFeatureFlag, error)
+	ClearTargetFlag(ctx context.Context, projectSlug, key string, envID, targetGroupID uuid.UUID) error
+}
+
+type featureService struct {
+	featureRepo     repository.FeatureRepository
+	categoryRepo    repository.CategoryRepository
+	envRepo         repository.EnvironmentRepository
+	targetGroupRepo repository.TargetGroupRepository
+	projectService  ProjectService
+	streamService   StreamService
+	auditService    AuditService
+}
+
+func NewFeatureService(
+	featureRepo repository.FeatureRepository,
+	categoryRepo repository.CategoryRepository,
+	envRepo repository.EnvironmentRepository,
+	targetGroupRepo repository.TargetGroupRepository,
+	projectService ProjectService,
+	streamService StreamService,
+	auditService AuditService,
+) FeatureService {
+	return &featureService{
+		featureRepo:     featureRepo,
+		categoryRepo:    categoryRepo,
+		envRepo:         envRepo,
+		targetGroupRepo: targetGroupRepo,
+		projectService:  projectService,
+		streamService:   streamService,
+		auditService:    auditService,
+	}
+}
+
+type CreateCategoryRequest struct {
+	Name        string `json:"name" binding:"required,max=255"`
+	Description string `json:"description"`
+}
+
+type UpdateCategoryRequest struct {
+	Name        *string `json:"name" binding:"omitempty,min=1,max=255"`
+	Description *string `json:"description"`
+}
+
+type CreateFeatureRequest struct {
+	Key          string    `json:"key" binding:"required"`
+	CategoryID   uuid.UUID `json:"category_id" binding:"required"`
+	Description  string    `json:"description"`
+	DefaultValue bool      `json:"default_value"`
+}
+
+type UpdateFeatureRequest struct {
+	Key          *string    `json:"key"`
+	CategoryID   *uuid.UUID `json:"category_id"`
+	Description  *string    `json:"description"`
+	DefaultValue *bool      `json:"default_value"`
+}
+
+type SetFlagRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+func (s *featureService) ListCategories(ctx context.Context, projectSlug string) ([]*model.Category, error) {
+	project, err := s.projectService.Get(ctx, projectSlug)
+	if err != nil {
+		return nil, err
+	}
+	return s.categoryRepo.FindByProject(ctx, project.ID)
+}
+
+func (s *featureService) CreateCategory(ctx context.Context, projectSlug string, req *CreateCategoryRequest) (*model.Category, error) {
+	project, err := s.projectService.Get(ctx, projectSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	category := &model.Category{
+		ID:          uuid.New(),
+		ProjectID:   project.ID,
+		Name:        req.Name,
+		Description: req.Description,
+	}
+	if err := s.categoryRepo.Create(ctx, category); err != nil {
+		return nil, translateError(err, "category")
+	}
+	s.auditService.Record(ctx, model.AuditActionCreate, auditCategory(category), nil, category)
+	return category, nil
+}
+
+func (s *featureService) UpdateCategory(ctx context.Context, projectSlug string, id uuid.UUID, req *UpdateCategoryRequest) (*model.Category, error) {
+	category, err := s.findCategory(ctx, projectSlug, id)
+	if err != nil {
+		return nil, err
+	}
+	before := *category
+
+	if req.Name != nil {
+		category.Name = *req.Name
+	}
+	if req.Description != nil {
+		category.Description = *req.Description
+	}
+
+	if err := s.categoryRepo.Update(ctx, category); err != nil {
+		return nil, translateError(err, "category")
+	}
+	s.auditService.Record(ctx, model.AuditActionUpdate, auditCategory(category), &before, category)
+	return category, nil
+}
+
+// DeleteCategory removes the category and, through ON DELETE CASCADE, every feature in it.
+func (s *featureService) DeleteCategory(ctx context.Context, projectSlug string, id uuid.UUID) error {
+	category, err := s.findCategory(ctx, projectSlug, id)
+	if err != nil {
+		return err
+	}
+	if err := s.categoryRepo.Delete(ctx, category.ID); err != nil {
+		return err
+	}
+	s.auditService.Record(ctx, model.AuditActionDelete, auditCategory(category), category, nil)
+	return nil
+}
+
+func (s *featureService) List(ctx context.Context, projectSlug string, categoryID *uuid.UUID) ([]*model.Feature, error) {
+	project, err := s.projectService.Get(ctx, projectSlug)
+	if err != nil {
+		return nil, err
+	}
+	return s.featureRepo.FindByProject(ctx, project.ID, categoryID)
+}
+
+func (s *featureService) Get(ctx context.Context, projectSlug, key string) (*model.Feature, error) {
+	project, err := s.projectService.Get(ctx, projectSlug)
+	if err != nil {
+		return nil, err
+	}
+	feature, err := s.featureRepo.FindByKey(ctx, project.ID, key)
+	if err != nil {
+		return nil, translateError(err, "feature")
+	}
+	return feature, nil
+}
+
+func (s *featureService) Create(ctx context.Context, projectSlug string, req *CreateFeatureRequest) (*model.Feature, error) {
+	if err := validateFeatureKey(req.Key); err != nil {
+		return nil, err
+	}
+	project, err := s.projectService.Get(ctx, projectSlug)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.categoryRepo.FindByID(ctx, project.ID, req.CategoryID); err != nil {
+		return nil, translateError(err, "category")
+	}
+
+	feature := &model.Feature{
+		ID:           uuid.New(),
+		ProjectID:    project.ID,
+		Key:          req.Key,
+		CategoryID:   req.CategoryID,
+		Description:  req.Description,
+		DefaultValue: req.DefaultValue,
+	}
+	if err := s.featureRepo.Create(ctx, feature); err != nil {
+		return nil, translateError(err, "feature")
+	}
+	s.streamService.PublishFlags(ctx, project.ID, nil, feature.Key)
+	s.auditService.Record(ctx, model.AuditActionCreate, auditFeature(feature), nil, feature)
+	return feature, nil
+}
+
+func (s *featureService) Update(ctx context.Context, projectSlug, key string, req *UpdateFeatureRequest) (*model.Feature, error) {
+	feature, err := s.Get(ctx, projectSlug, key)
+	if err != nil {
+		return nil, err
+	}
+	before := *feature
+
+	if req.Key != nil {
+		if err := validateFeatureKey(*req.Key); err != nil {
+			return nil, err
+		}
+		feature.Key = *req.Key
+	}
+	if req.CategoryID != nil {
+		if _, err := s.categoryRepo.FindByID(ctx, feature.ProjectID, *req.CategoryID); err != nil {
+			return nil, translateError(err, "category")
+		}
+		feature.CategoryID = *req.CategoryID
+	}
+	if req.Description != nil {
+		feature.Description = *req.Description
+	}
+	if req.DefaultValue != nil {
+		feature.DefaultValue = *req.DefaultValue
+	}
+
+	if err := s.featureRepo.Update(ctx, feature); err != nil {
+		return nil, translateError(err, "feature")
+	}
+	if feature.Key != key {
+		s.streamService.PublishFlags(ctx, feature.ProjectID, nil, key, feature.Key)
+	} else {
+		s.streamService.PublishFlags(ctx, feature.ProjectID, nil, key)
+	}
+	s.auditService.Record(ctx, model.AuditActionUpdate, auditFeature(feature), &before, feature)
+	return feature, nil
+}
+
+func (s *featureService) Delete(ctx context.Context, projectSlug, key string) error {
+	feature, err := s.Get(ctx, projectSlug, key)
+	if err != nil {
+		return err
+	}
+	if err := s.featureRepo.Delete(ctx, feature.ID); err != nil {
+		return err
+	}
+	s.streamService.PublishFlags(ctx, feature.ProjectID, nil, feature.Key)
+	s.auditService.Record(ctx, model.AuditActionDelete, auditFeature(feature), feature, nil)
+	return nil
+}
+
+func (s *featureService) ListFlags(ctx context.Context, projectSlug, key string) ([]*model.ProjectFeatureFlag, error) {
+	feature, err := s.Get(ctx, projectSlug, key)
+	if err != nil {
+		return nil, err
+	}
+	return s.featureRepo.FindFlags(ctx, feature.ID)
+}
+
+// SetFlag turns the feature on or off for everyone in the environment.
+func (s *featureService) SetFlag(ctx context.Context, projectSlug, key string, envID uuid.UUID, req *SetFlagRequest) (*model.ProjectFeatureFlag, error) {
+	feature, err := s.Get(ctx, projectSlug, key)
+	if err != nil {
+		return nil, err
+	}
+	env, err := s.envRepo.FindByID(ctx, feature.ProjectID, envID)
+	if err != nil {
+		return nil, translateError(err, "environment")
+	}
+	return s.saveFlag(ctx, feature, env, nil, req.Enabled)
+}
+
+// ClearFlag removes the environment's override so the feature falls back to its default value.
+func (s *featureService) ClearFlag(ctx context.Context, projectSlug, key string, envID uuid.UUID) error {
+	feature, err := s.Get(ctx, projectSlug, key)
+	if err != nil {
+		return err
+	}
+	env, err := s.envRepo.FindByID(ctx, feature.ProjectID, envID)
+	if err != nil {
+		return translateError(err, "environment")
+	}
+	return s.deleteFlag(ctx, feature, env, nil)
+}
+
+// SetTargetFlag turns the feature on or off for the members of a target group in the environment.
+// The state only takes effect while the target group is enabled in the environment.
+func (s *featureService) SetTargetFlag(ctx context.Context, projectSlug, key string, envID, targetGroupID uuid.UUID, req *SetFlagRequest) (*model.ProjectFeatureFlag, error) {
+	feature, env, group, err := s.findTarget(ctx, projectSlug, key, envID, targetGroupID)
+	if err != nil {
+		return nil, err
+	}
+	return s.saveFlag(ctx, feature, env, &group.ID, req.Enabled)
+}
+
+func (s *featureService) ClearTargetFlag(ctx context.Context, projectSlug, key string, envID, targetGroupID uuid.UUID) error {
+	feature, env, group, err := s.findTarget(ctx, projectSlug, key, envID, targetGroupID)
+	if err != nil {
+		return err
+	}
+	return s.deleteFlag(ctx, feature, env, &group.ID)
+}
+
+func (s *featureService) saveFlag(ctx context.Context, feature *model.Feature, env *model.ProjectEnvironment, targetGroupID *uuid.UUID, enabled bool) (*model.ProjectFeatureFlag, error) {
+	before, err := s.findFlag(ctx, feature.ID, env.ID, targetGroupID)
+	if err != nil {
+		return nil, err
+	}
+	flag := &model.ProjectFeatureFlag{
+		ProjectID:     feature.ProjectID,
+		FeatureID:     feature.ID,
+		EnvironmentID: &env.ID,
+		TargetGroupID: targetGroupID,
+		Enabled:       enabled,
+	}
+	if err := s.featureRepo.SaveFlag(ctx, flag); err != nil {
+		return nil, err
+	}
+	s.streamService.PublishFlags(ctx, feature.ProjectID, []uuid.UUID{env.ID}, feature.Key)
+	action := model.AuditActionUpdate
+	if before == nil {
+		action = model.AuditActionCreate
+	}
+	s.auditService.Record(ctx, action, auditFlag(feature, env.ID, targetGroupID), before, flag)
+	return flag, nil
+}
+
+func (s *featureService) deleteFlag(ctx context.Context, feature *model.Feature, env *model.ProjectEnvironment, targetGroupID *uuid.UUID) error {
+	before, err := s.findFlag(ctx, feature.ID, env.ID, targetGroupID)
+	if err != nil {
+		return err
+	}
+	if err := s.featureRepo.DeleteFlag(ctx, feature.ID, env.ID, targetGroupID); err != nil {
+		return err
+	}
+	s.streamService.PublishFlags(ctx, feature.ProjectID, []uuid.UUID{env.ID}, feature.Key)
+	if before != nil {
+		s.auditService.Record(ctx, model.AuditActionDelete, auditFlag(feature, env.ID, targetGroupID), before, nil)
+	}
+	return nil
+}
+
+// findFlag returns the flag state, or nil when the feature uses its default value.
+func (s *featureService) findFlag(ctx context.Context, featureID, envID uuid.UUID, targetGroupID *uuid.UUID) (*model.ProjectFeatureFlag, error) {
+	flag, err := s.featureRepo.FindFlag(ctx, featureID, envID, targetGroupID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return flag, err
+}
+
+func (s *featureService) findTarget(ctx context.Context, projectSlug, key string, envID, targetGroupID uuid.UUID) (*model.Feature, *model.ProjectEnvironment, *model.TargetGroup, error) {
+	feature, err := s.Get(ctx, projectSlug, key)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	env, err := s.envRepo.FindByID(ctx, feature.ProjectID, envID)
+	if err != nil {
+		return nil, nil, nil, translateError(err, "environment")
+	}
+	group, err := s.targetGroupRepo.FindByID(ctx, feature.ProjectID, targetGroupID)
+	if err != nil {
+		return nil, nil, nil, translateError(err, "target group")
+	}
+	return feature, env, group, nil
+}
+
+func (s *featureService) findCategory(ctx context.Context, projectSlug string, id uuid.UUID) (*model.Category, error) {
+	project, err := s.projectService.Get(ctx, projectSlug)
+	if err != nil {
+		return nil, err
+	}
+	category, err := s.categoryRepo.FindByID(ctx, project.ID, id)
+	if err != nil {
+		return nil, translateError(err, "category")
+	}
+	return category, nil
+}
+
+func auditCategory(category *model.Category) AuditResource {
+	return projectAudit(model.AuditResourceCategory, category.ID.String(), category.ProjectID)
+}
+
+func auditFeature(feature *model.Feature) AuditResource {
+	return projectAudit(model.AuditResourceFeature, feature.ID.String(), feature.ProjectID)
+}
+
+// auditFlag identifies a flag state as feature key/environment ID, followed by /target group ID for
+// the state of a target group.
+func auditFlag(feature *model.Feature, envID uuid.UUID, targetGroupID *uuid.UUID) AuditResource {
+	id := feature.Key + "/" + envID.String()
+	if targetGroupID != nil {
+		id += "/" + targetGroupID.String()
+	}
+	return projectAudit(model.AuditResourceFlag, id, feature.ProjectID)
+}
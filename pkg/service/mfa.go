@@ -0,0 +1,269 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"flagon/pkg/config"
+	"flagon/pkg/model"
+	"flagon/pkg/repository"
+	"flagon/pkg/totp"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	recoveryCodeCount = 10
+	// recoveryCodeSize is the number of random bytes of a recovery code, 16 base32 characters.
+	recoveryCodeSize = 10
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// MFAService manages the TOTP authenticators and recovery codes of users. An authenticator is set
+// up in two steps: Enroll returns its secret, and Confirm enables it once the user has entered a
+// first code, so a mistyped secret cannot lock them out.
+type MFAService interface {
+	Status(ctx context.Context, userID uuid.UUID) (*MFAStatus, error)
+	Enroll(ctx context.Context, userID uuid.UUID) (*MFAEnrollment, error)
+	// Confirm enables the authenticator and returns the recovery codes. The other sessions of the
+	// user, started without the second factor, are revoked.
+	Confirm(ctx context.Context, userID uuid.UUID, sessionID string, req *MFACodeRequest) (*RecoveryCodes, error)
+	Disable(ctx context.Context, userID uuid.UUID, req *MFACodeRequest) error
+	// RegenerateRecoveryCodes replaces the recovery codes of the user.
+	RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, req *MFACodeRequest) (*RecoveryCodes, error)
+
+	// IsEnabled reports whether the user has to enter a code when logging in.
+	IsEnabled(ctx context.Context, userID uuid.UUID) (bool, error)
+	// Verify checks a code of the authenticator of the user, or one of their recovery codes. Wrong
+	// codes return ErrAuthenticationFailed.
+	Verify(ctx context.Context, userID uuid.UUID, code string) error
+}
+
+type mfaService struct {
+	mfaRepo        repository.UserMFARepository
+	userRepo       repository.UserRepository
+	sessionService SessionService
+	issuer         string
+}
+
+func NewMFAService(
+	mfaRepo repository.UserMFARepository,
+	userRepo repository.UserRepository,
+	sessionService SessionService,
+) MFAService {
+	return &mfaService{
+		mfaRepo:        mfaRepo,
+		userRepo:       userRepo,
+		sessionService: sessionService,
+		issuer:         config.GetConfig().Auth.MFA.Issuer,
+	}
+}
+
+type MFAStatus struct {
+	Enabled   bool       `json:"enabled"`
+	EnabledAt *time.Time `json:"enabledAt"`
+	// RecoveryCodesRemaining is the number of unused recovery codes.
+	RecoveryCodesRemaining int64 `json:"recoveryCodesRemaining"`
+}
+
+// MFAEnrollment is the secret of a new authenticator. ProvisioningURI is an otpauth:// URI to show
+// as a QR code; the secret can be typed in instead.
+type MFAEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioningUri"`
+}
+
+// RecoveryCodes are only ever returned once; each logs in once without the authenticator.
+type RecoveryCodes struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// MFACodeRequest carries a code of the authenticator, or a recovery code where accepted.
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+func (s *mfaService) Status(ctx context.Context, userID uuid.UUID) (*MFAStatus, error) {
+	mfa, err := s.find(ctx, userID)
+	if err != nil || !mfa.Enabled() {
+		return &MFAStatus{}, err
+	}
+	remaining, err := s.mfaRepo.CountRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &MFAStatus{Enabled: true, EnabledAt: mfa.EnabledAt, RecoveryCodesRemaining: remaining}, nil
+}
+
+func (s *mfaService) Enroll(ctx context.Context, userID uuid.UUID) (*MFAEnrollment, error) {
+	mfa, err := s.find(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if mfa.Enabled() {
+		return nil, fmt.Errorf("two-factor authentication %w; disable it first", ErrAlreadyExists)
+	}
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, translateError(err, "user")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.Save(ctx, &model.UserMFA{UserID: userID, Secret: secret}); err != nil {
+		return nil, err
+	}
+	return &MFAEnrollment{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(s.issuer, user.Username, secret),
+	}, nil
+}
+
+func (s *mfaService) Confirm(ctx context.Context, userID uuid.UUID, sessionID string, req *MFACodeRequest) (*RecoveryCodes, error) {
+	mfa, err := s.find(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if mfa == nil {
+		return nil, invalidArgument("set up an authenticator first")
+	}
+	if mfa.Enabled() {
+		return nil, fmt.Errorf("two-factor authentication %w", ErrAlreadyExists)
+	}
+	counter, ok := totp.Validate(mfa.Secret, req.Code, time.Now())
+	if !ok {
+		return nil, invalidArgument("invalid code")
+	}
+
+	codes, records, err := generateRecoveryCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.Enable(ctx, userID, counter, records); err != nil {
+		return nil, err
+	}
+	if err := s.sessionService.RevokeOthers(ctx, userID, sessionID); err != nil {
+		return nil, err
+	}
+	return &RecoveryCodes{RecoveryCodes: codes}, nil
+}
+
+func (s *mfaService) Disable(ctx context.Context, userID uuid.UUID, req *MFACodeRequest) error {
+	if err := s.verifyEnabled(ctx, userID, req.Code); err != nil {
+		return err
+	}
+	return s.mfaRepo.Delete(ctx, userID)
+}
+
+func (s *mfaService) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, req *MFACodeRequest) (*RecoveryCodes, error) {
+	if err := s.verifyEnabled(ctx, userID, req.Code); err != nil {
+		return nil, err
+	}
+	codes, records, err := generateRecoveryCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.ReplaceRecoveryCodes(ctx, userID, records); err != nil {
+		return nil, err
+	}
+	return &RecoveryCodes{RecoveryCodes: codes}, nil
+}
+
+// verifyEnabled checks a code for changes to an enabled authenticator, which the caller is
+// authenticated for, so wrong codes are invalid arguments rather than failed logins.
+func (s *mfaService) verifyEnabled(ctx context.Context, userID uuid.UUID, code string) error {
+	err := s.Verify(ctx, userID, code)
+	if errors.Is(err, ErrAuthenticationFailed) {
+		return invalidArgument("invalid code")
+	}
+	return err
+}
+
+func (s *mfaService) IsEnabled(ctx context.Context, userID uuid.UUID) (bool, error) {
+	mfa, err := s.find(ctx, userID)
+	return mfa.Enabled(), err
+}
+
+func (s *mfaService) Verify(ctx context.Context, userID uuid.UUID, code string) error {
+	mfa, err := s.find(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !mfa.Enabled() {
+		return invalidArgument("two-factor authentication is not enabled")
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) != totp.Digits {
+		used, err := s.mfaRepo.UseRecoveryCode(ctx, userID, hashRecoveryCode(code))
+		if err != nil {
+			return err
+		}
+		if !used {
+			return fmt.Errorf("%w: invalid recovery code", ErrAuthenticationFailed)
+		}
+		return nil
+	}
+
+	counter, ok := totp.Validate(mfa.Secret, code, time.Now())
+	if !ok {
+		return fmt.Errorf("%w: invalid code", ErrAuthenticationFailed)
+	}
+	// A code seen by someone looking over the user's shoulder must not work a second time.
+	fresh, err := s.mfaRepo.UseCounter(ctx, userID, counter)
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		return fmt.Errorf("%w: code already used, wait for the next one", ErrAuthenticationFailed)
+	}
+	return nil
+}
+
+// find returns the authenticator of the user, or nil when they have none.
+func (s *mfaService) find(ctx context.Context, userID uuid.UUID) (*model.UserMFA, error) {
+	mfa, err := s.mfaRepo.Find(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return mfa, err
+}
+
+// generateRecoveryCodes returns new recovery codes, formatted xxxx-xxxx-xxxx-xxxx, and their records.
+func generateRecoveryCodes(userID uuid.UUID) ([]string, []*model.UserRecoveryCode, error) {
+	codes := make([]string, recoveryCodeCount)
+	records := make([]*model.UserRecoveryCode, recoveryCodeCount)
+	for i := range codes {
+		random := make([]byte, recoveryCodeSize)
+		if _, err := rand.Read(random); err != nil {
+			return nil, nil, err
+		}
+		code := strings.ToLower(recoveryCodeEncoding.EncodeToString(random))
+		codes[i] = code[0:4] + "-" + code[4:8] + "-" + code[8:12] + "-" + code[12:16]
+		records[i] = &model.UserRecoveryCode{ID: uuid.New(), UserID: userID, CodeHash: hashRecoveryCode(code)}
+	}
+	return codes, records, nil
+}
+
+// hashRecoveryCode hashes a recovery code, ignoring case, dashes and spaces. The codes are random
+// enough that an unsalted hash cannot be reversed.
+func hashRecoveryCode(code string) string {
+	code = strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(code))
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
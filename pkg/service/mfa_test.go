@@ -0,0 +1,346 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"flagon/pkg/cache"
+	"flagon/pkg/config"
+	"flagon/pkg/model"
+	"flagon/pkg/repository"
+	"flagon/pkg/totp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// memoryMFARepository keeps authenticators and recovery codes in memory.
+type memoryMFARepository struct {
+	repository.UserMFARepository
+	mfas  map[uuid.UUID]*model.UserMFA
+	codes map[uuid.UUID][]*model.UserRecoveryCode
+}
+
+func newMemoryMFARepository() *memoryMFARepository {
+	return &memoryMFARepository{
+		mfas:  map[uuid.UUID]*model.UserMFA{},
+		codes: map[uuid.UUID][]*model.UserRecoveryCode{},
+	}
+}
+
+func (r *memoryMFARepository) Find(_ context.Context, userID uuid.UUID) (*model.UserMFA, error) {
+	mfa, ok := r.mfas[userID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return mfa, nil
+}
+
+func (r *memoryMFARepository) Save(_ context.Context, mfa *model.UserMFA) error {
+	r.mfas[mfa.UserID] = mfa
+	return nil
+}
+
+func (r *memoryMFARepository) Enable(_ context.Context, userID uuid.UUID, counter int64, codes []*model.UserRecoveryCode) error {
+	now := time.Now()
+	r.mfas[userID].EnabledAt = &now
+	r.mfas[userID].LastCounter = counter
+	r.codes[userID] = codes
+	return nil
+}
+
+func (r *memoryMFARepository) UseCounter(_ context.Context, userID uuid.UUID, counter int64) (bool, error) {
+	mfa := r.mfas[userID]
+	if counter <= mfa.LastCounter {
+		return false, nil
+	}
+	mfa.LastCounter = counter
+	return true, nil
+}
+
+func (r *memoryMFARepository) UseRecoveryCode(_ context.Context, userID uuid.UUID, codeHash string) (bool, error) {
+	for _, code := range r.codes[userID] {
+		if code.CodeHash == codeHash && code.UsedAt == nil {
+			now := time.Now()
+			code.UsedAt = &now
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// mfaFixture is a user whose authenticator is enabled.
+type mfaFixture struct {
+	service       MFAService
+	repo          *memoryMFARepository
+	user          *model.User
+	secret        string
+	recoveryCodes []string
+}
+
+func newMFAFixture(t *testing.T) *mfaFixture {
+	t.Helper()
+	user := &model.User{ID: uuid.New(), Username: "bob"}
+	repo := newMemoryMFARepository()
+	users := &memoryUserRepository{users: []*model.User{user}}
+	s := &mfaService{mfaRepo: repo, userRepo: users, sessionService: &fakeSessionService{}, issuer: "Flagon"}
+
+	ctx := context.Background()
+	enrollment, err := s.Enroll(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Enable it as if confirmed a few steps ago, so that the codes of the whole window are fresh.
+	codes, records, err := generateRecoveryCodes(user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Enable(ctx, user.ID, totp.Counter(time.Now())-3, records); err != nil {
+		t.Fatal(err)
+	}
+	return &mfaFixture{service: s, repo: repo, user: user, secret: enrollment.Secret, recoveryCodes: codes}
+}
+
+// code returns the code of the authenticator at the time step offset from now.
+func (f *mfaFixture) code(t *testing.T, offset int64) string {
+	t.Helper()
+	code, err := totp.Code(f.secret, totp.Counter(time.Now())+offset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+// wrongCode returns a code no step of the window accepts.
+func (f *mfaFixture) wrongCode(t *testing.T) string {
+	t.Helper()
+	for _, code := range []string{"000000", "111111"} {
+		if code != f.code(t, -1) && code != f.code(t, 0) && code != f.code(t, 1) {
+			return code
+		}
+	}
+	return "222222"
+}
+
+func TestMFAVerifyCode(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		// codes are verified in turn; want is whether each is accepted.
+		codes func(t *testing.T, f *mfaFixture) []string
+		want  []bool
+	}{
+		{
+			name:  "current code",
+			codes: func(t *testing.T, f *mfaFixture) []string { return []string{f.code(t, 0)} },
+			want:  []bool{true},
+		},
+		{
+			name:  "codes of the adjacent steps",
+			codes: func(t *testing.T, f *mfaFixture) []string { return []string{f.code(t, -1), f.code(t, 1)} },
+			want:  []bool{true, true},
+		},
+		{
+			name:  "code out of the window",
+			codes: func(t *testing.T, f *mfaFixture) []string { return []string{f.code(t, -2), f.code(t, 2)} },
+			want:  []bool{false, false},
+		},
+		{
+			name:  "code used twice",
+			codes: func(t *testing.T, f *mfaFixture) []string { return []string{f.code(t, 0), f.code(t, 0)} },
+			want:  []bool{true, false},
+		},
+		{
+			// Once a code was accepted, the codes of earlier steps are stale.
+			name:  "earlier code after a later one",
+			codes: func(t *testing.T, f *mfaFixture) []string { return []string{f.code(t, 1), f.code(t, 0)} },
+			want:  []bool{true, false},
+		},
+		{
+			name:  "wrong code",
+			codes: func(t *testing.T, f *mfaFixture) []string { return []string{f.wrongCode(t), "abcdef"} },
+			want:  []bool{false, false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newMFAFixture(t)
+			for i, code := range tt.codes(t, f) {
+				err := f.service.Verify(ctx, f.user.ID, code)
+				if tt.want[i] && err != nil {
+					t.Errorf("code %d: %v, want it accepted", i, err)
+				}
+				if !tt.want[i] && !errors.Is(err, ErrAuthenticationFailed) {
+					t.Errorf("code %d: %v, want ErrAuthenticationFailed", i, err)
+				}
+			}
+		})
+	}
+}
+
+func TestMFAVerifyRecoveryCode(t *testing.T) {
+	ctx := context.Background()
+	f := newMFAFixture(t)
+	if len(f.recoveryCodes) != recoveryCodeCount {
+		t.Fatalf("%d recovery codes, want %d", len(f.recoveryCodes), recoveryCodeCount)
+	}
+
+	code := f.recoveryCodes[0]
+	if err := f.service.Verify(ctx, f.user.ID, code); err != nil {
+		t.Fatalf("first use: %v", err)
+	}
+	if err := f.service.Verify(ctx, f.user.ID, code); !errors.Is(err, ErrAuthenticationFailed) {
+		t.Errorf("second use: %v, want ErrAuthenticationFailed", err)
+	}
+
+	// Recovery codes may be typed in upper case and without dashes, or with spaces.
+	typed := strings.ToUpper(strings.ReplaceAll(f.recoveryCodes[1], "-", ""))
+	if err := f.service.Verify(ctx, f.user.ID, typed); err != nil {
+		t.Errorf("code typed as %s: %v", typed, err)
+	}
+	if err := f.service.Verify(ctx, f.user.ID, strings.ReplaceAll(f.recoveryCodes[1], "-", " ")); !errors.Is(err, ErrAuthenticationFailed) {
+		t.Errorf("code used again with spaces: %v, want ErrAuthenticationFailed", err)
+	}
+
+	if err := f.service.Verify(ctx, f.user.ID, "aaaa-bbbb-cccc-dddd"); !errors.Is(err, ErrAuthenticationFailed) {
+		t.Errorf("unknown code: %v, want ErrAuthenticationFailed", err)
+	}
+	// Users without an authenticator have no recovery codes either.
+	if err := f.service.Verify(ctx, uuid.New(), f.recoveryCodes[2]); err == nil {
+		t.Error("code of another user accepted")
+	}
+}
+
+func TestLoginMFAFailures(t *testing.T) {
+	ctx := context.Background()
+	f := newMFAFixture(t)
+	challenges := repository.NewMFAChallengeRepository(cache.NewMemoryCache())
+	s := &authService{
+		userRepo:      &memoryUserRepository{users: []*model.User{f.user}},
+		tokenRepo:     repository.NewTokenRepository(cache.NewMemoryCache()),
+		keyService:    newTestSigningKeyService(t, &memorySigningKeyRepository{}, ""),
+		mfaService:    f.service,
+		challengeRepo: challenges,
+		authCfg:       config.Authentication{AccessTokenLifetime: time.Hour, RefreshTokenLifetime: 24 * time.Hour},
+	}
+	wrong := f.wrongCode(t)
+
+	login := func(token, code string) error {
+		_, err := s.LoginMFA(ctx, &MFALoginRequest{MFAToken: token, Code: code})
+		return err
+	}
+	if err := challenges.Save(ctx, "first", f.user.ID, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i < maxMFAChallengeFailures; i++ {
+		if err := login("first", wrong); !errors.Is(err, ErrAuthenticationFailed) {
+			t.Fatalf("wrong code %d: %v, want ErrAuthenticationFailed", i, err)
+		}
+		if userID, err := challenges.Find(ctx, "first"); err != nil || userID == nil {
+			t.Fatalf("challenge after %d wrong codes: %v, %v, want it kept", i, userID, err)
+		}
+	}
+	if err := login("first", wrong); err == nil || !strings.Contains(err.Error(), "too many wrong codes") {
+		t.Fatalf("wrong code %d: %v, want too many wrong codes", maxMFAChallengeFailures, err)
+	}
+	if userID, err := challenges.Find(ctx, "first"); err != nil || userID != nil {
+		t.Errorf("challenge after %d wrong codes: %v, %v, want it deleted", maxMFAChallengeFailures, userID, err)
+	}
+	// The right code no longer completes the login.
+	if err := login("first", f.code(t, 0)); !errors.Is(err, ErrAuthenticationFailed) {
+		t.Errorf("right code after too many wrong ones: %v, want ErrAuthenticationFailed", err)
+	}
+
+	// Failures are counted per challenge, so logging in again starts over.
+	if err := challenges.Save(ctx, "second", f.user.ID, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := login("second", wrong); !errors.Is(err, ErrAuthenticationFailed) {
+		t.Fatalf("wrong code: %v, want ErrAuthenticationFailed", err)
+	}
+	response, err := s.LoginMFA(ctx, &MFALoginRequest{MFAToken: "second", Code: f.code(t, 1)})
+	if err != nil {
+		t.Fatalf("right code: %v", err)
+	}
+	if response.AccessToken == "" || response.User.ID != f.user.ID {
+		t.Errorf("login of %v without an access token", response.User)
+	}
+	if err := login("second", f.code(t, 1)); !errors.Is(err, ErrAuthenticationFailed) {
+		t.Errorf("completed challenge used again: %v, want ErrAuthenticationFailed", err)
+	}
+}
+
+func TestAuthorizeRequireMFA(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		// requireMFA is the group requiring two-factor authentication, nil for none.
+		requireMFA func(f *accessFixture) *model.ProjectGroup
+		mfa        *model.UserMFA
+		// want is whether view is granted on the group, the project and both environments.
+		want [4]bool
+	}{
+		{
+			name:       "not required",
+			requireMFA: func(*accessFixture) *model.ProjectGroup { return nil },
+			want:       [4]bool{true, true, true, true},
+		},
+		{
+			name:       "required by the group",
+			requireMFA: func(f *accessFixture) *model.ProjectGroup { return f.group },
+			want:       [4]bool{},
+		},
+		{
+			name:       "required by the parent group",
+			requireMFA: func(f *accessFixture) *model.ProjectGroup { return f.parent },
+			want:       [4]bool{},
+		},
+		{
+			name:       "authenticator not confirmed",
+			requireMFA: func(f *accessFixture) *model.ProjectGroup { return f.parent },
+			mfa:        &model.UserMFA{Secret: "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"},
+			want:       [4]bool{},
+		},
+		{
+			name:       "authenticator enabled",
+			requireMFA: func(f *accessFixture) *model.ProjectGroup { return f.parent },
+			mfa:        &model.UserMFA{Secret: "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", EnabledAt: &time.Time{}},
+			want:       [4]bool{true, true, true, true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAccessFixture()
+			mfaRepo := newMemoryMFARepository()
+			f.service.(*accessService).mfaRepo = mfaRepo
+			if group := tt.requireMFA(f); group != nil {
+				group.RequireMFA = true
+			}
+
+			// Members and owners alike need two-factor authentication.
+			member := uuid.New()
+			f.addGroupMember(f.group, member, model.RoleViewer)
+			for _, userID := range []uuid.UUID{member, f.owner} {
+				if tt.mfa != nil {
+					mfa := *tt.mfa
+					mfa.UserID = userID
+					mfaRepo.mfas[userID] = &mfa
+				}
+				if got := f.authorizeAll(ctx, userID, model.PermissionView); got != tt.want {
+					t.Errorf("view on group, project, environment, protected environment: %v, want %v", got, tt.want)
+				}
+			}
+
+			if tt.want == ([4]bool{}) {
+				err := f.service.AuthorizeProject(ctx, member, f.project.Slug, model.PermissionView)
+				if !errors.Is(err, ErrPermissionDenied) || !strings.Contains(err.Error(), "two-factor") {
+					t.Errorf("AuthorizeProject: %v, want two-factor authentication required", err)
+				}
+			}
+		})
+	}
+}
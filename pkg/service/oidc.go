@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"flagon/pkg/model"
+	"flagon/pkg/oidc"
+	"flagon/pkg/repository"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// oidcLoginTimeout is how long users have to log in at the identity provider.
+const oidcLoginTimeout = 10 * time.Minute
+
+// OIDCCallbackRequest holds the parameters the identity provider redirects back with.
+type OIDCCallbackRequest struct {
+	Code             string `form:"code"`
+	State            string `form:"state" binding:"required"`
+	Error            string `form:"error"`
+	ErrorDescription string `form:"error_description"`
+}
+
+func (s *authService) OIDCAuthorizationURL(ctx context.Context) (string, error) {
+	if s.oidcProvider == nil {
+		return "", fmt.Errorf("single sign-on %w", ErrNotFound)
+	}
+
+	login := &repository.OIDCLoginState{}
+	state, err := oidc.RandomString()
+	if err != nil {
+		return "", err
+	}
+	if login.Nonce, err = oidc.RandomString(); err != nil {
+		return "", err
+	}
+	if login.CodeVerifier, err = oidc.RandomString(); err != nil {
+		return "", err
+	}
+
+	authURL, err := s.oidcProvider.AuthCodeURL(ctx, state, login.Nonce, login.CodeVerifier)
+	if err != nil {
+		return "", err
+	}
+	if err := s.oidcStateRepo.Save(ctx, state, login, oidcLoginTimeout); err != nil {
+		return "", err
+	}
+	return authURL, nil
+}
+
+// OIDCLogin logs in the user linked to the provider account. Accounts are linked on first login to
+// the user with the same email address when the provider verified it, or to a new user when signup
+// is allowed.
+func (s *authService) OIDCLogin(ctx context.Context, req *OIDCCallbackRequest) (*LoginResponse, error) {
+	if s.oidcProvider == nil {
+		return nil, fmt.Errorf("single sign-on %w", ErrNotFound)
+	}
+
+	login, err := s.oidcStateRepo.Take(ctx, req.State)
+	if err != nil {
+		return nil, err
+	}
+	if login == nil {
+		return nil, fmt.Errorf("%w: login expired, please try again", ErrAuthenticationFailed)
+	}
+	if req.Error != "" {
+		return nil, fmt.Errorf("%w: identity provider: %s %s", ErrAuthenticationFailed, req.Error, req.ErrorDescription)
+	}
+	if req.Code == "" {
+		return nil, invalidArgument("code is required")
+	}
+
+	token, err := s.oidcProvider.Exchange(ctx, req.Code, login.CodeVerifier)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrAuthenticationFailed, err)
+	}
+	claims, err := s.oidcProvider.Verify(ctx, token.IDToken, login.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrAuthenticationFailed, err)
+	}
+
+	user, err := s.oidcUser(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+	return s.completeLogin(ctx, user)
+}
+
+// oidcUser returns the user linked to the provider account, linking or creating one on first login.
+func (s *authService) oidcUser(ctx context.Context, claims *oidc.Claims) (*model.User, error) {
+	provider := s.authCfg.OIDC.Provider
+	user, err := s.userRepo.FindBySSO(ctx, provider, claims.Subject)
+	if err == nil {
+		return user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if claims.Email == "" {
+		return nil, fmt.Errorf("%w: the identity provider did not share an email address", ErrAuthenticationFailed)
+	}
+	// An unverified address could be anyone's, so it neither grants access to an account nor claims one.
+	if !claims.EmailVerified {
+		return nil, fmt.Errorf("%w: the identity provider has not verified %s", ErrAuthenticationFailed, claims.Email)
+	}
+	sso := &model.UserSSO{ID: uuid.New(), Provider: provider, ProviderID: claims.Subject}
+
+	user, err = s.userRepo.FindByEmail(ctx, claims.Email)
+	if err == nil {
+		// Anyone can register an address they do not own, so only a verified one proves the
+		// account belongs to the person signing in.
+		if user.EmailVerifiedAt == nil {
+			return nil, fmt.Errorf("%w: %s must be verified in Flagon before single sign-on can use it", ErrAuthenticationFailed, claims.Email)
+		}
+		sso.UserID = user.ID
+		err := s.userRepo.AddSSO(ctx, sso)
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, fmt.Errorf("%w: %s is linked to another %s account", ErrAuthenticationFailed, claims.Email, provider)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if !s.authCfg.OIDC.AllowSignup {
+		return nil, fmt.Errorf("%w: no Flagon account uses %s", ErrAuthenticationFailed, claims.Email)
+	}
+	username, err := s.availableUsername(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	user = &model.User{
+		ID:        uuid.New(),
+		Username:  username,
+		Email:     claims.Email,
+		FirstName: claims.GivenName,
+		LastName:  claims.FamilyName,
+		AvatarURL: claims.Picture,
+		// The identity provider verified the address.
+		EmailVerifiedAt: &now,
+	}
+	sso.UserID = user.ID
+	if err := s.userRepo.CreateWithSSO(ctx, user, sso); err != nil {
+		return nil, translateError(err, "user")
+	}
+	return user, nil
+}
+
+// availableUsername picks the username of a new user from the provider account, adding a random
+// suffix when it is taken.
+func (s *authService) availableUsername(ctx context.Context, claims *oidc.Claims) (string, error) {
+	base := claims.PreferredUsername
+	if base == "" {
+		base, _, _ = strings.Cut(claims.Email, "@")
+	}
+	username := base
+	for range 5 {
+		_, err := s.userRepo.FindByUsername(ctx, username)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return username, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		suffix := make([]byte, 3)
+		if _, err := rand.Read(suffix); err != nil {
+			return "", err
+		}
+		username = base + "-" + hex.EncodeToString(suffix)
+	}
+	return "", fmt.Errorf("username %s %w", base, ErrAlreadyExists)
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"flagon/pkg/config"
+	"flagon/pkg/model"
+	"flagon/pkg/oidc"
+	"flagon/pkg/repository"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// memoryUserRepository keeps users and their single sign-on links in memory.
+type memoryUserRepository struct {
+	repository.UserRepository
+	users []*model.User
+	links []*model.UserSSO
+}
+
+func (r *memoryUserRepository) find(match func(*model.User) bool) (*model.User, error) {
+	for _, user := range r.users {
+		if match(user) {
+			return user, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryUserRepository) FindByID(_ context.Context, id uuid.UUID) (*model.User, error) {
+	return r.find(func(user *model.User) bool { return user.ID == id })
+}
+
+func (r *memoryUserRepository) FindByUsername(_ context.Context, username string) (*model.User, error) {
+	return r.find(func(user *model.User) bool { return user.Username == username })
+}
+
+func (r *memoryUserRepository) FindByEmail(_ context.Context, email string) (*model.User, error) {
+	return r.find(func(user *model.User) bool { return strings.EqualFold(user.Email, email) })
+}
+
+func (r *memoryUserRepository) FindBySSO(ctx context.Context, provider, providerID string) (*model.User, error) {
+	for _, link := range r.links {
+		if link.Provider == provider && link.ProviderID == providerID {
+			return r.FindByID(ctx, link.UserID)
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryUserRepository) AddSSO(_ context.Context, sso *model.UserSSO) error {
+	for _, link := range r.links {
+		if link.UserID == sso.UserID && link.Provider == sso.Provider {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+	r.links = append(r.links, sso)
+	return nil
+}
+
+func (r *memoryUserRepository) CreateWithSSO(ctx context.Context, user *model.User, sso *model.UserSSO) error {
+	r.users = append(r.users, user)
+	return r.AddSSO(ctx, sso)
+}
+
+func (r *memoryUserRepository) MarkEmailVerified(_ context.Context, id uuid.UUID, email string) error {
+	for _, user := range r.users {
+		if user.ID == id && user.Email == email {
+			now := time.Now()
+			user.EmailVerifiedAt = &now
+		}
+	}
+	return nil
+}
+
+func TestOIDCUser(t *testing.T) {
+	verifiedAt := time.Now()
+	const provider = "corp"
+
+	tests := []struct {
+		name        string
+		claims      oidc.Claims
+		allowSignup bool
+		wantErr     error
+		// wantUser is the username of the user logged in.
+		wantUser  string
+		wantUsers int
+		wantLinks int
+	}{
+		{
+			name:      "linked account",
+			claims:    oidc.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "sub-ana"}, Email: "other@example.com"},
+			wantUser:  "ana",
+			wantUsers: 3,
+			wantLinks: 1,
+		},
+		{
+			name:      "link by verified email",
+			claims:    oidc.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "sub-carol"}, Email: "CAROL@example.com", EmailVerified: true},
+			wantUser:  "carol",
+			wantUsers: 3,
+			wantLinks: 2,
+		},
+		{
+			name:      "email not verified by the local account",
+			claims:    oidc.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "sub-bob"}, Email: "BOB@example.com", EmailVerified: true},
+			wantErr:   ErrAuthenticationFailed,
+			wantUsers: 3,
+			wantLinks: 1,
+		},
+		{
+			name:      "unverified email",
+			claims:    oidc.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "sub-bob"}, Email: "bob@example.com"},
+			wantErr:   ErrAuthenticationFailed,
+			wantUsers: 3,
+			wantLinks: 1,
+		},
+		{
+			name:      "no email",
+			claims:    oidc.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "sub-bob"}, EmailVerified: true},
+			wantErr:   ErrAuthenticationFailed,
+			wantUsers: 3,
+			wantLinks: 1,
+		},
+		{
+			name:      "email of a user linked to another account",
+			claims:    oidc.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "sub-other"}, Email: "ana@example.com", EmailVerified: true},
+			wantErr:   ErrAuthenticationFailed,
+			wantUsers: 3,
+			wantLinks: 1,
+		},
+		{
+			name:      "signup disabled",
+			claims:    oidc.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "sub-new"}, Email: "new@example.com", EmailVerified: true},
+			wantErr:   ErrAuthenticationFailed,
+			wantUsers: 3,
+			wantLinks: 1,
+		},
+		{
+			name:        "signup",
+			claims:      oidc.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "sub-new"}, Email: "new@example.com", EmailVerified: true},
+			allowSignup: true,
+			wantUser:    "new",
+			wantUsers:   4,
+			wantLinks:   2,
+		},
+		{
+			name: "signup with a taken username",
+			claims: oidc.Claims{
+				RegisteredClaims:  jwt.RegisteredClaims{Subject: "sub-new"},
+				Email:             "new@example.com",
+				EmailVerified:     true,
+				PreferredUsername: "carol",
+			},
+			allowSignup: true,
+			wantUser:    "carol-",
+			wantUsers:   4,
+			wantLinks:   2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ana := &model.User{ID: uuid.New(), Username: "ana", Email: "ana@example.com", EmailVerifiedAt: &verifiedAt}
+			bob := &model.User{ID: uuid.New(), Username: "bob", Email: "bob@example.com"}
+			carol := &model.User{ID: uuid.New(), Username: "carol", Email: "carol@example.com", EmailVerifiedAt: &verifiedAt}
+			repo := &memoryUserRepository{
+				users: []*model.User{ana, bob, carol},
+				links: []*model.UserSSO{{ID: uuid.New(), UserID: ana.ID, Provider: provider, ProviderID: "sub-ana"}},
+			}
+			s := &authService{
+				userRepo: repo,
+				authCfg:  config.Authentication{OIDC: config.OIDC{Enabled: true, Provider: provider, AllowSignup: tt.allowSignup}},
+			}
+
+			user, err := s.oidcUser(context.Background(), &tt.claims)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("oidcUser: %v, want %v", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("oidcUser: %v", err)
+			} else if !strings.HasPrefix(user.Username, tt.wantUser) || user.EmailVerifiedAt == nil {
+				t.Errorf("logged in %s (verified at %v), want %s with a verified email", user.Username, user.EmailVerifiedAt, tt.wantUser)
+			}
+			if len(repo.users) != tt.wantUsers || len(repo.links) != tt.wantLinks {
+				t.Errorf("%d users and %d links, want %d and %d", len(repo.users), len(repo.links), tt.wantUsers, tt.wantLinks)
+			}
+			if bob.EmailVerifiedAt != nil {
+				t.Error("email of bob marked verified")
+			}
+		})
+	}
+}
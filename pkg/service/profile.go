@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"flagon/pkg/model"
+	"flagon/pkg/repository"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// ProfileService lets users manage their own account.
+type ProfileService interface {
+	Get(ctx context.Context, userID uuid.UUID) (*model.User, error)
+	// Update changes the profile of the user. Changing the email address requires the current
+	// password, and a code of the authenticator when two-factor authentication is enabled, as it lets
+	// whoever controls the new address reset the password. A new email address is unverified until
+	// the user opens the link emailed to it.
+	Update(ctx context.Context, userID uuid.UUID, req *UpdateProfileRequest) (*model.User, error)
+	// ChangePassword replaces the password of the user, logging them out everywhere but in the
+	// session with the kept ID.
+	ChangePassword(ctx context.Context, userID uuid.UUID, keptSessionID string, req *ChangePasswordRequest) error
+	// ListGroups returns the project groups the user owns or was added to, with their role in each.
+	ListGroups(ctx context.Context, userID uuid.UUID) ([]*model.ProjectGroupMembership, error)
+	// ListProjects returns the projects the user owns or was added to, with their role in each.
+	ListProjects(ctx context.Context, userID uuid.UUID) ([]*model.ProjectMembership, error)
+}
+
+type profileService struct {
+	userRepo       repository.UserRepository
+	groupRepo      repository.ProjectGroupRepository
+	projectRepo    repository.ProjectRepository
+	accounts       AccountService
+	sessionService SessionService
+	mfaService     MFAService
+}
+
+func NewProfileService(
+	userRepo repository.UserRepository,
+	groupRepo repository.ProjectGroupRepository,
+	projectRepo repository.ProjectRepository,
+	accounts AccountService,
+	sessionService SessionService,
+	mfaService MFAService,
+) ProfileService {
+	return &profileService{
+		userRepo:       userRepo,
+		groupRepo:      groupRepo,
+		projectRepo:    projectRepo,
+		accounts:       accounts,
+		sessionService: sessionService,
+		mfaService:     mfaService,
+	}
+}
+
+type UpdateProfileRequest struct {
+	FirstName *string `json:"first_name" binding:"omitempty,max=255"`
+	LastName  *string `json:"last_name" binding:"omitempty,max=255"`
+	AvatarURL *string `json:"avatar_url" binding:"omitempty,max=2048"`
+	Email     *string `json:"email" binding:"omitempty,email"`
+	// CurrentPassword is required to change the email address.
+	CurrentPassword string `json:"current_password"`
+	// Code is a code of the authenticator, or a recovery code, required to change the email address
+	// when two-factor authentication is enabled.
+	Code string `json:"code"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=6"`
+}
+
+func (s *profileService) Get(ctx context.Context, userID uuid.UUID) (*model.User, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, translateError(err, "user")
+	}
+	return user, nil
+}
+
+func (s *profileService) Update(ctx context.Context, userID uuid.UUID, req *UpdateProfileRequest) (*model.User, error) {
+	user, err := s.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.FirstName != nil {
+		user.FirstName = *req.FirstName
+	}
+	if req.LastName != nil {
+		user.LastName = *req.LastName
+	}
+	if req.AvatarURL != nil {
+		user.AvatarURL = *req.AvatarURL
+	}
+	emailChanged := req.Email != nil && *req.Email != user.Email
+	if emailChanged {
+		if err := s.confirmIdentity(ctx, user, req); err != nil {
+			return nil, err
+		}
+		if _, err := s.userRepo.FindByEmail(ctx, *req.Email); err == nil {
+			return nil, fmt.Errorf("email %w", ErrAlreadyExists)
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		user.Email = *req.Email
+		user.EmailVerifiedAt = nil
+	}
+
+	if err := s.userRepo.UpdateProfile(ctx, user); err != nil {
+		return nil, translateError(err, "email")
+	}
+	if emailChanged {
+		// As on registration, the user can ask for another email should this one fail.
+		if err := s.accounts.SendEmailVerification(ctx, user.ID); err != nil {
+			slog.ErrorContext(ctx, "Failed to send the email verification", "user_id", user.ID, "error", err)
+		}
+	}
+	return user, nil
+}
+
+// confirmIdentity checks the current password and, when two-factor authentication is enabled, the
+// code of the request, so a stolen session cannot take over the account by changing its email address.
+func (s *profileService) confirmIdentity(ctx context.Context, user *model.User, req *UpdateProfileRequest) error {
+	// Users without a password, such as single sign-on users, set one through a password reset.
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword)) != nil {
+		return invalidArgument("the current password is wrong")
+	}
+	enabled, err := s.mfaService.IsEnabled(ctx, user.ID)
+	if err != nil || !enabled {
+		return err
+	}
+	if req.Code == "" {
+		return invalidArgument("a code of the authenticator is required to change the email address")
+	}
+	err = s.mfaService.Verify(ctx, user.ID, req.Code)
+	if errors.Is(err, ErrAuthenticationFailed) {
+		return invalidArgument("invalid code")
+	}
+	return err
+}
+
+func (s *profileService) ChangePassword(ctx context.Context, userID uuid.UUID, keptSessionID string, req *ChangePasswordRequest) error {
+	user, err := s.Get(ctx, userID)
+	if err != nil {
+		return err
+	}
+	// Users without a password, such as single sign-on users, set one through a password reset.
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword)) != nil {
+		return invalidArgument("the current password is wrong")
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.UpdatePassword(ctx, user.ID, string(hashedPassword)); err != nil {
+		return err
+	}
+	return s.sessionService.RevokeOthers(ctx, user.ID, keptSessionID)
+}
+
+func (s *profileService) ListGroups(ctx context.Context, userID uuid.UUID) ([]*model.ProjectGroupMembership, error) {
+	memberships, err := s.groupRepo.FindMemberships(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, membership := range memberships {
+		if membership.OwnerID == userID {
+			membership.Role = model.RoleOwner
+		}
+	}
+	return memberships, nil
+}
+
+func (s *profileService) ListProjects(ctx context.Context, userID uuid.UUID) ([]*model.ProjectMembership, error) {
+	memberships, err := s.projectRepo.FindMemberships(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, membership := range memberships {
+		if membership.OwnerID == userID {
+			membership.Role = model.RoleOwner
+		}
+	}
+	return memberships, nil
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"flagon/pkg/model"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// UpdateProfile keeps the changes Update made to the stored user.
+func (r *memoryUserRepository) UpdateProfile(context.Context, *model.User) error {
+	return nil
+}
+
+// verificationRecorder records whose email address it was asked to verify.
+type verificationRecorder struct {
+	AccountService
+	sent []uuid.UUID
+}
+
+func (a *verificationRecorder) SendEmailVerification(_ context.Context, userID uuid.UUID) error {
+	a.sent = append(a.sent, userID)
+	return nil
+}
+
+func TestUpdateProfileEmail(t *testing.T) {
+	ctx := context.Background()
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ptr := func(s string) *string { return &s }
+
+	tests := []struct {
+		name       string
+		mfaEnabled bool
+		// req returns the request, given the fixture of the user's authenticator when it is enabled.
+		req       func(t *testing.T, f *mfaFixture) *UpdateProfileRequest
+		wantErr   error
+		wantEmail string
+	}{
+		{
+			name: "name without password",
+			req: func(*testing.T, *mfaFixture) *UpdateProfileRequest {
+				return &UpdateProfileRequest{FirstName: ptr("Bob")}
+			},
+			wantEmail: "bob@example.com",
+		},
+		{
+			name: "same email without password",
+			req: func(*testing.T, *mfaFixture) *UpdateProfileRequest {
+				return &UpdateProfileRequest{Email: ptr("bob@example.com")}
+			},
+			wantEmail: "bob@example.com",
+		},
+		{
+			name: "email without password",
+			req: func(*testing.T, *mfaFixture) *UpdateProfileRequest {
+				return &UpdateProfileRequest{Email: ptr("eve@example.com")}
+			},
+			wantErr:   ErrInvalidArgument,
+			wantEmail: "bob@example.com",
+		},
+		{
+			name: "email with a wrong password",
+			req: func(*testing.T, *mfaFixture) *UpdateProfileRequest {
+				return &UpdateProfileRequest{Email: ptr("eve@example.com"), CurrentPassword: "guess"}
+			},
+			wantErr:   ErrInvalidArgument,
+			wantEmail: "bob@example.com",
+		},
+		{
+			// A wrong password must not tell whether the address is taken.
+			name: "taken email with a wrong password",
+			req: func(*testing.T, *mfaFixture) *UpdateProfileRequest {
+				return &UpdateProfileRequest{Email: ptr("carol@example.com"), CurrentPassword: "guess"}
+			},
+			wantErr:   ErrInvalidArgument,
+			wantEmail: "bob@example.com",
+		},
+		{
+			name: "taken email",
+			req: func(*testing.T, *mfaFixture) *UpdateProfileRequest {
+				return &UpdateProfileRequest{Email: ptr("carol@example.com"), CurrentPassword: "secret"}
+			},
+			wantErr:   ErrAlreadyExists,
+			wantEmail: "bob@example.com",
+		},
+		{
+			name: "email with the password",
+			req: func(*testing.T, *mfaFixture) *UpdateProfileRequest {
+				return &UpdateProfileRequest{Email: ptr("bob@example.org"), CurrentPassword: "secret"}
+			},
+			wantEmail: "bob@example.org",
+		},
+		{
+			name:       "email without a code",
+			mfaEnabled: true,
+			req: func(*testing.T, *mfaFixture) *UpdateProfileRequest {
+				return &UpdateProfileRequest{Email: ptr("bob@example.org"), CurrentPassword: "secret"}
+			},
+			wantErr:   ErrInvalidArgument,
+			wantEmail: "bob@example.com",
+		},
+		{
+			name:       "email with a wrong code",
+			mfaEnabled: true,
+			req: func(t *testing.T, f *mfaFixture) *UpdateProfileRequest {
+				return &UpdateProfileRequest{Email: ptr("bob@example.org"), CurrentPassword: "secret", Code: f.wrongCode(t)}
+			},
+			wantErr:   ErrInvalidArgument,
+			wantEmail: "bob@example.com",
+		},
+		{
+			name:       "email with a code but a wrong password",
+			mfaEnabled: true,
+			req: func(t *testing.T, f *mfaFixture) *UpdateProfileRequest {
+				return &UpdateProfileRequest{Email: ptr("bob@example.org"), CurrentPassword: "guess", Code: f.code(t, 0)}
+			},
+			wantErr:   ErrInvalidArgument,
+			wantEmail: "bob@example.com",
+		},
+		{
+			name:       "email with the password and a code",
+			mfaEnabled: true,
+			req: func(t *testing.T, f *mfaFixture) *UpdateProfileRequest {
+				return &UpdateProfileRequest{Email: ptr("bob@example.org"), CurrentPassword: "secret", Code: f.code(t, 0)}
+			},
+			wantEmail: "bob@example.org",
+		},
+		{
+			name:       "email with the password and a recovery code",
+			mfaEnabled: true,
+			req: func(_ *testing.T, f *mfaFixture) *UpdateProfileRequest {
+				return &UpdateProfileRequest{Email: ptr("bob@example.org"), CurrentPassword: "secret", Code: f.recoveryCodes[0]}
+			},
+			wantEmail: "bob@example.org",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newMFAFixture(t)
+			if !tt.mfaEnabled {
+				f.repo.mfas[f.user.ID].EnabledAt = nil
+			}
+			verifiedAt := time.Now()
+			f.user.Email, f.user.Password, f.user.EmailVerifiedAt = "bob@example.com", string(hash), &verifiedAt
+			carol := &model.User{ID: uuid.New(), Username: "carol", Email: "carol@example.com"}
+			accounts := &verificationRecorder{}
+			s := NewProfileService(&memoryUserRepository{users: []*model.User{f.user, carol}}, nil, nil, accounts, nil, f.service)
+
+			_, err := s.Update(ctx, f.user.ID, tt.req(t, f))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Update: %v, want %v", err, tt.wantErr)
+			}
+			if f.user.Email != tt.wantEmail {
+				t.Errorf("email %s, want %s", f.user.Email, tt.wantEmail)
+			}
+			changed := tt.wantEmail != "bob@example.com"
+			if changed != (f.user.EmailVerifiedAt == nil) || changed != (len(accounts.sent) == 1) {
+				t.Errorf("email changed %t, but verified at %v with %d verification emails", changed, f.user.EmailVerifiedAt, len(accounts.sent))
+			}
+		})
+	}
+}
@@ -0,0 +1,289 @@
+package service
+
+import (
+	"context"
+	"flagon/pkg/model"
+	"flagon/pkg/repository"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+type ProjectService interface {
+	Create(ctx context.Context, ownerID uuid.UUID, req *CreateProjectRequest) (*model.Project, error)
+	List(ctx context.Context, userID uuid.UUID) ([]*model.Project, error)
+	Get(ctx context.Context, slug string) (*model.Project, error)
+	Update(ctx context.Context, slug string, req *UpdateProjectRequest) (*model.Project, error)
+	TransferOwnership(ctx context.Context, slug string, req *TransferProjectRequest) (*model.Project, error)
+	Delete(ctx context.Context, slug string) error
+	ListMembers(ctx context.Context, slug string) ([]*model.ProjectMember, error)
+	AddMember(ctx context.Context, slug string, req *AddProjectMemberRequest) error
+	UpdateMember(ctx context.Context, slug string, userID uuid.UUID, req *UpdateMemberRequest) error
+	RemoveMember(ctx context.Context, slug string, userID uuid.UUID) error
+}
+
+type projectService struct {
+	projectRepo   repository.ProjectRepository
+	userRepo      repository.UserRepository
+	groupService  ProjectGroupService
+	accessService AccessService
+	auditService  AuditService
+}
+
+func NewProjectService(
+	projectRepo repository.ProjectRepository,
+	userRepo repository.UserRepository,
+	groupService ProjectGroupService,
+	accessService AccessService,
+	auditService AuditService,
+) ProjectService {
+	return &projectService{
+		projectRepo:   projectRepo,
+		userRepo:      userRepo,
+		groupService:  groupService,
+		accessService: accessService,
+		auditService:  auditService,
+	}
+}
+
+type CreateProjectRequest struct {
+	GroupID     uuid.UUID `json:"group_id" binding:"required"`
+	Slug        string    `json:"slug" binding:"required"`
+	Name        string    `json:"name" binding:"required,max=255"`
+	Description string    `json:"description"`
+}
+
+type UpdateProjectRequest struct {
+	Slug        *string `json:"slug"`
+	Name        *string `json:"name" binding:"omitempty,min=1,max=255"`
+	Description *string `json:"description"`
+}
+
+type TransferProjectRequest struct {
+	OwnerID uuid.UUID `json:"owner_id" binding:"required"`
+}
+
+type AddProjectMemberRequest struct {
+	UserID uuid.UUID `json:"user_id" binding:"required"`
+	// Role defaults to viewer.
+	Role model.Role `json:"role" binding:"omitempty,oneof=viewer editor admin"`
+}
+
+// UpdateMemberRequest changes the role of a group or project member.
+type UpdateMemberRequest struct {
+	Role model.Role `json:"role" binding:"required,oneof=viewer editor admin"`
+}
+
+func (s *projectService) Create(ctx context.Context, ownerID uuid.UUID, req *CreateProjectRequest) (*model.Project, error) {
+	if err := validateSlug(req.Slug); err != nil {
+		return nil, err
+	}
+	if err := s.accessService.AuthorizeGroup(ctx, ownerID, req.GroupID, model.PermissionManage); err != nil {
+		return nil, err
+	}
+
+	project := &model.Project{
+		ID:          uuid.New(),
+		Slug:        req.Slug,
+		GroupID:     uuid.NullUUID{UUID: req.GroupID, Valid: true},
+		OwnerID:     ownerID,
+		Name:        req.Name,
+		Description: req.Description,
+	}
+	if err := s.projectRepo.Create(ctx, project); err != nil {
+		return nil, translateError(err, "project")
+	}
+	s.auditService.Record(ctx, model.AuditActionCreate, auditProject(project), nil, project)
+	return project, nil
+}
+
+// List returns the projects the user owns or belongs to, and every project in the groups visible to them.
+func (s *projectService) List(ctx context.Context, userID uuid.UUID) ([]*model.Project, error) {
+	groups, err := s.groupService.List(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	groupIDs := make([]uuid.UUID, 0, len(groups))
+	for _, group := range groups {
+		groupIDs = append(groupIDs, group.ID)
+	}
+	return s.projectRepo.FindVisible(ctx, userID, groupIDs)
+}
+
+func (s *projectService) Get(ctx context.Context, slug string) (*model.Project, error) {
+	project, err := s.projectRepo.FindBySlug(ctx, slug)
+	if err != nil {
+		return nil, translateError(err, "project")
+	}
+	return project, nil
+}
+
+func (s *projectService) Update(ctx context.Context, slug string, req *UpdateProjectRequest) (*model.Project, error) {
+	project, err := s.Get(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	before := *project
+
+	if req.Slug != nil {
+		if err := validateSlug(*req.Slug); err != nil {
+			return nil, err
+		}
+		project.Slug = *req.Slug
+	}
+	if req.Name != nil {
+		project.Name = *req.Name
+	}
+	if req.Description != nil {
+		project.Description = *req.Description
+	}
+
+	if err := s.projectRepo.Update(ctx, project); err != nil {
+		return nil, translateError(err, "project")
+	}
+	s.auditService.Record(ctx, model.AuditActionUpdate, auditProject(project), &before, project)
+	return project, nil
+}
+
+// TransferOwnership hands the project to another user, who also becomes a member if not one already.
+// The previous owner keeps their membership.
+func (s *projectService) TransferOwnership(ctx context.Context, slug string, req *TransferProjectRequest) (*model.Project, error) {
+	project, err := s.Get(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.userRepo.FindByID(ctx, req.OwnerID); err != nil {
+		return nil, translateError(err, "user")
+	}
+
+	if err := s.ensureMember(ctx, project.ID, req.OwnerID, model.RoleAdmin); err != nil {
+		return nil, err
+	}
+	before := *project
+	project.OwnerID = req.OwnerID
+	if err := s.projectRepo.Update(ctx, project); err != nil {
+		return nil, translateError(err, "project")
+	}
+	s.auditService.Record(ctx, model.AuditActionUpdate, auditProject(project), &before, project)
+	return project, nil
+}
+
+func (s *projectService) Delete(ctx context.Context, slug string) error {
+	project, err := s.Get(ctx, slug)
+	if err != nil {
+		return err
+	}
+	if err := s.projectRepo.Delete(ctx, project.ID); err != nil {
+		return err
+	}
+	s.auditService.Record(ctx, model.AuditActionDelete, auditProject(project), project, nil)
+	return nil
+}
+
+func (s *projectService) ListMembers(ctx context.Context, slug string) ([]*model.ProjectMember, error) {
+	project, err := s.Get(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.projectRepo.FindMembers(ctx, project.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, member := range members {
+		if member.ID == project.OwnerID {
+			member.Role = model.RoleOwner
+		}
+	}
+	return members, nil
+}
+
+func (s *projectService) AddMember(ctx context.Context, slug string, req *AddProjectMemberRequest) error {
+	project, err := s.Get(ctx, slug)
+	if err != nil {
+		return err
+	}
+	if _, err := s.userRepo.FindByID(ctx, req.UserID); err != nil {
+		return translateError(err, "user")
+	}
+
+	role := req.Role
+	if role == "" {
+		role = model.RoleViewer
+	}
+	member := &model.ProjectUser{UserID: req.UserID, ProjectID: project.ID, Role: role}
+	if err := s.projectRepo.AddMember(ctx, member); err != nil {
+		return translateError(err, "project member")
+	}
+	s.auditService.Record(ctx, model.AuditActionCreate, auditProjectMember(member), nil, member)
+	return nil
+}
+
+func (s *projectService) UpdateMember(ctx context.Context, slug string, userID uuid.UUID, req *UpdateMemberRequest) error {
+	project, err := s.Get(ctx, slug)
+	if err != nil {
+		return err
+	}
+	role, err := s.projectRepo.FindMemberRole(ctx, project.ID, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.projectRepo.UpdateMemberRole(ctx, project.ID, userID, req.Role); err != nil {
+		return translateError(err, "project member")
+	}
+	before := &model.ProjectUser{UserID: userID, ProjectID: project.ID, Role: role}
+	after := &model.ProjectUser{UserID: userID, ProjectID: project.ID, Role: req.Role}
+	s.auditService.Record(ctx, model.AuditActionUpdate, auditProjectMember(after), before, after)
+	return nil
+}
+
+func (s *projectService) RemoveMember(ctx context.Context, slug string, userID uuid.UUID) error {
+	project, err := s.Get(ctx, slug)
+	if err != nil {
+		return err
+	}
+	if project.OwnerID == userID {
+		return invalidArgument("the project owner cannot be removed; transfer ownership first")
+	}
+
+	role, err := s.projectRepo.FindMemberRole(ctx, project.ID, userID)
+	if err != nil {
+		return err
+	}
+	if role == "" {
+		return fmt.Errorf("project member %w", ErrNotFound)
+	}
+	if err := s.projectRepo.RemoveMember(ctx, project.ID, userID); err != nil {
+		return err
+	}
+	member := &model.ProjectUser{UserID: userID, ProjectID: project.ID, Role: role}
+	s.auditService.Record(ctx, model.AuditActionDelete, auditProjectMember(member), member, nil)
+	return nil
+}
+
+// ensureMember makes the user a member with at least the given role.
+func (s *projectService) ensureMember(ctx context.Context, projectID, userID uuid.UUID, role model.Role) error {
+	current, err := s.projectRepo.FindMemberRole(ctx, projectID, userID)
+	switch {
+	case err != nil:
+		return err
+	case current == "":
+		return s.projectRepo.AddMember(ctx, &model.ProjectUser{UserID: userID, ProjectID: projectID, Role: role})
+	case !current.Includes(role):
+		return s.projectRepo.UpdateMemberRole(ctx, projectID, userID, role)
+	default:
+		return nil
+	}
+}
+
+// auditProject identifies a project, including its group which cannot be looked up once it is deleted.
+func auditProject(project *model.Project) AuditResource {
+	resource := projectAudit(model.AuditResourceProject, project.ID.String(), project.ID)
+	if project.GroupID.Valid {
+		resource.GroupID = &project.GroupID.UUID
+	}
+	return resource
+}
+
+func auditProjectMember(member *model.ProjectUser) AuditResource {
+	return projectAudit(model.AuditResourceProjectMember, member.UserID.String(), member.ProjectID)
+}
@@ -0,0 +1,362 @@
+package service
+
+import (
+	"context"
+	"flagon/pkg/model"
+	"flagon/pkg/repository"
+
+	"github.com/google/uuid"
+)
+
+type ProjectGroupService interface {
+	Create(ctx context.Context, ownerID uuid.UUID, req *CreateProjectGroupRequest) (*model.ProjectGroup, error)
+	Get(ctx context.Context, id uuid.UUID) (*model.ProjectGroup, error)
+	List(ctx context.Context, userID uuid.UUID) ([]*model.ProjectGroup, error)
+	Tree(ctx context.Context, userID uuid.UUID) ([]*ProjectGroupNode, error)
+	Update(ctx context.Context, id uuid.UUID, req *UpdateProjectGroupRequest) (*model.ProjectGroup, error)
+	Move(ctx context.Context, userID, id uuid.UUID, req *MoveProjectGroupRequest) (*model.ProjectGroup, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	Contains(ctx context.Context, ancestorID, groupID uuid.UUID) (bool, error)
+
+	ListMembers(ctx context.Context, id uuid.UUID) ([]*model.ProjectGroupMember, error)
+	AddMember(ctx context.Context, id uuid.UUID, req *AddGroupMemberRequest) error
+	UpdateMember(ctx context.Context, id, userID uuid.UUID, req *UpdateMemberRequest) error
+	RemoveMember(ctx context.Context, id, userID uuid.UUID) error
+}
+
+type projectGroupService struct {
+	groupRepo     repository.ProjectGroupRepository
+	userRepo      repository.UserRepository
+	accessService AccessService
+	auditService  AuditService
+}
+
+func NewProjectGroupService(
+	groupRepo repository.ProjectGroupRepository,
+	userRepo repository.UserRepository,
+	accessService AccessService,
+	auditService AuditService,
+) ProjectGroupService {
+	return &projectGroupService{
+		groupRepo:     groupRepo,
+		userRepo:      userRepo,
+		accessService: accessService,
+		auditService:  auditService,
+	}
+}
+
+type CreateProjectGroupRequest struct {
+	ParentID    *uuid.UUID `json:"parent_id"`
+	Name        string     `json:"name" binding:"required,max=255"`
+	Slug        string     `json:"slug" binding:"required"`
+	Description string     `json:"description"`
+}
+
+type UpdateProjectGroupRequest struct {
+	Name        *string `json:"name" binding:"omitempty,min=1,max=255"`
+	Slug        *string `json:"slug"`
+	Description *string `json:"description"`
+	// RequireMFA denies the group, its sub-groups and their projects to members without two-factor authentication.
+	RequireMFA *bool `json:"require_mfa"`
+}
+
+type MoveProjectGroupRequest struct {
+	// ParentID is the new parent group; null moves the group to the top level.
+	ParentID *uuid.UUID `json:"parent_id"`
+}
+
+type AddGroupMemberRequest struct {
+	UserID uuid.UUID `json:"user_id" binding:"required"`
+	// Role defaults to viewer. Members inherit it in every sub-group and project of the group.
+	Role model.Role `json:"role" binding:"omitempty,oneof=viewer editor admin"`
+}
+
+// ProjectGroupNode is a project group together with its nested sub-groups.
+type ProjectGroupNode struct {
+	*model.ProjectGroup
+	Children []*ProjectGroupNode `json:"children"`
+}
+
+func (s *projectGroupService) Create(ctx context.Context, ownerID uuid.UUID, req *CreateProjectGroupRequest) (*model.ProjectGroup, error) {
+	if err := validateSlug(req.Slug); err != nil {
+		return nil, err
+	}
+	if req.ParentID != nil {
+		if err := s.accessService.AuthorizeGroup(ctx, ownerID, *req.ParentID, model.PermissionManage); err != nil {
+			return nil, err
+		}
+	}
+
+	group := &model.ProjectGroup{
+		ID:          uuid.New(),
+		ParentID:    req.ParentID,
+		OwnerID:     ownerID,
+		Name:        req.Name,
+		Slug:        req.Slug,
+		Description: req.Description,
+	}
+	if err := s.groupRepo.Create(ctx, group); err != nil {
+		return nil, translateError(err, "project group")
+	}
+	s.auditService.Record(ctx, model.AuditActionCreate, auditGroup(group), nil, group)
+	return group, nil
+}
+
+func (s *projectGroupService) Get(ctx context.Context, id uuid.UUID) (*model.ProjectGroup, error) {
+	group, err := s.groupRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, translateError(err, "project group")
+	}
+	return group, nil
+}
+
+// List returns the groups the user owns or belongs to, plus every group nested below them.
+func (s *projectGroupService) List(ctx context.Context, userID uuid.UUID) ([]*model.ProjectGroup, error) {
+	memberOf, err := s.groupRepo.FindByMember(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	all, err := s.groupRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	children := make(map[uuid.UUID][]*model.ProjectGroup)
+	for _, group := range all {
+		if group.ParentID != nil {
+			children[*group.ParentID] = append(children[*group.ParentID], group)
+		}
+	}
+
+	visible := make(map[uuid.UUID]bool)
+	queue := append([]*model.ProjectGroup(nil), memberOf...)
+	for len(queue) > 0 {
+		group := queue[0]
+		queue = queue[1:]
+		if visible[group.ID] {
+			continue
+		}
+		visible[group.ID] = true
+		queue = append(queue, children[group.ID]...)
+	}
+
+	groups := make([]*model.ProjectGroup, 0, len(visible))
+	for _, group := range all {
+		if visible[group.ID] {
+			groups = append(groups, group)
+		}
+	}
+	return groups, nil
+}
+
+func (s *projectGroupService) Tree(ctx context.Context, userID uuid.UUID) ([]*ProjectGroupNode, error) {
+	groups, err := s.List(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[uuid.UUID]*ProjectGroupNode, len(groups))
+	for _, group := range groups {
+		nodes[group.ID] = &ProjectGroupNode{ProjectGroup: group, Children: []*ProjectGroupNode{}}
+	}
+
+	roots := make([]*ProjectGroupNode, 0)
+	for _, group := range groups {
+		node := nodes[group.ID]
+		if group.ParentID != nil {
+			if parent, ok := nodes[*group.ParentID]; ok {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+	return roots, nil
+}
+
+func (s *projectGroupService) Update(ctx context.Context, id uuid.UUID, req *UpdateProjectGroupRequest) (*model.ProjectGroup, error) {
+	group, err := s.groupRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, translateError(err, "project group")
+	}
+	before := *group
+
+	if req.Name != nil {
+		group.Name = *req.Name
+	}
+	if req.Slug != nil {
+		if err := validateSlug(*req.Slug); err != nil {
+			return nil, err
+		}
+		group.Slug = *req.Slug
+	}
+	if req.Description != nil {
+		group.Description = *req.Description
+	}
+	if req.RequireMFA != nil {
+		group.RequireMFA = *req.RequireMFA
+	}
+
+	if err := s.groupRepo.Update(ctx, group); err != nil {
+		return nil, translateError(err, "project group")
+	}
+	s.auditService.Record(ctx, model.AuditActionUpdate, auditGroup(group), &before, group)
+	return group, nil
+}
+
+// Move re-parents the group. The user must be able to manage both the current and the new parent
+// group, as moving takes the group out of the reach of the current parent's admins.
+func (s *projectGroupService) Move(ctx context.Context, userID, id uuid.UUID, req *MoveProjectGroupRequest) (*model.ProjectGroup, error) {
+	group, err := s.groupRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, translateError(err, "project group")
+	}
+
+	if group.ParentID != nil {
+		if err := s.accessService.AuthorizeGroup(ctx, userID, *group.ParentID, model.PermissionManage); err != nil {
+			return nil, err
+		}
+	}
+	if req.ParentID != nil {
+		if err := s.checkNoCycle(ctx, group.ID, *req.ParentID); err != nil {
+			return nil, err
+		}
+		if err := s.accessService.AuthorizeGroup(ctx, userID, *req.ParentID, model.PermissionManage); err != nil {
+			return nil, err
+		}
+	}
+
+	before := *group
+	group.ParentID = req.ParentID
+	if err := s.groupRepo.Update(ctx, group); err != nil {
+		return nil, translateError(err, "project group")
+	}
+	s.auditService.Record(ctx, model.AuditActionUpdate, auditGroup(group), &before, group)
+	return group, nil
+}
+
+// Contains reports whether groupID is ancestorID itself or one of its sub-groups, at any depth.
+func (s *projectGroupService) Contains(ctx context.Context, ancestorID, groupID uuid.UUID) (bool, error) {
+	seen := make(map[uuid.UUID]bool)
+	for current := &groupID; current != nil && !seen[*current]; {
+		if *current == ancestorID {
+			return true, nil
+		}
+		seen[*current] = true
+
+		group, err := s.groupRepo.FindByID(ctx, *current)
+		if err != nil {
+			return false, translateError(err, "project group")
+		}
+		current = group.ParentID
+	}
+	return false, nil
+}
+
+func (s *projectGroupService) ListMembers(ctx context.Context, id uuid.UUID) ([]*model.ProjectGroupMember, error) {
+	group, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.groupRepo.FindMembers(ctx, group.ID)
+}
+
+func (s *projectGroupService) AddMember(ctx context.Context, id uuid.UUID, req *AddGroupMemberRequest) error {
+	group, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, err := s.userRepo.FindByID(ctx, req.UserID); err != nil {
+		return translateError(err, "user")
+	}
+
+	role := req.Role
+	if role == "" {
+		role = model.RoleViewer
+	}
+	member := &model.ProjectGroupUser{UserID: req.UserID, GroupID: group.ID, Role: role}
+	if err := s.groupRepo.AddMember(ctx, member); err != nil {
+		return translateError(err, "project group member")
+	}
+	s.auditService.Record(ctx, model.AuditActionCreate, auditGroupMember(member), nil, member)
+	return nil
+}
+
+func (s *projectGroupService) UpdateMember(ctx context.Context, id, userID uuid.UUID, req *UpdateMemberRequest) error {
+	group, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	role, err := s.groupRepo.FindMemberRole(ctx, group.ID, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.groupRepo.UpdateMemberRole(ctx, group.ID, userID, req.Role); err != nil {
+		return translateError(err, "project group member")
+	}
+	before := &model.ProjectGroupUser{UserID: userID, GroupID: group.ID, Role: role}
+	after := &model.ProjectGroupUser{UserID: userID, GroupID: group.ID, Role: req.Role}
+	s.auditService.Record(ctx, model.AuditActionUpdate, auditGroupMember(after), before, after)
+	return nil
+}
+
+func (s *projectGroupService) RemoveMember(ctx context.Context, id, userID uuid.UUID) error {
+	group, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	role, err := s.groupRepo.FindMemberRole(ctx, group.ID, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.groupRepo.RemoveMember(ctx, group.ID, userID); err != nil {
+		return translateError(err, "project group member")
+	}
+	member := &model.ProjectGroupUser{UserID: userID, GroupID: group.ID, Role: role}
+	s.auditService.Record(ctx, model.AuditActionDelete, auditGroupMember(member), member, nil)
+	return nil
+}
+
+// checkNoCycle walks up from the new parent and fails if it reaches the group being moved,
+// which would make the group its own ancestor.
+func (s *projectGroupService) checkNoCycle(ctx context.Context, groupID, parentID uuid.UUID) error {
+	seen := make(map[uuid.UUID]bool)
+	for current := &parentID; current != nil; {
+		if *current == groupID {
+			return invalidArgument("cannot move a project group into itself or one of its sub-groups")
+		}
+		if seen[*current] {
+			// The existing hierarchy is already cyclic; refuse to extend it.
+			return invalidArgument("project group hierarchy contains a cycle")
+		}
+		seen[*current] = true
+
+		ancestor, err := s.groupRepo.FindByID(ctx, *current)
+		if err != nil {
+			return translateError(err, "parent project group")
+		}
+		current = ancestor.ParentID
+	}
+	return nil
+}
+
+func (s *projectGroupService) Delete(ctx context.Context, id uuid.UUID) error {
+	group, err := s.groupRepo.FindByID(ctx, id)
+	if err != nil {
+		return translateError(err, "project group")
+	}
+	// Sub-groups and their projects are removed by the ON DELETE CASCADE constraints.
+	if err := s.groupRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.auditService.Record(ctx, model.AuditActionDelete, auditGroup(group), group, nil)
+	return nil
+}
+
+func auditGroup(group *model.ProjectGroup) AuditResource {
+	return groupAudit(model.AuditResourceProjectGroup, group.ID.String(), group.ID)
+}
+
+func auditGroupMember(member *model.ProjectGroupUser) AuditResource {
+	return groupAudit(model.AuditResourceProjectGroupMember, member.UserID.String(), member.GroupID)
+}
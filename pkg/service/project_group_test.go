@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"flagon/pkg/model"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func (r *memoryGroupRepository) Update(_ context.Context, group *model.ProjectGroup) error {
+	for i, existing := range r.groups {
+		if existing.ID == group.ID {
+			r.groups[i] = group
+			return nil
+		}
+	}
+	return nil
+}
+
+// auditRecord is a change recorded by recordingAuditService.
+type auditRecord struct {
+	action   model.AuditAction
+	resource AuditResource
+	before   any
+	after    any
+}
+
+// recordingAuditService keeps the recorded changes in memory.
+type recordingAuditService struct {
+	AuditService
+	records []auditRecord
+}
+
+func (s *recordingAuditService) Record(_ context.Context, action model.AuditAction, resource AuditResource, before, after any) {
+	s.records = append(s.records, auditRecord{action: action, resource: resource, before: before, after: after})
+}
+
+func TestMoveProjectGroup(t *testing.T) {
+	ctx := context.Background()
+	owner := uuid.New()
+	user := uuid.New()
+
+	tests := []struct {
+		name string
+		// roles are the member roles of the user in the current parent, the new parent and the group.
+		currentRole, newRole, groupRole model.Role
+		toTopLevel                      bool
+		wantErr                         error
+	}{
+		{
+			name:        "admin of both parents",
+			currentRole: model.RoleAdmin,
+			newRole:     model.RoleAdmin,
+		},
+		{
+			name:      "admin of the group and the new parent",
+			newRole:   model.RoleAdmin,
+			groupRole: model.RoleAdmin,
+			wantErr:   ErrPermissionDenied,
+		},
+		{
+			name:        "editor of the current parent",
+			currentRole: model.RoleEditor,
+			newRole:     model.RoleAdmin,
+			wantErr:     ErrPermissionDenied,
+		},
+		{
+			name:        "editor of the new parent",
+			currentRole: model.RoleAdmin,
+			newRole:     model.RoleEditor,
+			wantErr:     ErrPermissionDenied,
+		},
+		{
+			name:        "admin of the current parent to the top level",
+			currentRole: model.RoleAdmin,
+			toTopLevel:  true,
+		},
+		{
+			name:       "admin of the group to the top level",
+			groupRole:  model.RoleAdmin,
+			toTopLevel: true,
+			wantErr:    ErrPermissionDenied,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := &model.ProjectGroup{ID: uuid.New(), OwnerID: owner, Slug: "current"}
+			parent := &model.ProjectGroup{ID: uuid.New(), OwnerID: owner, Slug: "parent"}
+			group := &model.ProjectGroup{ID: uuid.New(), OwnerID: owner, Slug: "group", ParentID: &current.ID}
+			groups := &memoryGroupRepository{
+				groups: []*model.ProjectGroup{current, parent, group},
+				roles: map[uuid.UUID]map[uuid.UUID]model.Role{
+					current.ID: {user: tt.currentRole},
+					parent.ID:  {user: tt.newRole},
+					group.ID:   {user: tt.groupRole},
+				},
+			}
+			audit := &recordingAuditService{}
+			s := NewProjectGroupService(groups, nil, NewAccessService(groups, nil, nil, nil), audit)
+
+			req := &MoveProjectGroupRequest{ParentID: &parent.ID}
+			if tt.toTopLevel {
+				req.ParentID = nil
+			}
+			_, err := s.Move(ctx, user, group.ID, req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Move: %v, want %v", err, tt.wantErr)
+			}
+
+			moved, _ := groups.FindByID(ctx, group.ID)
+			wantParent := &current.ID
+			if tt.wantErr == nil {
+				wantParent = req.ParentID
+			}
+			if !equalIDs(moved.ParentID, wantParent) {
+				t.Errorf("parent %v, want %v", moved.ParentID, wantParent)
+			}
+			if tt.wantErr == nil && len(audit.records) != 1 {
+				t.Errorf("%d audit records, want 1", len(audit.records))
+			}
+		})
+	}
+}
+
+func equalIDs(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
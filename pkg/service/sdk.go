@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"flagon/pkg/evaluation"
+	"flagon/pkg/model"
+)
+
+// SdkService serves the flag evaluation API used by SDKs, authenticated with environment-scoped access tokens.
+type SdkService interface {
+	Authenticate(ctx context.Context, secret string) (*model.AccessToken, error)
+	Evaluate(ctx context.Context, token *model.AccessToken, req *EvaluateRequest) ([]evaluation.Result, error)
+	Config(ctx context.Context, token *model.AccessToken) (*evaluation.Snapshot, error)
+}
+
+type sdkService struct {
+	tokenService TokenService
+	evaluator    evaluation.Evaluator
+	store        evaluation.Store
+}
+
+func NewSdkService(tokenService TokenService, evaluator evaluation.Evaluator, store evaluation.Store) SdkService {
+	return &sdkService{
+		tokenService: tokenService,
+		evaluator:    evaluator,
+		store:        store,
+	}
+}
+
+type EvaluateRequest struct {
+	Context evaluation.Context `json:"context"`
+}
+
+// Authenticate resolves the secret of an SDK token. Only SDK tokens scoped to an environment are accepted.
+func (s *sdkService) Authenticate(ctx context.Context, secret string) (*model.AccessToken, error) {
+	token, err := s.tokenService.Authenticate(ctx, secret)
+	if err != nil {
+		return nil, err
+	}
+	if token.TokenType != model.AccessTokenTypeSDK || token.ProjectID == nil || token.EnvironmentID == nil {
+		return nil, ErrInvalidToken
+	}
+	return token, nil
+}
+
+// Evaluate evaluates every flag of the token's environment for the context.
+func (s *sdkService) Evaluate(ctx context.Context, token *model.AccessToken, req *EvaluateRequest) ([]evaluation.Result, error) {
+	results, err := s.evaluator.Evaluate(ctx, *token.ProjectID, *token.EnvironmentID, &req.Context)
+	if err != nil {
+		return nil, translateError(err, "environment")
+	}
+	return results, nil
+}
+
+// Config returns the configuration of the token's environment, for SDKs evaluating flags locally.
+func (s *sdkService) Config(ctx context.Context, token *model.AccessToken) (*evaluation.Snapshot, error) {
+	snapshot, err := s.store.Snapshot(ctx, *token.ProjectID, *token.EnvironmentID)
+	if err != nil {
+		return nil, translateError(err, "environment")
+	}
+	return snapshot, nil
+}
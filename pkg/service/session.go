@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"flagon/pkg/model"
+	"flagon/pkg/repository"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// Client describes where a request comes from, to tell the sessions of a user apart.
+type Client struct {
+	IP        string
+	UserAgent string
+}
+
+type clientKey struct{}
+
+// WithClient returns a context whose logins record the client in their session.
+func WithClient(ctx context.Context, client Client) context.Context {
+	return context.WithValue(ctx, clientKey{}, client)
+}
+
+// ClientFrom returns the client set by WithClient.
+func ClientFrom(ctx context.Context) Client {
+	client, _ := ctx.Value(clientKey{}).(Client)
+	return client
+}
+
+// SessionService lists and revokes the sessions of users. A session starts with a login and lasts
+// through its refreshes; revoking it revokes its access and refresh tokens.
+type SessionService interface {
+	// List returns the sessions of the user, flagging the one with the current ID.
+	List(ctx context.Context, userID uuid.UUID, currentID string) ([]*model.Session, error)
+	Revoke(ctx context.Context, userID uuid.UUID, id string) error
+	// RevokeAll logs the user out everywhere.
+	RevokeAll(ctx context.Context, userID uuid.UUID) error
+	// RevokeOthers logs the user out everywhere but in the session with the kept ID.
+	RevokeOthers(ctx context.Context, userID uuid.UUID, keptID string) error
+}
+
+type sessionService struct {
+	tokenRepo repository.TokenRepository
+}
+
+func NewSessionService(tokenRepo repository.TokenRepository) SessionService {
+	return &sessionService{
+		tokenRepo: tokenRepo,
+	}
+}
+
+func (s *sessionService) List(ctx context.Context, userID uuid.UUID, currentID string) ([]*model.Session, error) {
+	sessions, err := s.tokenRepo.FindSessions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, session := range sessions {
+		session.Current = session.ID == currentID
+	}
+	return sessions, nil
+}
+
+func (s *sessionService) Revoke(ctx context.Context, userID uuid.UUID, id string) error {
+	session, err := s.tokenRepo.FindSession(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if session == nil {
+		return fmt.Errorf("session %w", ErrNotFound)
+	}
+	return s.tokenRepo.RevokeFamily(ctx, userID, id)
+}
+
+func (s *sessionService) RevokeAll(ctx context.Context, userID uuid.UUID) error {
+	sessions, err := s.tokenRepo.FindSessions(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return s.revoke(ctx, userID, sessions)
+}
+
+func (s *sessionService) RevokeOthers(ctx context.Context, userID uuid.UUID, keptID string) error {
+	sessions, err := s.tokenRepo.FindSessions(ctx, userID)
+	if err != nil {
+		return err
+	}
+	sessions = slices.DeleteFunc(sessions, func(session *model.Session) bool {
+		return session.ID == keptID
+	})
+	return s.revoke(ctx, userID, sessions)
+}
+
+func (s *sessionService) revoke(ctx context.Context, userID uuid.UUID, sessions []*model.Session) error {
+	for _, session := range sessions {
+		if err := s.tokenRepo.RevokeFamily(ctx, userID, session.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
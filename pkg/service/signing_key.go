@@ -0,0 +1,330 @@
+package service
+
+import (
+	"context"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"flagon/pkg/config"
+	"flagon/pkg/model"
+	"flagon/pkg/oidc"
+	"flagon/pkg/repository"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	// signingKeyCacheTTL is how long keys are used from memory before being read again, so keys
+	// rotated by other instances are picked up.
+	signingKeyCacheTTL = time.Minute
+	// signingKeyReloadInterval limits the reads caused by tokens with unknown key IDs.
+	signingKeyReloadInterval = 10 * time.Second
+	// signingKeyClockSkew covers the clocks of the services verifying tokens running behind.
+	signingKeyClockSkew = 5 * time.Minute
+	// encryptedKeyPrefix marks private keys encrypted with the signing key encryption key. Keys
+	// stored before it was configured remain plain PEM.
+	encryptedKeyPrefix = "enc:v1:"
+)
+
+// SigningAlgorithms are the algorithms JWTs can be signed with.
+var SigningAlgorithms = []string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}
+
+// SigningKeyService signs the JWTs Flagon issues and publishes the keys verifying them. The signing
+// key is replaced once it is older than the rotation interval; replaced keys keep verifying until
+// the tokens they signed have expired.
+type SigningKeyService interface {
+	// Sign signs the claims with the current key, rotating it first when it is due.
+	Sign(ctx context.Context, claims jwt.Claims) (string, error)
+	// VerificationKey returns the key verifying a token, for use as a jwt.Keyfunc.
+	VerificationKey(ctx context.Context, token *jwt.Token) (any, error)
+	// JWKS returns the public keys verifying the tokens that have not expired yet.
+	JWKS(ctx context.Context) (*oidc.JSONWebKeySet, error)
+	// Rotate creates a new signing key.
+	Rotate(ctx context.Context) error
+}
+
+type signingKeyService struct {
+	keyRepo          repository.SigningKeyRepository
+	algorithm        string
+	rotationInterval time.Duration
+	// retention is how long a replaced key keeps verifying: the lifetime of the longest-lived token.
+	retention time.Duration
+	// encryption encrypts the private keys in the database, unless nil.
+	encryption cipher.AEAD
+
+	mu       sync.Mutex
+	keys     []*signingKey
+	loadedAt time.Time
+}
+
+// signingKey is a parsed model.SigningKey. keys are ordered newest first, so keys[0] signs.
+type signingKey struct {
+	id        string
+	method    jwt.SigningMethod
+	private   crypto.Signer
+	createdAt time.Time
+}
+
+func NewSigningKeyService(keyRepo repository.SigningKeyRepository) (SigningKeyService, error) {
+	cfg := config.GetConfig()
+	encryption, err := newKeyEncryption(cfg.Auth.SigningKeyEncryptionKey)
+	if err != nil {
+		return nil, err
+	}
+	if encryption == nil {
+		slog.Warn("JWT signing keys are stored unencrypted: set auth.signingKeyEncryptionKey to encrypt them")
+	}
+	return &signingKeyService{
+		keyRepo:          keyRepo,
+		algorithm:        cfg.Auth.SigningAlgorithm,
+		rotationInterval: cfg.Auth.KeyRotationInterval,
+		retention:        signingKeyRetention(cfg.Auth),
+		encryption:       encryption,
+	}, nil
+}
+
+// signingKeyRetention is how long a replaced key keeps verifying. Tokens are not accepted after they
+// expire, but a key must outlive them however the lifetimes are configured, with some clock skew.
+func signingKeyRetention(cfg config.Authentication) time.Duration {
+	return max(cfg.AccessTokenLifetime, cfg.RefreshTokenLifetime) + signingKeyClockSkew
+}
+
+// newKeyEncryption derives the AES-256-GCM cipher of the private keys from the configured secret,
+// or returns nil without one.
+func newKeyEncryption(secret string) (cipher.AEAD, error) {
+	if secret == "" {
+		return nil, nil
+	}
+	key, err := hkdf.Key(sha256.New, []byte(secret), nil, "flagon signing keys", 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (s *signingKeyService) Sign(ctx context.Context, claims jwt.Claims) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(ctx, false); err != nil {
+		return "", err
+	}
+	if len(s.keys) == 0 || time.Since(s.keys[0].createdAt) >= s.rotationInterval {
+		if err := s.rotate(ctx); err != nil {
+			return "", err
+		}
+	}
+
+	key := s.keys[0]
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.id
+	return token.SignedString(key.private)
+}
+
+func (s *signingKeyService) VerificationKey(ctx context.Context, token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(ctx, false); err != nil {
+		return nil, err
+	}
+	key := s.find(kid)
+	if key == nil && time.Since(s.loadedAt) >= signingKeyReloadInterval {
+		// The key may have been created by another instance since the keys were read.
+		if err := s.load(ctx, true); err != nil {
+			return nil, err
+		}
+		key = s.find(kid)
+	}
+	if key == nil {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	// The algorithm is the key's, whatever the token claims.
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, jwt.ErrTokenSignatureInvalid
+	}
+	return key.private.Public(), nil
+}
+
+func (s *signingKeyService) JWKS(ctx context.Context) (*oidc.JSONWebKeySet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(ctx, false); err != nil {
+		return nil, err
+	}
+	set := &oidc.JSONWebKeySet{Keys: make([]oidc.JSONWebKey, 0, len(s.keys))}
+	for _, key := range s.keys {
+		jwk, err := oidc.NewJSONWebKey(key.id, key.method.Alg(), key.private.Public())
+		if err != nil {
+			return nil, err
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set, nil
+}
+
+func (s *signingKeyService) Rotate(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rotate(ctx)
+}
+
+func (s *signingKeyService) rotate(ctx context.Context) error {
+	private, err := generateSigningKey(s.algorithm)
+	if err != nil {
+		return err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return err
+	}
+	key := &model.SigningKey{
+		ID:        uuid.NewString(),
+		Algorithm: s.algorithm,
+		CreatedAt: time.Now(),
+	}
+	key.PrivateKey, err = s.seal(key.ID, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	if err != nil {
+		return err
+	}
+	if err := s.keyRepo.Create(ctx, key); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "Rotated JWT signing key", "kid", key.ID, "algorithm", key.Algorithm)
+	return s.load(ctx, true)
+}
+
+// load reads the keys when the cached ones are stale, and deletes the keys no unexpired token was
+// signed with. A key is last used when the next one is created, so its tokens expire by then plus
+// the retention. Rotations racing on several instances only make a key sign for a short while.
+func (s *signingKeyService) load(ctx context.Context, force bool) error {
+	if !force && !s.loadedAt.IsZero() && time.Since(s.loadedAt) < signingKeyCacheTTL {
+		return nil
+	}
+	stored, err := s.keyRepo.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]*signingKey, 0, len(stored))
+	var expired []string
+	for i, record := range stored {
+		if i > 0 && time.Since(stored[i-1].CreatedAt) > s.retention {
+			expired = append(expired, record.ID)
+			continue
+		}
+		key, err := s.parse(record)
+		if err != nil {
+			return fmt.Errorf("signing key %s: %w", record.ID, err)
+		}
+		keys = append(keys, key)
+	}
+	if len(expired) > 0 {
+		if err := s.keyRepo.Delete(ctx, expired); err != nil {
+			return err
+		}
+	}
+	s.keys = keys
+	s.loadedAt = time.Now()
+	return nil
+}
+
+func (s *signingKeyService) find(kid string) *signingKey {
+	for _, key := range s.keys {
+		if key.id == kid {
+			return key
+		}
+	}
+	return nil
+}
+
+func generateSigningKey(algorithm string) (crypto.Signer, error) {
+	switch algorithm {
+	case jwt.SigningMethodRS256.Alg():
+		return rsa.GenerateKey(rand.Reader, 2048)
+	case jwt.SigningMethodEdDSA.Alg():
+		_, private, err := ed25519.GenerateKey(rand.Reader)
+		return private, err
+	}
+	return nil, fmt.Errorf("unsupported signing algorithm %q", algorithm)
+}
+
+// seal encrypts the PEM of a private key when encryption is configured. The key ID is authenticated
+// with it, so that the private keys of two rows cannot be swapped.
+func (s *signingKeyService) seal(id string, privatePEM []byte) (string, error) {
+	if s.encryption == nil {
+		return string(privatePEM), nil
+	}
+	nonce := make([]byte, s.encryption.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := s.encryption.Seal(nonce, nonce, privatePEM, []byte(id))
+	return encryptedKeyPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// open returns the PEM of a private key, decrypting it when it was stored encrypted.
+func (s *signingKeyService) open(key *model.SigningKey) ([]byte, error) {
+	encoded, encrypted := strings.CutPrefix(key.PrivateKey, encryptedKeyPrefix)
+	if !encrypted {
+		return []byte(key.PrivateKey), nil
+	}
+	if s.encryption == nil {
+		return nil, errors.New("private key is encrypted but auth.signingKeyEncryptionKey is not set")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	nonceSize := s.encryption.NonceSize()
+	if len(sealed) < nonceSize {
+		return nil, errors.New("encrypted private key is truncated")
+	}
+	privatePEM, err := s.encryption.Open(nil, sealed[:nonceSize], sealed[nonceSize:], []byte(key.ID))
+	if err != nil {
+		return nil, errors.New("private key cannot be decrypted with auth.signingKeyEncryptionKey")
+	}
+	return privatePEM, nil
+}
+
+func (s *signingKeyService) parse(key *model.SigningKey) (*signingKey, error) {
+	privatePEM, err := s.open(key)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(privatePEM)
+	if block == nil {
+		return nil, errors.New("private key is not PEM-encoded")
+	}
+	private, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := private.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", private)
+	}
+	method := jwt.GetSigningMethod(key.Algorithm)
+	if method == nil {
+		return nil, fmt.Errorf("unsupported signing algorithm %q", key.Algorithm)
+	}
+	return &signingKey{id: key.ID, method: method, private: signer, createdAt: key.CreatedAt}, nil
+}
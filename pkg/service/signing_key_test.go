@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"flagon/pkg/config"
+	"flagon/pkg/model"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// memorySigningKeyRepository keeps signing keys in memory, newest first.
+type memorySigningKeyRepository struct {
+	keys []*model.SigningKey
+}
+
+func (r *memorySigningKeyRepository) Create(_ context.Context, key *model.SigningKey) error {
+	r.keys = append([]*model.SigningKey{key}, r.keys...)
+	return nil
+}
+
+func (r *memorySigningKeyRepository) FindAll(context.Context) ([]*model.SigningKey, error) {
+	return slices.Clone(r.keys), nil
+}
+
+func (r *memorySigningKeyRepository) Delete(_ context.Context, ids []string) error {
+	r.keys = slices.DeleteFunc(r.keys, func(key *model.SigningKey) bool {
+		return slices.Contains(ids, key.ID)
+	})
+	return nil
+}
+
+func newTestSigningKeyService(t *testing.T, repo *memorySigningKeyRepository, secret string) *signingKeyService {
+	t.Helper()
+	encryption, err := newKeyEncryption(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &signingKeyService{
+		keyRepo:          repo,
+		algorithm:        jwt.SigningMethodEdDSA.Alg(),
+		rotationInterval: time.Hour,
+		// Lifetimes left unset must not make replaced keys stop verifying at once.
+		retention:  signingKeyRetention(config.Authentication{}),
+		encryption: encryption,
+	}
+}
+
+func verify(ctx context.Context, s SigningKeyService, token string) error {
+	_, err := jwt.Parse(token, func(token *jwt.Token) (any, error) {
+		return s.VerificationKey(ctx, token)
+	})
+	return err
+}
+
+func TestReplacedSigningKeyKeepsVerifying(t *testing.T) {
+	ctx := context.Background()
+	repo := &memorySigningKeyRepository{}
+	s := newTestSigningKeyService(t, repo, "")
+
+	token, err := s.Sign(ctx, jwt.RegisteredClaims{Subject: "user"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Rotate(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Rotate(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := verify(ctx, s, token); err != nil {
+		t.Errorf("token of a key replaced within the retention: %v", err)
+	}
+
+	// Once the retention has passed since its replacement, the key is deleted.
+	for _, key := range repo.keys {
+		key.CreatedAt = key.CreatedAt.Add(-signingKeyClockSkew - time.Minute)
+	}
+	if err := s.Rotate(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := verify(ctx, s, token); err == nil {
+		t.Error("token of an expired key verified")
+	}
+	if len(repo.keys) != 2 {
+		t.Errorf("%d keys stored, want the new key and the one it replaced", len(repo.keys))
+	}
+}
+
+func TestSigningKeyEncryption(t *testing.T) {
+	ctx := context.Background()
+	const secret = "0123456789abcdef0123456789abcdef"
+
+	tests := []struct {
+		name       string
+		readSecret string
+		wantErr    string
+	}{
+		{name: "same secret", readSecret: secret},
+		{name: "other secret", readSecret: "fedcba9876543210fedcba9876543210", wantErr: "cannot be decrypted"},
+		{name: "no secret", readSecret: "", wantErr: "is not set"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &memorySigningKeyRepository{}
+			token, err := newTestSigningKeyService(t, repo, secret).Sign(ctx, jwt.RegisteredClaims{Subject: "user"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stored := repo.keys[0].PrivateKey; !strings.HasPrefix(stored, encryptedKeyPrefix) || strings.Contains(stored, "PRIVATE KEY") {
+				t.Fatalf("private key stored as %.40q, want it encrypted", stored)
+			}
+
+			err = verify(ctx, newTestSigningKeyService(t, repo, tt.readSecret), token)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("verify: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("verify: %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSigningKeyRowsCannotBeSwapped(t *testing.T) {
+	ctx := context.Background()
+	repo := &memorySigningKeyRepository{}
+	s := newTestSigningKeyService(t, repo, "0123456789abcdef0123456789abcdef")
+	if err := s.Rotate(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Rotate(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	repo.keys[0].PrivateKey, repo.keys[1].PrivateKey = repo.keys[1].PrivateKey, repo.keys[0].PrivateKey
+	if _, err := newTestSigningKeyService(t, repo, "0123456789abcdef0123456789abcdef").JWKS(ctx); err == nil {
+		t.Error("keys moved to another row were accepted")
+	}
+}
@@ -0,0 +1,261 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"flagon/pkg/evaluation"
+	"flagon/pkg/model"
+	"flagon/pkg/repository"
+	"log/slog"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// subscriberBuffer is how many events a slow stream client may lag behind before it is disconnected.
+// It reconnects with the ID of the last event it received and resumes from there.
+const subscriberBuffer = 64
+
+// StreamService publishes flag configuration changes to the SDKs streaming an environment.
+//
+// Events are appended to a log per environment and broadcast to every server instance, each of which
+// forwards them to its own subscribers. A subscriber first receives a snapshot of the environment, or
+// the events it missed when it resumes after a known event ID, then a patch for every change.
+type StreamService interface {
+	// Subscribe streams the events of the token's environment until the context is done. The channel
+	// is closed when the subscription ends.
+	Subscribe(ctx context.Context, token *model.AccessToken, lastEventID string) (<-chan *model.FlagEvent, error)
+	// PublishFlags sends the current state of the flags with the given keys, which may no longer exist,
+	// to the environments. Nil environments means every environment of the project.
+	PublishFlags(ctx context.Context, projectID uuid.UUID, envIDs []uuid.UUID, keys ...string)
+	// PublishTargetGroups sends the current target groups of the environments.
+	PublishTargetGroups(ctx context.Context, projectID uuid.UUID, envIDs []uuid.UUID)
+}
+
+type streamService struct {
+	eventRepo    repository.FlagEventRepository
+	snapshotRepo repository.SnapshotRepository
+	envRepo      repository.EnvironmentRepository
+
+	mu          sync.Mutex
+	listening   bool
+	subscribers map[uuid.UUID]map[chan *model.FlagEvent]struct{}
+}
+
+func NewStreamService(
+	eventRepo repository.FlagEventRepository,
+	snapshotRepo repository.SnapshotRepository,
+	envRepo repository.EnvironmentRepository,
+) StreamService {
+	return &streamService{
+		eventRepo:    eventRepo,
+		snapshotRepo: snapshotRepo,
+		envRepo:      envRepo,
+		subscribers:  make(map[uuid.UUID]map[chan *model.FlagEvent]struct{}),
+	}
+}
+
+func (s *streamService) Subscribe(ctx context.Context, token *model.AccessToken, lastEventID string) (<-chan *model.FlagEvent, error) {
+	if err := s.listen(); err != nil {
+		return nil, err
+	}
+
+	// Register before reading the log so nothing published meanwhile is lost; duplicates are skipped below.
+	envID := *token.EnvironmentID
+	live := s.register(envID)
+
+	initial, err := s.initialEvents(ctx, *token.ProjectID, envID, lastEventID)
+	if err != nil {
+		s.unregister(envID, live)
+		return nil, err
+	}
+
+	events := make(chan *model.FlagEvent)
+	go func() {
+		defer close(events)
+		defer s.unregister(envID, live)
+
+		last := lastEventID
+		for _, event := range initial {
+			select {
+			case events <- event:
+				last = event.ID
+			case <-ctx.Done():
+				return
+			}
+		}
+		for {
+			select {
+			case event, ok := <-live:
+				if !ok {
+					return
+				}
+				if !event.After(last) {
+					continue
+				}
+				select {
+				case events <- event:
+					last = event.ID
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, nil
+}
+
+// initialEvents returns the events missed since lastEventID, or a snapshot when they are not all known.
+func (s *streamService) initialEvents(ctx context.Context, projectID, envID uuid.UUID, lastEventID string) ([]*model.FlagEvent, error) {
+	if lastEventID != "" {
+		events, ok, err := s.eventRepo.Since(ctx, envID, lastEventID)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return events, nil
+		}
+	}
+
+	// Read the position before the snapshot, so the snapshot includes at least every event up to it.
+	latest, err := s.eventRepo.Latest(ctx, envID)
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := s.snapshotRepo.Snapshot(ctx, projectID, envID)
+	if err != nil {
+		return nil, translateError(err, "environment")
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	return []*model.FlagEvent{{ID: latest, EnvironmentID: envID, Type: model.FlagEventTypeSnapshot, Data: data}}, nil
+}
+
+func (s *streamService) PublishFlags(ctx context.Context, projectID uuid.UUID, envIDs []uuid.UUID, keys ...string) {
+	s.publish(ctx, projectID, envIDs, func(snapshot *evaluation.Snapshot) *evaluation.Patch {
+		patch := &evaluation.Patch{}
+		for _, key := range keys {
+			if flag, ok := snapshot.Flag(key); ok {
+				patch.Flags = append(patch.Flags, flag)
+			} else {
+				patch.DeletedFlags = append(patch.DeletedFlags, key)
+			}
+		}
+		return patch
+	})
+}
+
+func (s *streamService) PublishTargetGroups(ctx context.Context, projectID uuid.UUID, envIDs []uuid.UUID) {
+	s.publish(ctx, projectID, envIDs, func(snapshot *evaluation.Snapshot) *evaluation.Patch {
+		return &evaluation.Patch{TargetGroups: snapshot.TargetGroups}
+	})
+}
+
+// publish appends a patch built from the current snapshot of each environment. The change it describes
+// is already saved, so failures are logged rather than returned; affected clients catch up when they
+// reconnect and receive a new snapshot.
+func (s *streamService) publish(ctx context.Context, projectID uuid.UUID, envIDs []uuid.UUID, build func(*evaluation.Snapshot) *evaluation.Patch) {
+	if envIDs == nil {
+		envs, err := s.envRepo.FindByProject(ctx, projectID)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to publish flag changes", "project", projectID, "error", err)
+			return
+		}
+		for _, env := range envs {
+			envIDs = append(envIDs, env.ID)
+		}
+	}
+
+	for _, envID := range envIDs {
+		snapshot, err := s.snapshotRepo.Snapshot(ctx, projectID, envID)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to publish flag changes", "environment", envID, "error", err)
+			continue
+		}
+		data, err := json.Marshal(build(snapshot))
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to publish flag changes", "environment", envID, "error", err)
+			continue
+		}
+		event := &model.FlagEvent{EnvironmentID: envID, Type: model.FlagEventTypePatch, Data: data}
+		if err := s.eventRepo.Append(ctx, event); err != nil {
+			slog.ErrorContext(ctx, "Failed to publish flag changes", "environment", envID, "error", err)
+		}
+	}
+}
+
+// listen starts forwarding broadcast events to the subscribers of this instance, unless it already does.
+func (s *streamService) listen() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.listening {
+		return nil
+	}
+
+	events, err := s.eventRepo.Listen(context.Background())
+	if err != nil {
+		return err
+	}
+	s.listening = true
+	go s.dispatch(events)
+	return nil
+}
+
+func (s *streamService) dispatch(events <-chan *model.FlagEvent) {
+	for event := range events {
+		s.mu.Lock()
+		for live := range s.subscribers[event.EnvironmentID] {
+			select {
+			case live <- event:
+			default:
+				// Too far behind: disconnect it rather than hold up everyone else.
+				s.drop(event.EnvironmentID, live)
+			}
+		}
+		s.mu.Unlock()
+	}
+
+	// The broadcast ended, so end every subscription to make clients reconnect and listen again.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listening = false
+	for envID, subscribers := range s.subscribers {
+		for live := range subscribers {
+			s.drop(envID, live)
+		}
+	}
+}
+
+func (s *streamService) register(envID uuid.UUID) chan *model.FlagEvent {
+	live := make(chan *model.FlagEvent, subscriberBuffer)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.subscribers[envID] == nil {
+		s.subscribers[envID] = make(map[chan *model.FlagEvent]struct{})
+	}
+	s.subscribers[envID][live] = struct{}{}
+	return live
+}
+
+func (s *streamService) unregister(envID uuid.UUID, live chan *model.FlagEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.drop(envID, live)
+}
+
+// drop removes a subscriber and closes its channel. The caller must hold s.mu.
+func (s *streamService) drop(envID uuid.UUID, live chan *model.FlagEvent) {
+	subscribers := s.subscribers[envID]
+	if _, ok := subscribers[live]; !ok {
+		return
+	}
+	delete(subscribers, live)
+	close(live)
+	if len(subscribers) == 0 {
+		delete(s.subscribers, envID)
+	}
+}
@@ -0,0 +1,365 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"flagon/pkg/evaluation"
+	"flagon/pkg/model"
+	"flagon/pkg/repository"
+	"log/slog"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type TargetGroupService interface {
+	List(ctx context.Context, projectSlug string) ([]*model.TargetGroup, error)
+	Get(ctx context.Context, projectSlug string, id uuid.UUID) (*model.TargetGroup, error)
+	Create(ctx context.Context, projectSlug string, req *CreateTargetGroupRequest) (*model.TargetGroup, error)
+	Update(ctx context.Context, projectSlug string, id uuid.UUID, req *UpdateTargetGroupRequest) (*model.TargetGroup, error)
+	Reorder(ctx context.Context, projectSlug string, req *ReorderTargetGroupsRequest) ([]*model.TargetGroup, error)
+	Delete(ctx context.Context, projectSlug string, id uuid.UUID) error
+
+	ListEnvironments(ctx context.Context, projectSlug string, id uuid.UUID) ([]*model.ProjectEnvironment, error)
+	AddEnvironment(ctx context.Context, projectSlug string, id, envID uuid.UUID) error
+	RemoveEnvironment(ctx context.Context, projectSlug string, id, envID uuid.UUID) error
+}
+
+type targetGroupService struct {
+	targetGroupRepo repository.TargetGroupRepository
+	envRepo         repository.EnvironmentRepository
+	projectService  ProjectService
+	streamService   StreamService
+	auditService    AuditService
+}
+
+func NewTargetGroupService(
+	targetGroupRepo repository.TargetGroupRepository,
+	envRepo repository.EnvironmentRepository,
+	projectService ProjectService,
+	streamService StreamService,
+	auditService AuditService,
+) TargetGroupService {
+	return &targetGroupService{
+		targetGroupRepo: targetGroupRepo,
+		envRepo:         envRepo,
+		projectService:  projectService,
+		streamService:   streamService,
+		auditService:    auditService,
+	}
+}
+
+type CreateTargetGroupRequest struct {
+	Name        string `json:"name" binding:"required,max=255"`
+	Description string `json:"description"`
+	// RolloutPercentage defaults to 100, including every context matching the rules.
+	// It is applied with a precision of 0.001 percent.
+	RolloutPercentage *float64 `json:"rollout_percentage" binding:"omitempty,min=0,max=100"`
+	// BucketBy is the context attribute rollouts are bucketed by, e.g. accountId. It defaults to the context key.
+	BucketBy string `json:"bucket_by" binding:"max=255"`
+	// Rules is a rule tree as described by evaluation.Rule. Omit it to include everyone.
+	Rules json.RawMessage `json:"rules" swaggertype:"object"`
+}
+
+type UpdateTargetGroupRequest struct {
+	Name              *string  `json:"name" binding:"omitempty,min=1,max=255"`
+	Description       *string  `json:"description"`
+	RolloutPercentage *float64 `json:"rollout_percentage" binding:"omitempty,min=0,max=100"`
+	BucketBy          *string  `json:"bucket_by" binding:"omitempty,max=255"`
+	// RolloutSalt changes which contexts fall into a partial rollout when set to a new value.
+	RolloutSalt *string `json:"rollout_salt" binding:"omitempty,min=1,max=255"`
+	// Rules replaces the rule tree when present; null removes the rules.
+	Rules json.RawMessage `json:"rules" swaggertype:"object"`
+}
+
+type ReorderTargetGroupsRequest struct {
+	// TargetGroupIDs lists every target group of the project, from the highest precedence to the lowest.
+	TargetGroupIDs []uuid.UUID `json:"target_group_ids" binding:"required"`
+}
+
+func (s *targetGroupService) List(ctx context.Context, projectSlug string) ([]*model.TargetGroup, error) {
+	project, err := s.projectService.Get(ctx, projectSlug)
+	if err != nil {
+		return nil, err
+	}
+	return s.targetGroupRepo.FindByProject(ctx, project.ID)
+}
+
+func (s *targetGroupService) Get(ctx context.Context, projectSlug string, id uuid.UUID) (*model.TargetGroup, error) {
+	project, err := s.projectService.Get(ctx, projectSlug)
+	if err != nil {
+		return nil, err
+	}
+	group, err := s.targetGroupRepo.FindByID(ctx, project.ID, id)
+	if err != nil {
+		return nil, translateError(err, "target group")
+	}
+	return group, nil
+}
+
+func (s *targetGroupService) Create(ctx context.Context, projectSlug string, req *CreateTargetGroupRequest) (*model.TargetGroup, error) {
+	rules, err := normalizeRules(req.Rules)
+	if err != nil {
+		return nil, err
+	}
+	project, err := s.projectService.Get(ctx, projectSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.targetGroupRepo.FindByProject(ctx, project.ID)
+	if err != nil {
+		return nil, err
+	}
+	position := 0
+	for _, group := range existing {
+		position = max(position, group.Position+1)
+	}
+
+	group := &model.TargetGroup{
+		ID:                uuid.New(),
+		ProjectID:         project.ID,
+		Name:              req.Name,
+		Description:       req.Description,
+		Position:          position,
+		RolloutPercentage: 100,
+		RolloutSalt:       uuid.NewString(),
+		BucketBy:          strings.TrimSpace(req.BucketBy),
+		Rules:             rules,
+	}
+	if req.RolloutPercentage != nil {
+		group.RolloutPercentage = *req.RolloutPercentage
+	}
+	if err := s.targetGroupRepo.Create(ctx, group); err != nil {
+		return nil, translateError(err, "target group")
+	}
+	s.auditService.Record(ctx, model.AuditActionCreate, auditTargetGroup(group), nil, group)
+	return group, nil
+}
+
+func (s *targetGroupService) Update(ctx context.Context, projectSlug string, id uuid.UUID, req *UpdateTargetGroupRequest) (*model.TargetGroup, error) {
+	group, err := s.Get(ctx, projectSlug, id)
+	if err != nil {
+		return nil, err
+	}
+	before := *group
+
+	if req.Rules != nil {
+		rules, err := normalizeRules(req.Rules)
+		if err != nil {
+			return nil, err
+		}
+		group.Rules = rules
+	}
+	if req.Name != nil {
+		group.Name = *req.Name
+	}
+	if req.Description != nil {
+		group.Description = *req.Description
+	}
+	if req.RolloutPercentage != nil {
+		group.RolloutPercentage = *req.RolloutPercentage
+	}
+	if req.BucketBy != nil {
+		group.BucketBy = strings.TrimSpace(*req.BucketBy)
+	}
+	if req.RolloutSalt != nil {
+		group.RolloutSalt = *req.RolloutSalt
+	}
+
+	if err := s.targetGroupRepo.Update(ctx, group); err != nil {
+		return nil, translateError(err, "target group")
+	}
+	s.auditService.Record(ctx, model.AuditActionUpdate, auditTargetGroup(group), &before, group)
+	if err := s.publish(ctx, group); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// Reorder sets the precedence of all target groups of the project and publishes it to every
+// environment, as it changes which group's flag state applies to contexts in several groups.
+func (s *targetGroupService) Reorder(ctx context.Context, projectSlug string, req *ReorderTargetGroupsRequest) ([]*model.TargetGroup, error) {
+	project, err := s.projectService.Get(ctx, projectSlug)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := s.targetGroupRepo.FindByProject(ctx, project.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(req.TargetGroupIDs) != len(groups) {
+		return nil, invalidArgument("target_group_ids must list all %d target groups of the project", len(groups))
+	}
+	before := make(map[uuid.UUID]*model.TargetGroup, len(groups))
+	for _, group := range groups {
+		before[group.ID] = group
+	}
+	seen := make(map[uuid.UUID]bool, len(groups))
+	for _, id := range req.TargetGroupIDs {
+		if before[id] == nil || seen[id] {
+			return nil, invalidArgument("target group %s is unknown or listed twice", id)
+		}
+		seen[id] = true
+	}
+
+	if err := s.targetGroupRepo.UpdatePositions(ctx, project.ID, req.TargetGroupIDs); err != nil {
+		return nil, err
+	}
+	reordered, err := s.targetGroupRepo.FindByProject(ctx, project.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range reordered {
+		if old := before[group.ID]; old != nil && old.Position != group.Position {
+			s.auditService.Record(ctx, model.AuditActionUpdate, auditTargetGroup(group), old, group)
+		}
+	}
+
+	envs, err := s.envRepo.FindByProject(ctx, project.ID)
+	if err != nil {
+		return nil, err
+	}
+	s.streamService.PublishTargetGroups(ctx, project.ID, environmentIDs(envs))
+	return reordered, nil
+}
+
+// Delete removes the target group together with its environment links and flag states.
+func (s *targetGroupService) Delete(ctx context.Context, projectSlug string, id uuid.UUID) error {
+	group, err := s.Get(ctx, projectSlug, id)
+	if err != nil {
+		return err
+	}
+	envs, err := s.targetGroupRepo.FindEnvironments(ctx, group.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.targetGroupRepo.Delete(ctx, group.ID); err != nil {
+		return err
+	}
+	s.streamService.PublishTargetGroups(ctx, group.ProjectID, environmentIDs(envs))
+	s.auditService.Record(ctx, model.AuditActionDelete, auditTargetGroup(group), group, nil)
+	return nil
+}
+
+func (s *targetGroupService) ListEnvironments(ctx context.Context, projectSlug string, id uuid.UUID) ([]*model.ProjectEnvironment, error) {
+	group, err := s.Get(ctx, projectSlug, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.targetGroupRepo.FindEnvironments(ctx, group.ID)
+}
+
+// AddEnvironment enables the target group in the environment, so its flag states there are evaluated.
+func (s *targetGroupService) AddEnvironment(ctx context.Context, projectSlug string, id, envID uuid.UUID) error {
+	group, env, err := s.findWithEnvironment(ctx, projectSlug, id, envID)
+	if err != nil {
+		return err
+	}
+	before, err := s.environmentState(ctx, group.ID)
+	if err != nil {
+		return err
+	}
+	err = s.targetGroupRepo.AddEnvironment(ctx, &model.ProjectTargetGroupEnvironment{
+		ProjectID:     group.ProjectID,
+		TargetGroupID: group.ID,
+		EnvironmentID: env.ID,
+	})
+	if err != nil {
+		return err
+	}
+	s.streamService.PublishTargetGroups(ctx, group.ProjectID, []uuid.UUID{env.ID})
+	s.recordEnvironments(ctx, group, before)
+	return nil
+}
+
+func (s *targetGroupService) RemoveEnvironment(ctx context.Context, projectSlug string, id, envID uuid.UUID) error {
+	group, env, err := s.findWithEnvironment(ctx, projectSlug, id, envID)
+	if err != nil {
+		return err
+	}
+	before, err := s.environmentState(ctx, group.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.targetGroupRepo.RemoveEnvironment(ctx, group.ID, env.ID); err != nil {
+		return err
+	}
+	s.streamService.PublishTargetGroups(ctx, group.ProjectID, []uuid.UUID{env.ID})
+	s.recordEnvironments(ctx, group, before)
+	return nil
+}
+
+// targetGroupEnvironments is the audited state of the environments a target group is enabled in.
+type targetGroupEnvironments struct {
+	EnvironmentIDs []uuid.UUID `json:"environmentIds"`
+}
+
+func (s *targetGroupService) environmentState(ctx context.Context, id uuid.UUID) (*targetGroupEnvironments, error) {
+	envs, err := s.targetGroupRepo.FindEnvironments(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &targetGroupEnvironments{EnvironmentIDs: environmentIDs(envs)}, nil
+}
+
+// recordEnvironments audits a change of the environments the target group is enabled in.
+func (s *targetGroupService) recordEnvironments(ctx context.Context, group *model.TargetGroup, before *targetGroupEnvironments) {
+	after, err := s.environmentState(ctx, group.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to record audit event", "target_group_id", group.ID, "error", err)
+		return
+	}
+	s.auditService.Record(ctx, model.AuditActionUpdate, auditTargetGroup(group), before, after)
+}
+
+func (s *targetGroupService) findWithEnvironment(ctx context.Context, projectSlug string, id, envID uuid.UUID) (*model.TargetGroup, *model.ProjectEnvironment, error) {
+	group, err := s.Get(ctx, projectSlug, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	env, err := s.envRepo.FindByID(ctx, group.ProjectID, envID)
+	if err != nil {
+		return nil, nil, translateError(err, "environment")
+	}
+	return group, env, nil
+}
+
+// publish sends the target groups of every environment the group is enabled in.
+func (s *targetGroupService) publish(ctx context.Context, group *model.TargetGroup) error {
+	envs, err := s.targetGroupRepo.FindEnvironments(ctx, group.ID)
+	if err != nil {
+		return err
+	}
+	s.streamService.PublishTargetGroups(ctx, group.ProjectID, environmentIDs(envs))
+	return nil
+}
+
+func environmentIDs(envs []*model.ProjectEnvironment) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(envs))
+	for _, env := range envs {
+		ids = append(ids, env.ID)
+	}
+	return ids
+}
+
+// normalizeRules validates rules and re-encodes them, so stored rules are always well-formed and compact.
+func normalizeRules(data json.RawMessage) (model.JSONText, error) {
+	rule, err := evaluation.ParseRules(data)
+	if err != nil {
+		return "", invalidArgument("%v", err)
+	}
+	if rule == nil {
+		return "", nil
+	}
+	encoded, err := json.Marshal(rule)
+	if err != nil {
+		return "", err
+	}
+	return model.JSONText(encoded), nil
+}
+
+func auditTargetGroup(group *model.TargetGroup) AuditResource {
+	return projectAudit(model.AuditResourceTargetGroup, group.ID.String(), group.ProjectID)
+}
@@ -0,0 +1,285 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"flagon/pkg/model"
+	"flagon/pkg/repository"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AccessTokenPrefix starts every access token secret, which tells them apart from JWTs.
+const AccessTokenPrefix = "flg_"
+
+// TokenService manages the access tokens of users and resolves their secrets.
+type TokenService interface {
+	Create(ctx context.Context, userID uuid.UUID, req *CreateAccessTokenRequest) (*CreatedAccessToken, error)
+	List(ctx context.Context, userID uuid.UUID) ([]*model.AccessToken, error)
+	Revoke(ctx context.Context, userID, id uuid.UUID) error
+	Authenticate(ctx context.Context, secret string) (*model.AccessToken, error)
+	AuthorizeProject(ctx context.Context, token *model.AccessToken, projectSlug string) error
+	AuthorizeGroup(ctx context.Context, token *model.AccessToken, groupID uuid.UUID) error
+}
+
+type tokenService struct {
+	accessTokenRepo repository.AccessTokenRepository
+	userRepo        repository.UserRepository
+	envRepo         repository.EnvironmentRepository
+	projectRepo     repository.ProjectRepository
+	projectService  ProjectService
+	groupService    ProjectGroupService
+	accessService   AccessService
+	auditService    AuditService
+}
+
+func NewTokenService(
+	accessTokenRepo repository.AccessTokenRepository,
+	userRepo repository.UserRepository,
+	envRepo repository.EnvironmentRepository,
+	projectRepo repository.ProjectRepository,
+	projectService ProjectService,
+	groupService ProjectGroupService,
+	accessService AccessService,
+	auditService AuditService,
+) TokenService {
+	return &tokenService{
+		accessTokenRepo: accessTokenRepo,
+		userRepo:        userRepo,
+		envRepo:         envRepo,
+		projectRepo:     projectRepo,
+		projectService:  projectService,
+		groupService:    groupService,
+		accessService:   accessService,
+		auditService:    auditService,
+	}
+}
+
+// CreateAccessTokenRequest describes a new token. SDK tokens need a project and one of its environments.
+// Personal and service tokens act on the management API; scoping them to a group or a project restricts
+// them to the routes of that group or project.
+type CreateAccessTokenRequest struct {
+	Name          string                `json:"name" binding:"required,max=255"`
+	TokenType     model.AccessTokenType `json:"token_type" binding:"required,oneof=sdk personal service"`
+	GroupID       *uuid.UUID            `json:"group_id"`
+	ProjectID     *uuid.UUID            `json:"project_id"`
+	EnvironmentID *uuid.UUID            `json:"environment_id"`
+}
+
+// CreatedAccessToken carries the secret of a new token. It is only ever returned once.
+type CreatedAccessToken struct {
+	AccessToken *model.AccessToken `json:"accessToken"`
+	Token       string             `json:"token"`
+}
+
+func (s *tokenService) Create(ctx context.Context, userID uuid.UUID, req *CreateAccessTokenRequest) (*CreatedAccessToken, error) {
+	if err := s.checkScope(ctx, userID, req); err != nil {
+		return nil, err
+	}
+
+	secret, err := generateAccessToken()
+	if err != nil {
+		return nil, err
+	}
+	token := &model.AccessToken{
+		ID:            uuid.New(),
+		UserID:        userID,
+		Name:          req.Name,
+		Token:         hashAccessToken(secret),
+		TokenType:     req.TokenType,
+		GroupID:       req.GroupID,
+		ProjectID:     req.ProjectID,
+		EnvironmentID: req.EnvironmentID,
+	}
+	if err := s.accessTokenRepo.Create(ctx, token); err != nil {
+		return nil, translateError(err, "access token")
+	}
+	s.auditService.Record(ctx, model.AuditActionCreate, auditAccessToken(token), nil, token)
+	return &CreatedAccessToken{AccessToken: token, Token: secret}, nil
+}
+
+func (s *tokenService) List(ctx context.Context, userID uuid.UUID) ([]*model.AccessToken, error) {
+	return s.accessTokenRepo.FindByUser(ctx, userID)
+}
+
+func (s *tokenService) Revoke(ctx context.Context, userID, id uuid.UUID) error {
+	token, err := s.accessTokenRepo.FindByID(ctx, userID, id)
+	if err != nil {
+		return translateError(err, "access token")
+	}
+	if err := s.accessTokenRepo.Delete(ctx, token.ID); err != nil {
+		return err
+	}
+	s.auditService.Record(ctx, model.AuditActionDelete, auditAccessToken(token), token, nil)
+	return nil
+}
+
+// Authenticate resolves an access token secret of any type. Tokens act with the current rights of
+// their user, so they stop working when the user is disabled, and SDK tokens also when the user can
+// no longer view their project.
+func (s *tokenService) Authenticate(ctx context.Context, secret string) (*model.AccessToken, error) {
+	if !strings.HasPrefix(secret, AccessTokenPrefix) {
+		return nil, ErrInvalidToken
+	}
+	token, err := s.accessTokenRepo.FindByToken(ctx, hashAccessToken(secret))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.userRepo.FindByID(ctx, token.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user.DisabledAt != nil {
+		return nil, ErrInvalidToken
+	}
+	if token.TokenType == model.AccessTokenTypeSDK && token.ProjectID != nil {
+		if err := s.checkProjectRole(ctx, token.UserID, *token.ProjectID); err != nil {
+			return nil, err
+		}
+	}
+	return token, nil
+}
+
+// checkProjectRole checks that the user of an SDK token still has a role in its project.
+func (s *tokenService) checkProjectRole(ctx context.Context, userID, projectID uuid.UUID) error {
+	project, err := s.projectRepo.FindByID(ctx, projectID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrInvalidToken
+	}
+	if err != nil {
+		return err
+	}
+	role, err := s.accessService.ProjectRole(ctx, userID, project)
+	if err != nil {
+		return err
+	}
+	if role == "" {
+		return ErrInvalidToken
+	}
+	return nil
+}
+
+// AuthorizeProject checks that a management token may act on the project: the project is within the
+// scope of the token, and the user of the token can still view it. The route checks the permission
+// it needs on top.
+func (s *tokenService) AuthorizeProject(ctx context.Context, token *model.AccessToken, projectSlug string) error {
+	project, err := s.projectService.Get(ctx, projectSlug)
+	if err != nil {
+		return err
+	}
+	switch {
+	case token.ProjectID != nil && *token.ProjectID != project.ID:
+		return fmt.Errorf("%w: the access token is scoped to another project", ErrPermissionDenied)
+	case token.ProjectID == nil && token.GroupID != nil:
+		if err := s.authorizeWithinGroup(ctx, token, project.GroupID.UUID); err != nil {
+			return err
+		}
+	}
+	return s.accessService.AuthorizeProject(ctx, token.UserID, project.Slug, model.PermissionView)
+}
+
+// AuthorizeGroup checks that a management token may act on the project group, as AuthorizeProject
+// does for projects.
+func (s *tokenService) AuthorizeGroup(ctx context.Context, token *model.AccessToken, groupID uuid.UUID) error {
+	if token.ProjectID != nil {
+		return fmt.Errorf("%w: the access token is scoped to a project", ErrPermissionDenied)
+	}
+	if token.GroupID != nil {
+		if err := s.authorizeWithinGroup(ctx, token, groupID); err != nil {
+			return err
+		}
+	}
+	return s.accessService.AuthorizeGroup(ctx, token.UserID, groupID, model.PermissionView)
+}
+
+func (s *tokenService) authorizeWithinGroup(ctx context.Context, token *model.AccessToken, groupID uuid.UUID) error {
+	within, err := s.groupService.Contains(ctx, *token.GroupID, groupID)
+	if err != nil {
+		return err
+	}
+	if !within {
+		return fmt.Errorf("%w: the access token is scoped to another project group", ErrPermissionDenied)
+	}
+	return nil
+}
+
+// checkScope validates the scope of a new token and that the user can see what it points to.
+func (s *tokenService) checkScope(ctx context.Context, userID uuid.UUID, req *CreateAccessTokenRequest) error {
+	if req.TokenType == model.AccessTokenTypeSDK {
+		if req.ProjectID == nil || req.EnvironmentID == nil {
+			return invalidArgument("sdk tokens require a project and an environment")
+		}
+		if req.GroupID != nil {
+			return invalidArgument("sdk tokens cannot be scoped to a project group")
+		}
+	} else {
+		if req.EnvironmentID != nil {
+			return invalidArgument("only sdk tokens can be scoped to an environment")
+		}
+		if req.GroupID != nil && req.ProjectID != nil {
+			return invalidArgument("a token can be scoped to a project group or a project, not both")
+		}
+	}
+
+	if req.GroupID != nil {
+		groups, err := s.groupService.List(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if !slices.ContainsFunc(groups, func(group *model.ProjectGroup) bool { return group.ID == *req.GroupID }) {
+			return fmt.Errorf("project group %w", ErrNotFound)
+		}
+	}
+	if req.ProjectID != nil {
+		projects, err := s.projectService.List(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if !slices.ContainsFunc(projects, func(project *model.Project) bool { return project.ID == *req.ProjectID }) {
+			return fmt.Errorf("project %w", ErrNotFound)
+		}
+	}
+	if req.EnvironmentID != nil {
+		if _, err := s.envRepo.FindByID(ctx, *req.ProjectID, *req.EnvironmentID); err != nil {
+			return translateError(err, "environment")
+		}
+	}
+	return nil
+}
+
+func generateAccessToken() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return AccessTokenPrefix + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// hashAccessToken returns the form of an access token secret stored in the database.
+func hashAccessToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// auditAccessToken locates a token in the project or project group it is scoped to, if any.
+func auditAccessToken(token *model.AccessToken) AuditResource {
+	resource := AuditResource{Type: model.AuditResourceAccessToken, ID: token.ID.String()}
+	switch {
+	case token.ProjectID != nil:
+		resource = projectAudit(resource.Type, resource.ID, *token.ProjectID)
+	case token.GroupID != nil:
+		resource = groupAudit(resource.Type, resource.ID, *token.GroupID)
+	}
+	return resource
+}
@@ -0,0 +1,260 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"flagon/pkg/model"
+	"flagon/pkg/repository"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// memoryProjectRepository keeps projects and their member roles in memory.
+type memoryProjectRepository struct {
+	repository.ProjectRepository
+	projects []*model.Project
+	roles    map[uuid.UUID]map[uuid.UUID]model.Role
+}
+
+func (r *memoryProjectRepository) FindByID(_ context.Context, id uuid.UUID) (*model.Project, error) {
+	for _, project := range r.projects {
+		if project.ID == id {
+			return project, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryProjectRepository) FindBySlug(_ context.Context, slug string) (*model.Project, error) {
+	for _, project := range r.projects {
+		if project.Slug == slug {
+			return project, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryProjectRepository) FindMemberRole(_ context.Context, projectID, userID uuid.UUID) (model.Role, error) {
+	return r.roles[projectID][userID], nil
+}
+
+// memoryGroupRepository keeps project groups and their member roles in memory.
+type memoryGroupRepository struct {
+	repository.ProjectGroupRepository
+	groups []*model.ProjectGroup
+	roles  map[uuid.UUID]map[uuid.UUID]model.Role
+}
+
+func (r *memoryGroupRepository) FindByID(_ context.Context, id uuid.UUID) (*model.ProjectGroup, error) {
+	for _, group := range r.groups {
+		if group.ID == id {
+			return group, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryGroupRepository) FindMemberRole(_ context.Context, groupID, userID uuid.UUID) (model.Role, error) {
+	return r.roles[groupID][userID], nil
+}
+
+// memoryAccessTokenRepository finds access tokens by the hash of their secret.
+type memoryAccessTokenRepository struct {
+	repository.AccessTokenRepository
+	tokens map[string]*model.AccessToken
+}
+
+func (r *memoryAccessTokenRepository) FindByToken(_ context.Context, tokenHash string) (*model.AccessToken, error) {
+	if token, ok := r.tokens[tokenHash]; ok {
+		return token, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// tokenFixture is a group with a project and another project outside of it. The user created tokens
+// as an editor of the group.
+type tokenFixture struct {
+	service TokenService
+	groups  *memoryGroupRepository
+	user    *model.User
+	group   *model.ProjectGroup
+	project *model.Project
+	tokens  *memoryAccessTokenRepository
+	envID   uuid.UUID
+}
+
+func newTokenFixture() *tokenFixture {
+	owner := &model.User{ID: uuid.New(), Username: "owner"}
+	user := &model.User{ID: uuid.New(), Username: "bob"}
+	group := &model.ProjectGroup{ID: uuid.New(), OwnerID: owner.ID, Slug: "acme"}
+	project := &model.Project{ID: uuid.New(), Slug: "shop", OwnerID: owner.ID, GroupID: uuid.NullUUID{UUID: group.ID, Valid: true}}
+	other := &model.Project{ID: uuid.New(), Slug: "blog", OwnerID: owner.ID}
+
+	users := &memoryUserRepository{users: []*model.User{owner, user}}
+	groups := &memoryGroupRepository{
+		groups: []*model.ProjectGroup{group},
+		roles:  map[uuid.UUID]map[uuid.UUID]model.Role{group.ID: {user.ID: model.RoleEditor}},
+	}
+	projects := &memoryProjectRepository{projects: []*model.Project{project, other}}
+	tokens := &memoryAccessTokenRepository{tokens: map[string]*model.AccessToken{}}
+
+	accessService := NewAccessService(groups, projects, nil, nil)
+	groupService := NewProjectGroupService(groups, users, accessService, nil)
+	projectService := NewProjectService(projects, users, groupService, accessService, nil)
+	return &tokenFixture{
+		service: NewTokenService(tokens, users, nil, projects, projectService, groupService, accessService, nil),
+		groups:  groups,
+		user:    user,
+		group:   group,
+		project: project,
+		tokens:  tokens,
+		envID:   uuid.New(),
+	}
+}
+
+func (f *tokenFixture) removeFromGroup() {
+	delete(f.groups.roles[f.group.ID], f.user.ID)
+}
+
+func (f *tokenFixture) disable() {
+	now := time.Now()
+	f.user.DisabledAt = &now
+}
+
+// add stores a token of the user and returns its secret.
+func (f *tokenFixture) add(token *model.AccessToken) string {
+	secret := AccessTokenPrefix + uuid.NewString()
+	token.ID = uuid.New()
+	token.UserID = f.user.ID
+	f.tokens.tokens[hashAccessToken(secret)] = token
+	return secret
+}
+
+func TestAuthorizeAccessTokenRechecksAccess(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		scope   func(f *tokenFixture) *model.AccessToken
+		removed bool
+		// project is the slug the token acts on, or empty for the group.
+		project string
+		wantErr error
+	}{
+		{
+			name:    "project token of a member",
+			scope:   func(f *tokenFixture) *model.AccessToken { return &model.AccessToken{ProjectID: &f.project.ID} },
+			project: "shop",
+		},
+		{
+			name:    "project token of a removed member",
+			scope:   func(f *tokenFixture) *model.AccessToken { return &model.AccessToken{ProjectID: &f.project.ID} },
+			removed: true,
+			project: "shop",
+			wantErr: ErrPermissionDenied,
+		},
+		{
+			name:    "project token on another project",
+			scope:   func(f *tokenFixture) *model.AccessToken { return &model.AccessToken{ProjectID: &f.project.ID} },
+			project: "blog",
+			wantErr: ErrPermissionDenied,
+		},
+		{
+			name:    "group token on a project of a member",
+			scope:   func(f *tokenFixture) *model.AccessToken { return &model.AccessToken{GroupID: &f.group.ID} },
+			project: "shop",
+		},
+		{
+			name:    "group token on a project of a removed member",
+			scope:   func(f *tokenFixture) *model.AccessToken { return &model.AccessToken{GroupID: &f.group.ID} },
+			removed: true,
+			project: "shop",
+			wantErr: ErrPermissionDenied,
+		},
+		{
+			name:  "group token of a member",
+			scope: func(f *tokenFixture) *model.AccessToken { return &model.AccessToken{GroupID: &f.group.ID} },
+		},
+		{
+			name:    "group token of a removed member",
+			scope:   func(f *tokenFixture) *model.AccessToken { return &model.AccessToken{GroupID: &f.group.ID} },
+			removed: true,
+			wantErr: ErrPermissionDenied,
+		},
+		{
+			name:    "project token on the group",
+			scope:   func(f *tokenFixture) *model.AccessToken { return &model.AccessToken{ProjectID: &f.project.ID} },
+			wantErr: ErrPermissionDenied,
+		},
+		{
+			name:    "unscoped token of a removed member",
+			scope:   func(f *tokenFixture) *model.AccessToken { return &model.AccessToken{} },
+			removed: true,
+			project: "shop",
+			wantErr: ErrPermissionDenied,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTokenFixture()
+			token := tt.scope(f)
+			token.UserID = f.user.ID
+			token.TokenType = model.AccessTokenTypePersonal
+			if tt.removed {
+				f.removeFromGroup()
+			}
+
+			var err error
+			if tt.project != "" {
+				err = f.service.AuthorizeProject(ctx, token, tt.project)
+			} else {
+				err = f.service.AuthorizeGroup(ctx, token, f.group.ID)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("authorize: %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAuthenticateAccessTokenOfFormerMember(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		tokenType model.AccessTokenType
+		change    func(f *tokenFixture)
+		wantErr   error
+	}{
+		{name: "sdk token", tokenType: model.AccessTokenTypeSDK},
+		{name: "sdk token of a removed member", tokenType: model.AccessTokenTypeSDK, change: (*tokenFixture).removeFromGroup, wantErr: ErrInvalidToken},
+		{name: "sdk token of a disabled user", tokenType: model.AccessTokenTypeSDK, change: (*tokenFixture).disable, wantErr: ErrInvalidToken},
+		{name: "personal token of a disabled user", tokenType: model.AccessTokenTypePersonal, change: (*tokenFixture).disable, wantErr: ErrInvalidToken},
+		// Management tokens are authorized per route, see TestAuthorizeAccessTokenRechecksAccess.
+		{name: "personal token of a removed member", tokenType: model.AccessTokenTypePersonal, change: (*tokenFixture).removeFromGroup},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTokenFixture()
+			token := &model.AccessToken{TokenType: tt.tokenType}
+			if tt.tokenType == model.AccessTokenTypeSDK {
+				token.ProjectID, token.EnvironmentID = &f.project.ID, &f.envID
+			}
+			secret := f.add(token)
+			if tt.change != nil {
+				tt.change(f)
+			}
+
+			got, err := f.service.Authenticate(ctx, secret)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("authenticate: %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && got.ID != token.ID {
+				t.Errorf("authenticated token %s, want %s", got.ID, token.ID)
+			}
+		})
+	}
+}
@@ -0,0 +1,295 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"flagon/pkg/model"
+	"flagon/pkg/repository"
+	"fmt"
+	"net/mail"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultUserLimit = 50
+	maxUserLimit     = 500
+)
+
+// UserService lets instance admins manage the users of Flagon. Admins cannot disable, demote or
+// delete themselves, so an instance always keeps the admin acting on it.
+type UserService interface {
+	// IsAdmin reports whether the user is an enabled instance admin.
+	IsAdmin(ctx context.Context, userID uuid.UUID) (bool, error)
+	List(ctx context.Context, req *ListUsersRequest) (*UserList, error)
+	Get(ctx context.Context, id uuid.UUID) (*model.User, error)
+	// Update grants or revokes admin rights, and disables or enables the user. Disabling logs the
+	// user out everywhere.
+	Update(ctx context.Context, adminID, id uuid.UUID, req *UpdateUserRequest) (*model.User, error)
+	// ForcePasswordReset makes the user choose a new password through an emailed link.
+	ForcePasswordReset(ctx context.Context, id uuid.UUID) error
+	// RevokeSessions logs the user out everywhere, for instance when they leave the company. Their
+	// access tokens are not revoked.
+	RevokeSessions(ctx context.Context, id uuid.UUID) error
+	// Delete deletes the user. The project groups and projects they own go to the new owner of the
+	// request, which is required when there are any.
+	Delete(ctx context.Context, adminID, id uuid.UUID, req *DeleteUserRequest) error
+	// CreateAdmin creates an instance admin, to bootstrap a new instance from the command line.
+	CreateAdmin(ctx context.Context, req *CreateAdminRequest) (*model.User, error)
+}
+
+type userService struct {
+	userRepo       repository.UserRepository
+	accounts       AccountService
+	sessionService SessionService
+	auditService   AuditService
+}
+
+func NewUserService(
+	userRepo repository.UserRepository,
+	accounts AccountService,
+	sessionService SessionService,
+	auditService AuditService,
+) UserService {
+	return &userService{
+		userRepo:       userRepo,
+		accounts:       accounts,
+		sessionService: sessionService,
+		auditService:   auditService,
+	}
+}
+
+type ListUsersRequest struct {
+	// Search matches part of the username, email address, first or last name.
+	Search string `form:"search"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=500"`
+	Offset int    `form:"offset" binding:"omitempty,min=0"`
+}
+
+// UserList is a page of users, with the number of users matching the search.
+type UserList struct {
+	Users []*model.User `json:"users"`
+	Total int64         `json:"total"`
+}
+
+type UpdateUserRequest struct {
+	IsAdmin  *bool `json:"is_admin"`
+	Disabled *bool `json:"disabled"`
+}
+
+type DeleteUserRequest struct {
+	// NewOwnerID is the ID of the user receiving the project groups and projects of the deleted user.
+	NewOwnerID string `form:"new_owner_id" binding:"omitempty,uuid"`
+}
+
+// CreateAdminRequest is validated by CreateAdmin, as it comes from the command line rather than a
+// request body.
+type CreateAdminRequest struct {
+	Username string
+	Email    string
+	Password string
+}
+
+func (s *userService) IsAdmin(ctx context.Context, userID uuid.UUID) (bool, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return user.IsAdmin && user.DisabledAt == nil, nil
+}
+
+func (s *userService) List(ctx context.Context, req *ListUsersRequest) (*UserList, error) {
+	filter := &repository.UserFilter{
+		Search: req.Search,
+		Limit:  defaultUserLimit,
+		Offset: req.Offset,
+	}
+	if req.Limit > 0 {
+		filter.Limit = min(req.Limit, maxUserLimit)
+	}
+	users, total, err := s.userRepo.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return &UserList{Users: users, Total: total}, nil
+}
+
+func (s *userService) Get(ctx context.Context, id uuid.UUID) (*model.User, error) {
+	user, err := s.userRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, translateError(err, "user")
+	}
+	return user, nil
+}
+
+func (s *userService) Update(ctx context.Context, adminID, id uuid.UUID, req *UpdateUserRequest) (*model.User, error) {
+	user, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if id == adminID && (req.IsAdmin != nil && !*req.IsAdmin || req.Disabled != nil && *req.Disabled) {
+		return nil, invalidArgument("admins cannot disable themselves or revoke their own admin rights")
+	}
+	before := *user
+
+	if req.IsAdmin != nil && *req.IsAdmin != user.IsAdmin {
+		if err := s.userRepo.UpdateAdmin(ctx, id, *req.IsAdmin); err != nil {
+			return nil, err
+		}
+		user.IsAdmin = *req.IsAdmin
+	}
+	if req.Disabled != nil && *req.Disabled != (user.DisabledAt != nil) {
+		var disabledAt *time.Time
+		if *req.Disabled {
+			now := time.Now()
+			disabledAt = &now
+		}
+		if err := s.userRepo.UpdateDisabledAt(ctx, id, disabledAt); err != nil {
+			return nil, err
+		}
+		user.DisabledAt = disabledAt
+		if *req.Disabled {
+			if err := s.sessionService.RevokeAll(ctx, id); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	s.auditService.Record(ctx, model.AuditActionUpdate, auditUser(id), &before, user)
+	return user, nil
+}
+
+// userPassword is the audited state of the password of a user, which never includes its hash.
+type userPassword struct {
+	Set bool `json:"set"`
+}
+
+func (s *userService) ForcePasswordReset(ctx context.Context, id uuid.UUID) error {
+	user, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	before := &userPassword{Set: user.Password != ""}
+	if err := s.accounts.ForcePasswordReset(ctx, id); err != nil {
+		return err
+	}
+	s.auditService.Record(ctx, model.AuditActionUpdate, auditUserPassword(id), before, &userPassword{})
+	return nil
+}
+
+func (s *userService) RevokeSessions(ctx context.Context, id uuid.UUID) error {
+	if _, err := s.Get(ctx, id); err != nil {
+		return err
+	}
+	sessions, err := s.sessionService.List(ctx, id, "")
+	if err != nil {
+		return err
+	}
+	if err := s.sessionService.RevokeAll(ctx, id); err != nil {
+		return err
+	}
+	s.auditService.Record(ctx, model.AuditActionDelete, auditUserSessions(id), sessions, nil)
+	return nil
+}
+
+func (s *userService) Delete(ctx context.Context, adminID, id uuid.UUID, req *DeleteUserRequest) error {
+	if id == adminID {
+		return invalidArgument("admins cannot delete themselves")
+	}
+	user, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	owned, err := s.userRepo.CountOwned(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	var heirID uuid.UUID
+	if owned > 0 {
+		if req.NewOwnerID == "" {
+			return invalidArgument("%s owns %d project groups and projects; choose a new owner for them", user.Username, owned)
+		}
+		newOwnerID, err := uuid.Parse(req.NewOwnerID)
+		if err != nil {
+			return invalidArgument("invalid new_owner_id")
+		}
+		heir, err := s.Get(ctx, newOwnerID)
+		if err != nil {
+			return fmt.Errorf("new owner: %w", err)
+		}
+		if heir.ID == id || heir.DisabledAt != nil {
+			return invalidArgument("the new owner must be another user who is not disabled")
+		}
+		heirID = heir.ID
+	}
+
+	// Sessions are kept in the cache rather than with the user, so they are revoked first.
+	if err := s.sessionService.RevokeAll(ctx, id); err != nil {
+		return err
+	}
+	if err := s.userRepo.Delete(ctx, id, heirID); err != nil {
+		return err
+	}
+	s.auditService.Record(ctx, model.AuditActionDelete, auditUser(id), user, nil)
+	return nil
+}
+
+func (s *userService) CreateAdmin(ctx context.Context, req *CreateAdminRequest) (*model.User, error) {
+	switch {
+	case req.Username == "":
+		return nil, invalidArgument("a username is required")
+	case !validEmail(req.Email):
+		return nil, invalidArgument("%q is not an email address", req.Email)
+	case len(req.Password) < 6:
+		return nil, invalidArgument("the password must be at least 6 characters")
+	}
+	if _, err := s.userRepo.FindByUsernameOrEmail(ctx, req.Username, req.Email); err == nil {
+		return nil, fmt.Errorf("user with this username or email %w", ErrAlreadyExists)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	// Whoever runs the command controls the instance, so the address needs no verification.
+	now := time.Now()
+	user := &model.User{
+		ID:              uuid.New(),
+		Username:        req.Username,
+		Password:        string(hashedPassword),
+		Email:           req.Email,
+		EmailVerifiedAt: &now,
+		IsAdmin:         true,
+	}
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return nil, translateError(err, "user")
+	}
+	return user, nil
+}
+
+// validEmail reports whether the address is a bare email address, as the email binding checks.
+func validEmail(address string) bool {
+	parsed, err := mail.ParseAddress(address)
+	return err == nil && parsed.Address == address
+}
+
+func auditUser(id uuid.UUID) AuditResource {
+	return AuditResource{Type: model.AuditResourceUser, ID: id.String()}
+}
+
+func auditUserSessions(id uuid.UUID) AuditResource {
+	return AuditResource{Type: model.AuditResourceUserSessions, ID: id.String()}
+}
+
+func auditUserPassword(id uuid.UUID) AuditResource {
+	return AuditResource{Type: model.AuditResourceUserPassword, ID: id.String()}
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"flagon/pkg/model"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// passwordResettingAccounts clears the password of the users it resets.
+type passwordResettingAccounts struct {
+	AccountService
+	users *memoryUserRepository
+}
+
+func (a *passwordResettingAccounts) ForcePasswordReset(ctx context.Context, userID uuid.UUID) error {
+	return a.users.UpdatePassword(ctx, userID, "")
+}
+
+func TestForcePasswordResetAudit(t *testing.T) {
+	ctx := context.Background()
+	user := &model.User{ID: uuid.New(), Username: "bob", Password: "hash"}
+	users := &memoryUserRepository{users: []*model.User{user}}
+	audit := &recordingAuditService{}
+	s := NewUserService(users, &passwordResettingAccounts{users: users}, nil, audit)
+
+	// The second reset finds the password cleared already.
+	for _, wasSet := range []bool{true, false} {
+		audit.records = nil
+		if err := s.ForcePasswordReset(ctx, user.ID); err != nil {
+			t.Fatal(err)
+		}
+		if len(audit.records) != 1 {
+			t.Fatalf("%d audit records, want 1", len(audit.records))
+		}
+		record := audit.records[0]
+		if record.action != model.AuditActionUpdate || record.resource.Type != model.AuditResourceUserPassword ||
+			record.resource.ID != user.ID.String() {
+			t.Errorf("recorded %s of %s %s, want an update of the password of %s",
+				record.action, record.resource.Type, record.resource.ID, user.ID)
+		}
+		before, after := record.before.(*userPassword), record.after.(*userPassword)
+		if before.Set != wasSet || after.Set {
+			t.Errorf("password set %t before and %t after the reset, want %t and false", before.Set, after.Set, wasSet)
+		}
+	}
+
+	audit.records = nil
+	if err := s.ForcePasswordReset(ctx, uuid.New()); !errors.Is(err, ErrNotFound) {
+		t.Errorf("reset of an unknown user: %v, want ErrNotFound", err)
+	}
+	if len(audit.records) != 0 {
+		t.Errorf("%d audit records of a failed reset, want 0", len(audit.records))
+	}
+}
@@ -0,0 +1,24 @@
+package service
+
+import "regexp"
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+
+const maxSlugLength = 64
+
+// featureKeyPattern restricts flag keys to identifiers that are safe to embed in code and URLs.
+var featureKeyPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_.-]{0,127}$`)
+
+func validateSlug(slug string) error {
+	if len(slug) > maxSlugLength || !slugPattern.MatchString(slug) {
+		return invalidArgument("slug %q must be lowercase letters, digits and single dashes, at most %d characters", slug, maxSlugLength)
+	}
+	return nil
+}
+
+func validateFeatureKey(key string) error {
+	if !featureKeyPattern.MatchString(key) {
+		return invalidArgument("feature key %q must start with a letter and contain only letters, digits, '.', '_' or '-', at most 128 characters", key)
+	}
+	return nil
+}
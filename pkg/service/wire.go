@@ -4,4 +4,20 @@ import "github.com/google/wire"
 
 var WireSet = wire.NewSet(
 	NewAuthService,
+	NewAccessService,
+	NewProjectGroupService,
+	NewProjectService,
+	NewEnvironmentService,
+	NewFeatureService,
+	NewTargetGroupService,
+	NewTokenService,
+	NewSdkService,
+	NewStreamService,
+	NewAuditService,
+	NewSigningKeyService,
+	NewSessionService,
+	NewMFAService,
+	NewAccountService,
+	NewUserService,
+	NewProfileService,
 )
@@ -0,0 +1,98 @@
+package totp
+
+import (
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA-1 secret of the test vectors of RFC 6238, "12345678901234567890", in base32.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCode(t *testing.T) {
+	// The test vectors of RFC 6238 appendix B, truncated to 6 digits.
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, tt := range tests {
+		got, err := Code(rfcSecret, Counter(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("code at %d: %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+
+	// Authenticator apps may show the secret in lower case.
+	if got, _ := Code("gezdgnbvgy3tqojqgezdgnbvgy3tqojq", Counter(time.Unix(59, 0))); got != "287082" {
+		t.Errorf("code of a lower case secret: %s, want 287082", got)
+	}
+	if _, err := Code("not base32!", 1); err == nil {
+		t.Error("code of an invalid secret succeeded")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	counter := Counter(now)
+	codeAt := func(offset int64) string {
+		code, err := Code(rfcSecret, counter+offset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return code
+	}
+
+	tests := []struct {
+		name        string
+		code        string
+		wantOK      bool
+		wantCounter int64
+	}{
+		{name: "current step", code: codeAt(0), wantOK: true, wantCounter: counter},
+		{name: "previous step", code: codeAt(-1), wantOK: true, wantCounter: counter - 1},
+		{name: "next step", code: codeAt(1), wantOK: true, wantCounter: counter + 1},
+		{name: "two steps ago", code: codeAt(-2)},
+		{name: "two steps ahead", code: codeAt(2)},
+		{name: "too short", code: codeAt(0)[1:]},
+		{name: "too long", code: codeAt(0) + "0"},
+		{name: "empty", code: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Validate(rfcSecret, tt.code, now)
+			if ok != tt.wantOK || got != tt.wantCounter {
+				t.Errorf("Validate: step %d, %t, want step %d, %t", got, ok, tt.wantCounter, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret == other {
+		t.Error("two secrets are equal")
+	}
+	// 20 bytes are 32 base32 characters without padding.
+	if len(secret) != 32 {
+		t.Errorf("secret of %d characters, want 32", len(secret))
+	}
+	if _, err := Code(secret, 1); err != nil {
+		t.Errorf("code of a generated secret: %v", err)
+	}
+}